@@ -1,29 +1,27 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"crypto/md5"
-	"crypto/tls"
-	"encoding/hex"
 	"fmt"
-	"io"
 	"net/http"
-	"net/url"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/arabella/ai-studio-backend/config"
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
 	"github.com/arabella/ai-studio-backend/internal/infrastructure/auth"
 	"github.com/arabella/ai-studio-backend/internal/infrastructure/cache"
 	"github.com/arabella/ai-studio-backend/internal/infrastructure/database"
+	"github.com/arabella/ai-studio-backend/internal/infrastructure/imageproxy"
+	"github.com/arabella/ai-studio-backend/internal/infrastructure/media"
 	"github.com/arabella/ai-studio-backend/internal/infrastructure/provider"
 	"github.com/arabella/ai-studio-backend/internal/infrastructure/queue"
 	infraRepo "github.com/arabella/ai-studio-backend/internal/infrastructure/repository"
+	"github.com/arabella/ai-studio-backend/internal/infrastructure/storage"
 	"github.com/arabella/ai-studio-backend/internal/infrastructure/worker"
 	"github.com/arabella/ai-studio-backend/internal/interface/http/handler"
 	"github.com/arabella/ai-studio-backend/internal/interface/http/middleware"
@@ -127,6 +125,73 @@ func main() {
 	userRepo := infraRepo.NewUserRepositoryPostgres(db.Pool())
 	templateRepo := infraRepo.NewTemplateRepositoryPostgres(db.Pool())
 	videoJobRepo := infraRepo.NewVideoJobRepositoryPostgres(db.Pool())
+	assetRepo := infraRepo.NewUserAssetRepositoryPostgres(db.Pool())
+
+	// Initialize private asset storage and signed asset URLs
+	assetStore := storage.NewLocalAssetStore(cfg.Storage.AssetDir)
+	assetURLSigner := storage.NewAssetURLSigner(cfg.Storage.AssetURLSecret, cfg.Server.BaseURL, cfg.Storage.AssetURLTTL)
+
+	// Initialize durable storage for generated media
+	var mediaStorage service.MediaStorage
+	switch cfg.Storage.Backend {
+	case "s3":
+		s3Storage, err := storage.NewS3MediaStorage(storage.S3Config{
+			Endpoint:      cfg.Storage.S3Endpoint,
+			Region:        cfg.Storage.S3Region,
+			Bucket:        cfg.Storage.S3Bucket,
+			AccessKey:     cfg.Storage.AWSAccessKey,
+			SecretKey:     cfg.Storage.AWSSecretKey,
+			PathStyle:     cfg.Storage.S3PathStyle,
+			PublicBaseURL: cfg.Storage.CDNBaseURL,
+		})
+		if err != nil {
+			logger.Fatal("Failed to initialize S3 media storage", zap.Error(err))
+		}
+		mediaStorage = s3Storage
+	default:
+		mediaStorage = storage.NewLocalMediaStorage(cfg.Storage.MediaDir, strings.TrimSuffix(cfg.Server.BaseURL, "/")+"/media")
+	}
+	logger.Info("Media storage initialized", zap.String("backend", cfg.Storage.Backend))
+
+	signingKeys := make([]storage.SigningKey, 0, len(cfg.Storage.MediaURLKeys))
+	for _, key := range cfg.Storage.MediaURLKeys {
+		signingKeys = append(signingKeys, storage.SigningKey{ID: key.ID, Secret: key.Secret})
+	}
+	mediaURLSigner, err := storage.NewMediaURLSigner(signingKeys, cfg.Server.BaseURL, cfg.Storage.MediaURLTTL)
+	if err != nil {
+		logger.Fatal("Failed to initialize media URL signer", zap.Error(err))
+	}
+
+	// Posters and previews need ffmpeg; without it completed videos simply have none
+	ffmpeg := media.NewFFmpeg(cfg.Media.FFmpegPath, cfg.Media.FFprobePath)
+	if !ffmpeg.Available() {
+		logger.Warn("ffmpeg not found, video posters and previews are disabled", zap.String("path", cfg.Media.FFmpegPath))
+	}
+	if !ffmpeg.ProbeAvailable() {
+		logger.Warn("ffprobe not found, video metadata will not be measured", zap.String("path", cfg.Media.FFprobePath))
+	}
+	if cfg.Media.WatermarkImage != "" {
+		if err := ffmpeg.SetWatermark(media.WatermarkConfig{
+			ImagePath: cfg.Media.WatermarkImage,
+			Position:  cfg.Media.WatermarkPosition,
+			Margin:    cfg.Media.WatermarkMargin,
+		}); err != nil {
+			logger.Fatal("Invalid watermark configuration", zap.Error(err))
+		}
+	}
+
+	// Initialize the external image proxy, shared by the proxy endpoint and Wan AI
+	imageProxy, err := imageproxy.New(imageproxy.Config{
+		AllowedHosts:  cfg.Proxy.AllowedHosts,
+		HTTPOnlyHosts: cfg.Proxy.HTTPOnlyHosts,
+		MaxBytes:      cfg.Proxy.MaxBytes,
+		CacheDir:      cfg.Proxy.CacheDir,
+		CacheMaxBytes: cfg.Proxy.CacheMaxBytes,
+		CacheTTL:      cfg.Proxy.CacheTTL,
+	}, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize image proxy", zap.Error(err))
+	}
 
 	// Initialize rate limiter
 	rateLimiter := cache.NewRateLimiter(redisCache.Client())
@@ -138,25 +203,89 @@ func main() {
 	providerRegistry := provider.NewProviderRegistry(logger)
 
 	if cfg.AI.UseMockProvider {
-		mockProvider := provider.NewMockProvider(logger, false)
+		mockProvider := provider.NewMockProvider(logger, cfg.AI.MockSimulateTime, cfg.AI.MockCompletionTime, cfg.AI.MockVideoURL)
 		providerRegistry.Register(mockProvider)
 	}
 
-	if cfg.AI.GeminiAPIKey != "" {
-		geminiProvider := provider.NewGeminiProvider(cfg.AI.GeminiAPIKey, logger)
+	keyStrategy := provider.KeySelectionStrategy(cfg.AI.KeyStrategy)
+
+	if len(cfg.AI.GeminiAPIKeys) > 0 {
+		geminiKeys := provider.NewKeyPool(entity.ProviderGeminiVEO, cfg.AI.GeminiAPIKeys, keyStrategy, cfg.AI.KeyCooldown)
+		geminiProvider := provider.NewGeminiProvider(geminiKeys, logger)
 		providerRegistry.Register(geminiProvider)
 	}
 
-	if cfg.AI.WanAIAPIKey != "" {
-		wanaiProvider := provider.NewWanAIProvider(cfg.AI.WanAIAPIKey, cfg.AI.WanAIVersion, cfg.AI.WanAIBaseURL, cfg.Server.BaseURL, logger)
+	if len(cfg.AI.WanAIAPIKeys) > 0 {
+		wanaiKeys := provider.NewKeyPool(entity.ProviderWanAI, cfg.AI.WanAIAPIKeys, keyStrategy, cfg.AI.KeyCooldown)
+		wanaiProvider := provider.NewWanAIProvider(wanaiKeys, cfg.AI.WanAIVersion, cfg.AI.WanAIBaseURL, cfg.Server.BaseURL, imageProxy, logger)
 		providerRegistry.Register(wanaiProvider)
 		logger.Info("Wan AI provider registered",
 			zap.String("version", cfg.AI.WanAIVersion),
 			zap.String("base_url", cfg.AI.WanAIBaseURL),
+			zap.Int("api_keys", wanaiKeys.Size()),
 		)
 	}
 
-	providerSelector := provider.NewProviderSelector(providerRegistry, logger)
+	// Record or replay provider HTTP traffic (PROVIDER_CASSETTE_MODE=record|replay)
+	if err := provider.AttachCassettes(providerRegistry, provider.CassetteMode(cfg.AI.CassetteMode), cfg.AI.CassetteDir, logger); err != nil {
+		logger.Fatal("Failed to attach provider cassettes", zap.Error(err))
+	}
+
+	// Throttle outbound provider calls and track daily quotas across instances
+	providerLimits := make(map[entity.AIProvider]map[service.ProviderOperation]cache.RateLimit)
+	for name, ops := range cfg.AI.ProviderRateLimits {
+		providerLimits[entity.AIProvider(name)] = make(map[service.ProviderOperation]cache.RateLimit)
+		for op, limit := range ops {
+			providerLimits[entity.AIProvider(name)][service.ProviderOperation(op)] = cache.RateLimit{
+				Rate:  limit.Rate,
+				Burst: limit.Burst,
+			}
+		}
+	}
+	providerQuotas := make(map[entity.AIProvider]int)
+	for name, quota := range cfg.AI.ProviderDailyQuotas {
+		providerQuotas[entity.AIProvider(name)] = quota
+	}
+	providerRateLimiter := cache.NewProviderRateLimiter(redisCache.Client(), providerLimits, providerQuotas)
+	provider.ApplyRateLimits(providerRegistry, providerRateLimiter, logger)
+
+	// Initialize cost-aware routing policy and spend tracking
+	routingPolicy := provider.DefaultRoutingPolicy()
+	for tier, weights := range cfg.AI.RoutingWeights {
+		routingPolicy.TierWeights[entity.UserTier(tier)] = provider.RoutingWeights{
+			Cost:    weights.Cost,
+			Quality: weights.Quality,
+			Latency: weights.Latency,
+		}
+	}
+	for name, budget := range cfg.AI.ProviderBudgets {
+		routingPolicy.Budgets[entity.AIProvider(name)] = provider.ProviderBudget{
+			DailyLimit:   budget.Daily,
+			MonthlyLimit: budget.Monthly,
+		}
+	}
+	for _, exp := range cfg.AI.Experiments {
+		experiment := provider.Experiment{
+			Name:       exp.Name,
+			Tier:       entity.UserTier(exp.Tier),
+			TemplateID: exp.TemplateID,
+		}
+		for _, arm := range exp.Arms {
+			experiment.Arms = append(experiment.Arms, provider.ExperimentArm{
+				Name:     arm.Name,
+				Provider: entity.AIProvider(arm.Provider),
+				Model:    arm.Model,
+				Weight:   arm.Weight,
+			})
+		}
+		routingPolicy.Experiments = append(routingPolicy.Experiments, experiment)
+	}
+	if err := provider.ValidateExperiments(routingPolicy.Experiments, providerRegistry); err != nil {
+		logger.Fatal("Invalid provider experiment", zap.Error(err))
+	}
+	spendTracker := cache.NewSpendTracker(redisCache.Client())
+
+	providerSelector := provider.NewProviderSelector(providerRegistry, routingPolicy, spendTracker, providerRateLimiter, cfg.AI.HealthHistorySize, logger)
 
 	// Initialize WebSocket hub
 	wsHub := websocket.NewHub(logger)
@@ -178,24 +307,45 @@ func main() {
 	googleVerifier := auth.NewGoogleAuthVerifier(googleConfig, logger)
 
 	// Initialize use cases
+	imageProcessor := media.NewImageProcessor()
+	hlsPublisher := media.NewHLSPublisher(ffmpeg, mediaStorage)
 	authUseCase := usecase.NewAuthUseCase(userRepo, tokenGenerator, googleVerifier)
-	templateUseCase := usecase.NewTemplateUseCase(templateRepo, redisCache)
+	templateUseCase := usecase.NewTemplateUseCase(templateRepo, redisCache, providerSelector)
 	userUseCase := usecase.NewUserUseCase(userRepo, videoJobRepo)
+	providerUseCase := usecase.NewProviderUseCase(providerSelector, videoJobRepo)
 	videoUseCase := usecase.NewVideoUseCase(
 		videoJobRepo,
 		templateRepo,
 		userRepo,
+		assetRepo,
 		providerSelector,
 		jobQueue,
 		wsHub,
+		mediaStorage,
+		mediaURLSigner,
+		hlsPublisher,
 	)
+	mediaUseCase := usecase.NewMediaUseCase(mediaStorage, mediaURLSigner)
+	uploadUseCase := usecase.NewUploadUseCase(mediaStorage, imageProcessor)
+	assetUseCase := usecase.NewAssetUseCase(assetRepo, assetStore, assetURLSigner, imageProcessor, media.NewAudioInspector())
+	mediaGCUseCase := usecase.NewMediaGCUseCase(videoJobRepo, templateRepo, assetRepo, usecase.MediaGCStores{
+		Media:         mediaStorage,
+		Assets:        assetStore,
+		LegacyUploads: storage.NewLocalMediaStorage("./static/uploads", ""),
+		ImageCache:    imageProxy,
+	}, cfg.MediaGC.GracePeriod)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authUseCase)
 	templateHandler := handler.NewTemplateHandler(templateUseCase)
 	userHandler := handler.NewUserHandler(userUseCase)
-	videoHandler := handler.NewVideoHandler(videoUseCase)
-	uploadHandler := handler.NewUploadHandler()
+	videoHandler := handler.NewVideoHandler(videoUseCase, cfg.AI.UseMockProvider)
+	uploadHandler := handler.NewUploadHandler(uploadUseCase)
+	providerHandler := handler.NewProviderHandler(providerUseCase)
+	assetHandler := handler.NewAssetHandler(assetUseCase)
+	mediaHandler := handler.NewMediaHandler(mediaUseCase)
+	proxyHandler := handler.NewProxyHandler(imageProxy)
+	mediaGCHandler := handler.NewMediaGCHandler(mediaGCUseCase)
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(authUseCase)
@@ -210,17 +360,46 @@ func main() {
 		videoJobRepo,
 		templateRepo,
 		userRepo,
+		assetRepo,
+		assetURLSigner,
+		mediaStorage,
+		mediaURLSigner,
+		ffmpeg,
+		worker.MediaConfig{
+			HLS:       cfg.Media.HLSEnabled,
+			Watermark: cfg.Media.WatermarkImage != "",
+		},
+		hlsPublisher,
 		providerSelector,
 		jobQueue,
 		wsHub,
+		worker.PollConfig{
+			Interval:             cfg.Worker.PollInterval,
+			Timeout:              cfg.Worker.PollTimeout,
+			MaxConsecutiveErrors: cfg.Worker.MaxPollErrors,
+		},
 		logger,
 	)
 	videoWorker.Start(ctx)
 	logger.Info("Video worker started")
 
+	// Initialize watermark remover to run the removals users queue
+	watermarkRemover := worker.NewWatermarkRemover(videoUseCase, cfg.Worker.PollInterval, logger)
+	watermarkRemover.Start(ctx)
+
+	// Initialize provider health monitor to keep the selector's health cache fresh
+	healthMonitor := worker.NewProviderHealthMonitor(providerSelector, cfg.AI.HealthCheckInterval, logger)
+	healthMonitor.Start(ctx)
+
+	// Initialize media garbage collector to delete files nothing references any more
+	if cfg.MediaGC.Enabled {
+		mediaGC := worker.NewMediaGarbageCollector(mediaGCUseCase, cfg.MediaGC.Interval, cfg.MediaGC.DryRun, logger)
+		mediaGC.Start(ctx)
+	}
+
 	// Setup router
 	router := setupRouter(cfg, logger, authHandler, templateHandler, userHandler, videoHandler, uploadHandler,
-		authMiddleware, rateLimitMiddleware, loggingMiddleware, wsHandler)
+		providerHandler, assetHandler, mediaHandler, proxyHandler, mediaGCHandler, authMiddleware, rateLimitMiddleware, loggingMiddleware, wsHandler)
 
 	// Create HTTP server
 	server := &http.Server{
@@ -288,6 +467,11 @@ func setupRouter(
 	userHandler *handler.UserHandler,
 	videoHandler *handler.VideoHandler,
 	uploadHandler *handler.UploadHandler,
+	providerHandler *handler.ProviderHandler,
+	assetHandler *handler.AssetHandler,
+	mediaHandler *handler.MediaHandler,
+	proxyHandler *handler.ProxyHandler,
+	mediaGCHandler *handler.MediaGCHandler,
 	authMiddleware *middleware.AuthMiddleware,
 	rateLimitMiddleware *middleware.RateLimitMiddleware,
 	loggingMiddleware *middleware.LoggingMiddleware,
@@ -333,302 +517,21 @@ func setupRouter(
 	// Static file serving for thumbnails
 	router.Static("/thumbnails", "./static/thumbnails")
 
-	// Static file serving for cached temp images (for DashScope)
-	router.Static("/temp-images", "./static/temp-images")
+	// Static file serving for the image proxy cache (for DashScope)
+	router.Static("/temp-images", cfg.Proxy.CacheDir)
 
-	// Static file serving for uploaded images (at root level for direct backend access)
+	// Static file serving for images uploaded before content-addressed storage (at root level for direct backend access)
 	router.Static("/uploads", "./static/uploads")
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
-		// Static file serving for uploaded images under /api/v1/uploads (for Nginx proxy)
-		// Use handler function since StaticFS doesn't work properly in groups
-		v1.GET("/uploads/*filepath", func(c *gin.Context) {
-			pathParam := c.Param("filepath")
-			// Remove leading slash if present
-			if strings.HasPrefix(pathParam, "/") {
-				pathParam = pathParam[1:]
-			}
-			filePath := filepath.Join("./static/uploads", pathParam)
-			c.File(filePath)
-		})
-		v1.HEAD("/uploads/*filepath", func(c *gin.Context) {
-			pathParam := c.Param("filepath")
-			if strings.HasPrefix(pathParam, "/") {
-				pathParam = pathParam[1:]
-			}
-			filePath := filepath.Join("./static/uploads", pathParam)
-			c.File(filePath)
-		})
-
-		// Image proxy endpoint (for external images that DashScope can't access)
-		// Also used by frontend for nanobanana.uz images
-		// Caches images locally to avoid repeated downloads
-		v1.GET("/proxy/image", func(c *gin.Context) {
-			imageURL := c.Query("url")
-			if imageURL == "" {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "url parameter is required"})
-				return
-			}
-
-			// Validate URL
-			parsedURL, err := url.Parse(imageURL)
-			if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid URL"})
-				return
-			}
-
-			// For nanobanana.uz images, check cache first
-			if parsedURL.Host == "nanobanana.uz" {
-				cacheDir := "./static/temp-images"
-				os.MkdirAll(cacheDir, 0755)
-
-				// Generate cache filename from URL hash
-				hash := md5.Sum([]byte(imageURL))
-				filename := hex.EncodeToString(hash[:]) + ".png"
-				cachePath := filepath.Join(cacheDir, filename)
-
-				// Check if cached and file is reasonable size (>100KB suggests complete image)
-				if fileInfo, err := os.Stat(cachePath); err == nil && fileInfo.Size() > 100*1024 {
-					// Serve from cache
-					c.File(cachePath)
-					return
-				}
-			}
-
-			// For nanobanana.uz, always use HTTP (HTTPS has SSL issues)
-			originalScheme := parsedURL.Scheme
-			if parsedURL.Host == "nanobanana.uz" && parsedURL.Scheme == "https" {
-				parsedURL.Scheme = "http"
-				imageURL = parsedURL.String()
-			}
-
-			// Fetch the image with retry logic
-			// Create HTTP client that ignores SSL certificate errors
-			// This is needed for nanobanana.uz which has SSL issues
-			tr := &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: true, // Accept any certificate
-				},
-				DisableKeepAlives:     false,
-				MaxIdleConns:          100,
-				IdleConnTimeout:       90 * time.Second,
-				ResponseHeaderTimeout: 30 * time.Second,
-				ExpectContinueTimeout: 1 * time.Second,
-			}
-			client := &http.Client{
-				Timeout:   180 * time.Second, // Longer timeout for large images
-				Transport: tr,
-			}
-
-			var resp *http.Response
-			var fetchErr error
-			maxFetchRetries := 3
-
-			for i := 0; i < maxFetchRetries; i++ {
-				req, err := http.NewRequest("GET", imageURL, nil)
-				if err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{"error": "failed to create request", "details": err.Error()})
-					return
-				}
-
-				// Set headers to mimic a browser request
-				req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
-				req.Header.Set("Accept", "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8")
-				req.Header.Set("Accept-Language", "en-US,en;q=0.9")
-				req.Header.Set("Referer", "https://arabella.uz/")
-				req.Header.Set("Cache-Control", "no-cache")
-				req.Header.Set("Connection", "keep-alive")
-				req.Header.Set("Accept-Encoding", "identity") // Disable compression to avoid issues
-
-				// Try both HTTP and HTTPS if original is HTTP
-				if parsedURL.Scheme == "http" {
-					// Some servers redirect HTTP to HTTPS
-					req.Header.Set("Upgrade-Insecure-Requests", "1")
-				}
-
-				resp, fetchErr = client.Do(req)
-
-				if fetchErr != nil {
-					// If HTTPS fails and we haven't tried HTTP yet, fallback to HTTP
-					if originalScheme == "https" && parsedURL.Scheme == "https" && i == 0 {
-						// Try HTTP as fallback
-						parsedURL.Scheme = "http"
-						imageURL = parsedURL.String()
-						continue
-					}
-					// Continue to retry or fail after max retries
-					if i < maxFetchRetries-1 {
-						time.Sleep(time.Duration(i+1) * 2 * time.Second) // Exponential backoff
-					}
-					continue
-				}
-
-				if resp != nil {
-					// Follow redirects
-					if resp.StatusCode >= 300 && resp.StatusCode < 400 {
-						location := resp.Header.Get("Location")
-						if location != "" {
-							resp.Body.Close()
-							imageURL = location
-							parsedURL, _ = url.Parse(imageURL)
-							// For nanobanana.uz redirects, ensure HTTP
-							if parsedURL.Host == "nanobanana.uz" && parsedURL.Scheme == "https" {
-								parsedURL.Scheme = "http"
-								imageURL = parsedURL.String()
-							}
-							continue // Retry with new URL
-						}
-					}
-					if resp.StatusCode == http.StatusOK {
-						break // Success!
-					}
-					// Non-200 status, close and retry
-					resp.Body.Close()
-					if i < maxFetchRetries-1 {
-						time.Sleep(time.Duration(i+1) * 2 * time.Second)
-					}
-				}
-			}
-
-			if fetchErr != nil || resp == nil {
-				// Log error but don't expose internal details to client
-				c.JSON(http.StatusBadGateway, gin.H{
-					"error":   "failed to fetch image",
-					"details": "image source unavailable",
-					"url":     imageURL,
-				})
-				return
-			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode != http.StatusOK {
-				resp.Body.Close()
-				c.JSON(http.StatusBadGateway, gin.H{
-					"error":  "failed to fetch image",
-					"status": resp.StatusCode,
-					"url":    imageURL,
-				})
-				return
-			}
+		// Uploaded images under /api/v1/uploads (for Nginx proxy)
+		v1.GET("/uploads/*filepath", uploadHandler.ServeUpload)
+		v1.HEAD("/uploads/*filepath", uploadHandler.ServeUpload)
 
-			// Read the entire image into memory to ensure we get the complete file
-			// nanobanana.uz closes connections prematurely, so we need to read it all first
-			// Set appropriate headers
-			contentType := resp.Header.Get("Content-Type")
-			if contentType == "" {
-				contentType = "image/png" // Default content type
-			}
-
-			// Read the complete image with retry logic
-			// nanobanana.uz closes connections prematurely, so we need to retry until we get the full image
-			expectedSize := resp.ContentLength
-			var imageData []byte
-			maxRetries := 5
-
-			for retry := 0; retry < maxRetries; retry++ {
-				// Close previous response if retrying
-				if retry > 0 {
-					resp.Body.Close()
-					// Re-fetch the image
-					req, _ := http.NewRequest("GET", imageURL, nil)
-					req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-					req.Header.Set("Accept", "image/*")
-					req.Header.Set("Connection", "keep-alive")
-					req.Header.Set("Accept-Encoding", "identity")
-
-					newResp, err := client.Do(req)
-					if err != nil || newResp == nil || newResp.StatusCode != http.StatusOK {
-						if newResp != nil {
-							newResp.Body.Close()
-						}
-						if retry < maxRetries-1 {
-							time.Sleep(time.Duration(retry+1) * time.Second)
-							continue
-						}
-						break
-					}
-					resp = newResp
-				}
-
-				// Read with timeout
-				ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
-				defer cancel()
-				var buf bytes.Buffer
-				maxSize := int64(50 * 1024 * 1024) // 50MB max
-				limitedReader := io.LimitReader(resp.Body, maxSize)
-
-				done := make(chan error, 1)
-				go func() {
-					_, err := io.Copy(&buf, limitedReader)
-					done <- err
-				}()
-
-				var readErr error
-				select {
-				case <-ctx.Done():
-					readErr = ctx.Err()
-				case readErr = <-done:
-				}
-				cancel()
-
-				imageData = buf.Bytes()
-
-				// Check if we got the complete image
-				if expectedSize > 0 {
-					if int64(len(imageData)) >= expectedSize {
-						// Got the full image!
-						break
-					}
-				} else {
-					// No Content-Length, check if read completed without error
-					if readErr == nil {
-						// Assume we got it all if no error
-						break
-					}
-				}
-
-				// If we didn't get the full image and there are retries left, try again
-				if retry < maxRetries-1 {
-					time.Sleep(time.Duration(retry+1) * 2 * time.Second)
-				}
-			}
-
-			if len(imageData) == 0 {
-				c.JSON(http.StatusBadGateway, gin.H{
-					"error":   "failed to read image data",
-					"details": "could not fetch complete image after retries",
-				})
-				return
-			}
-
-			// For nanobanana.uz images, cache the complete image locally
-			if parsedURL != nil && parsedURL.Host == "nanobanana.uz" && len(imageData) > 0 {
-				cacheDir := "./static/temp-images"
-				os.MkdirAll(cacheDir, 0755)
-				hash := md5.Sum([]byte(imageURL))
-				filename := hex.EncodeToString(hash[:]) + ".png"
-				cachePath := filepath.Join(cacheDir, filename)
-
-				// Only cache if we got the complete image (or at least a reasonable amount)
-				if expectedSize == 0 || int64(len(imageData)) >= expectedSize || len(imageData) > 100*1024 {
-					if err := os.WriteFile(cachePath, imageData, 0644); err == nil {
-						// Successfully cached, serve from cache next time
-					}
-				}
-			}
-
-			// Send the image with Content-Length matching what we actually have
-			// This prevents ERR_CONTENT_LENGTH_MISMATCH
-			c.Header("Content-Type", contentType)
-			c.Header("Content-Length", fmt.Sprintf("%d", len(imageData)))
-			c.Header("Cache-Control", "public, max-age=3600")
-			c.Header("Access-Control-Allow-Origin", "*")
-
-			// Send the image data
-			c.Data(http.StatusOK, contentType, imageData)
-		})
+		// Image proxy endpoint (for external images that DashScope or the frontend can't load directly)
+		v1.GET("/proxy/image", proxyHandler.ProxyImage)
 
 		// Rate limiting for all API routes
 		v1.Use(rateLimitMiddleware.Limit(100, time.Minute))
@@ -637,7 +540,9 @@ func setupRouter(
 		authRoutes := v1.Group("/auth")
 		{
 			authRoutes.POST("/google", authHandler.GoogleAuth)
-			authRoutes.POST("/test", authHandler.TestLogin) // Test login (skip Google)
+			if cfg.IsDevelopment() {
+				authRoutes.POST("/test", authHandler.TestLogin) // Test login (skip Google, development only)
+			}
 			authRoutes.POST("/refresh", authHandler.RefreshToken)
 			authRoutes.POST("/logout", authMiddleware.RequireAuth(), authHandler.Logout)
 		}
@@ -652,9 +557,9 @@ func setupRouter(
 			templateRoutes.GET("/:id", templateHandler.GetTemplate)
 		}
 
-		// Admin routes (authenticated, admin only - TODO: add admin role check)
+		// Admin routes (authenticated, users flagged is_admin only)
 		adminRoutes := v1.Group("/admin")
-		adminRoutes.Use(authMiddleware.RequireAuth())
+		adminRoutes.Use(authMiddleware.RequireAuth(), authMiddleware.RequireAdmin())
 		{
 			// Admin template management
 			adminTemplateRoutes := adminRoutes.Group("/templates")
@@ -666,6 +571,14 @@ func setupRouter(
 
 			// Admin upload endpoints
 			adminRoutes.POST("/upload/image", uploadHandler.UploadImage)
+
+			// Admin provider monitoring
+			adminRoutes.GET("/providers/health", providerHandler.GetProviderHealth)
+			adminRoutes.GET("/providers/spend", providerHandler.GetProviderSpend)
+			adminRoutes.GET("/experiments/report", providerHandler.GetExperimentReport)
+
+			// Admin media garbage collection (reports without deleting unless ?dry_run=false)
+			adminRoutes.POST("/media/gc", mediaGCHandler.CollectGarbage)
 		}
 
 		// Video routes (authenticated)
@@ -678,6 +591,27 @@ func setupRouter(
 			videoRoutes.GET("/:id", videoHandler.GetVideo)
 			videoRoutes.GET("/:id/status", videoHandler.GetJobStatus)
 			videoRoutes.POST("/:id/cancel", videoHandler.CancelJob)
+			videoRoutes.POST("/:id/rating", videoHandler.RateVideo)
+			videoRoutes.POST("/:id/regenerate", rateLimitMiddleware.LimitGeneration(), videoHandler.RegenerateVideo)
+			videoRoutes.POST("/:id/unwatermark", videoHandler.RemoveWatermark)
+			videoRoutes.POST("/:id/share", videoHandler.ShareVideo)
+			videoRoutes.DELETE("/:id/share", videoHandler.UnshareVideo)
+		}
+
+		// Video streams are open to the owner or anyone holding the video's share link
+		v1.GET("/videos/:id/stream", authMiddleware.OptionalAuth(), videoHandler.StreamVideo)
+
+		// Generated media, served only through signed, expiring URLs
+		v1.GET("/media/:token/*key", mediaHandler.ServeMedia)
+
+		// Asset routes: uploads and metadata are authenticated, content is served through signed URLs
+		v1.GET("/assets/:id/content", assetHandler.GetAssetContent)
+		assetRoutes := v1.Group("/assets")
+		assetRoutes.Use(authMiddleware.RequireAuth())
+		{
+			assetRoutes.POST("/images", assetHandler.UploadStartImage)
+			assetRoutes.POST("/audio", assetHandler.UploadAudio)
+			assetRoutes.GET("/:id", assetHandler.GetAsset)
 		}
 
 		// User routes (authenticated)
@@ -0,0 +1,354 @@
+// Command fake-dashscope is a local stand-in for the subset of the DashScope
+// video-synthesis API used by WanAIProvider: task submission, task status and models.
+//
+// Point the API at it with:
+//
+//	WANAI_API_KEY=dev WANAI_BASE_URL=http://localhost:8090/api/v1 go run ./cmd/api
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// stage is one step of the task status curve
+type stage struct {
+	status   string
+	duration time.Duration
+}
+
+// options configures the fake server behaviour
+type options struct {
+	addr           string
+	publicURL      string
+	latency        time.Duration
+	curve          []stage
+	failRate       float64
+	failCode       string
+	failMessage    string
+	submitFailRate float64
+	submitFailCode string
+	resultFile     string
+}
+
+// task is a submitted video-synthesis task
+type task struct {
+	id          string
+	model       string
+	submittedAt time.Time
+	duration    int
+	size        string
+	willFail    bool
+}
+
+// server holds submitted tasks
+type server struct {
+	opts  options
+	mu    sync.Mutex
+	tasks map[string]*task
+}
+
+// synthesisRequest is the subset of the DashScope request the fake inspects
+type synthesisRequest struct {
+	Model string `json:"model"`
+	Input struct {
+		Prompt string `json:"prompt"`
+		ImgURL string `json:"img_url"`
+	} `json:"input"`
+	Parameters struct {
+		Resolution string `json:"resolution"`
+		Size       string `json:"size"`
+		Duration   int    `json:"duration"`
+	} `json:"parameters"`
+}
+
+func main() {
+	opts := options{}
+	var curve string
+	flag.StringVar(&opts.addr, "addr", getEnv("FAKE_DASHSCOPE_ADDR", ":8090"), "listen address")
+	flag.StringVar(&opts.publicURL, "public-url", getEnv("FAKE_DASHSCOPE_PUBLIC_URL", "http://localhost:8090"), "base URL used in returned video URLs")
+	flag.DurationVar(&opts.latency, "latency", getEnvDuration("FAKE_DASHSCOPE_LATENCY", 200*time.Millisecond), "added latency per request")
+	flag.StringVar(&curve, "curve", getEnv("FAKE_DASHSCOPE_CURVE", "PENDING:5s,RUNNING:25s"), "task status curve as STATUS:duration pairs, followed by SUCCEEDED or FAILED")
+	flag.Float64Var(&opts.failRate, "fail-rate", getEnvFloat("FAKE_DASHSCOPE_FAIL_RATE", 0), "fraction of tasks that end in FAILED")
+	flag.StringVar(&opts.failCode, "fail-code", getEnv("FAKE_DASHSCOPE_FAIL_CODE", "InternalError"), "error code reported by failed tasks")
+	flag.StringVar(&opts.failMessage, "fail-message", getEnv("FAKE_DASHSCOPE_FAIL_MESSAGE", "Simulated generation failure"), "error message reported by failed tasks")
+	flag.Float64Var(&opts.submitFailRate, "submit-fail-rate", getEnvFloat("FAKE_DASHSCOPE_SUBMIT_FAIL_RATE", 0), "fraction of submissions rejected with submit-fail-code")
+	flag.StringVar(&opts.submitFailCode, "submit-fail-code", getEnv("FAKE_DASHSCOPE_SUBMIT_FAIL_CODE", "Throttling.RateQuota"), "error code for rejected submissions (e.g. DataInspectionFailed, InvalidApiKey)")
+	flag.StringVar(&opts.resultFile, "result-file", getEnv("FAKE_DASHSCOPE_RESULT_FILE", ""), "local video file served as the result of every task")
+	flag.Parse()
+
+	stages, err := parseCurve(curve)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -curve: %v\n", err)
+		os.Exit(2)
+	}
+	opts.curve = stages
+
+	if opts.resultFile == "" {
+		fmt.Println("⚠️  No -result-file set; result video URLs will return 404")
+	} else if _, err := os.Stat(opts.resultFile); err != nil {
+		fmt.Fprintf(os.Stderr, "result file: %v\n", err)
+		os.Exit(2)
+	}
+
+	s := &server{opts: opts, tasks: make(map[string]*task)}
+
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(gin.Logger(), gin.Recovery(), s.withLatency)
+
+	api := router.Group("/api/v1", s.requireAuth)
+	{
+		api.POST("/services/aigc/video-generation/video-synthesis", s.submitTask)
+		api.GET("/tasks/:id", s.getTask)
+		api.GET("/models", s.listModels)
+	}
+	router.GET("/files/result.mp4", s.serveResult)
+
+	fmt.Printf("🧪 Fake DashScope listening on %s (WANAI_BASE_URL=%s/api/v1)\n", opts.addr, opts.publicURL)
+	if err := router.Run(opts.addr); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start server: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// withLatency delays every request by the configured latency
+func (s *server) withLatency(c *gin.Context) {
+	if s.opts.latency > 0 {
+		time.Sleep(s.opts.latency)
+	}
+	c.Next()
+}
+
+// requireAuth rejects requests without a bearer token, like DashScope does
+func (s *server) requireAuth(c *gin.Context) {
+	if !strings.HasPrefix(c.GetHeader("Authorization"), "Bearer ") {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, errorBody("InvalidApiKey", "No API-key provided."))
+		return
+	}
+	c.Next()
+}
+
+// submitTask handles asynchronous video-synthesis submission
+func (s *server) submitTask(c *gin.Context) {
+	if c.GetHeader("X-DashScope-Async") != "enable" {
+		c.JSON(http.StatusForbidden, errorBody("AccessDenied", "current user api does not support synchronous calls"))
+		return
+	}
+
+	var req synthesisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorBody("InvalidParameter", err.Error()))
+		return
+	}
+
+	if s.opts.submitFailRate > 0 && rand.Float64() < s.opts.submitFailRate {
+		c.JSON(submitFailStatus(s.opts.submitFailCode), errorBody(s.opts.submitFailCode, "Simulated submission failure"))
+		return
+	}
+
+	t := &task{
+		id:          uuid.New().String(),
+		model:       req.Model,
+		submittedAt: time.Now(),
+		duration:    req.Parameters.Duration,
+		size:        videoSize(req.Parameters.Resolution, req.Parameters.Size),
+		willFail:    s.opts.failRate > 0 && rand.Float64() < s.opts.failRate,
+	}
+	if t.duration == 0 {
+		t.duration = 5
+	}
+
+	s.mu.Lock()
+	s.tasks[t.id] = t
+	s.mu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"request_id": uuid.New().String(),
+		"output": gin.H{
+			"task_id":     t.id,
+			"task_status": "PENDING",
+		},
+	})
+}
+
+// getTask reports task status following the configured curve
+func (s *server) getTask(c *gin.Context) {
+	s.mu.Lock()
+	t, ok := s.tasks[c.Param("id")]
+	s.mu.Unlock()
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{
+			"request_id": uuid.New().String(),
+			"output":     gin.H{"task_id": c.Param("id"), "task_status": "UNKNOWN"},
+		})
+		return
+	}
+
+	status := s.statusAt(time.Since(t.submittedAt))
+	output := gin.H{
+		"task_id":     t.id,
+		"task_status": status,
+		"submit_time": t.submittedAt.Format("2006-01-02 15:04:05.000"),
+	}
+	response := gin.H{"request_id": uuid.New().String(), "output": output}
+
+	if status == "SUCCEEDED" && t.willFail {
+		status = "FAILED"
+		output["task_status"] = status
+	}
+
+	switch status {
+	case "SUCCEEDED":
+		expires := time.Now().Add(24 * time.Hour).Unix()
+		output["video_url"] = fmt.Sprintf("%s/files/result.mp4?Expires=%d&OSSAccessKeyId=fake&Signature=fake", s.opts.publicURL, expires)
+		output["end_time"] = time.Now().Format("2006-01-02 15:04:05.000")
+		response["usage"] = gin.H{
+			"video_duration": t.duration,
+			"video_ratio":    t.size,
+			"video_count":    1,
+		}
+	case "FAILED":
+		output["code"] = s.opts.failCode
+		output["message"] = s.opts.failMessage
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// listModels returns the models the provider may request
+func (s *server) listModels(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"request_id": uuid.New().String(),
+		"output": gin.H{
+			"models": []gin.H{
+				{"model": "wan2.6-i2v"},
+				{"model": "wan2.5-i2v-preview"},
+				{"model": "wan2.5-t2v-preview"},
+			},
+		},
+	})
+}
+
+// serveResult serves the configured result video
+func (s *server) serveResult(c *gin.Context) {
+	if s.opts.resultFile == "" {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.File(s.opts.resultFile)
+}
+
+// statusAt returns the task status after elapsed time on the curve
+func (s *server) statusAt(elapsed time.Duration) string {
+	var total time.Duration
+	for _, st := range s.opts.curve {
+		if st.duration == 0 {
+			return st.status
+		}
+		total += st.duration
+		if elapsed < total {
+			return st.status
+		}
+	}
+	return "SUCCEEDED"
+}
+
+// parseCurve parses "PENDING:5s,RUNNING:25s[,FAILED]" into stages.
+// A terminal status without a duration ends the curve.
+func parseCurve(curve string) ([]stage, error) {
+	var stages []stage
+	for _, part := range strings.Split(curve, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		status, durationStr, hasDuration := strings.Cut(part, ":")
+		st := stage{status: strings.ToUpper(status)}
+		if hasDuration {
+			d, err := time.ParseDuration(durationStr)
+			if err != nil {
+				return nil, fmt.Errorf("%q: %w", part, err)
+			}
+			if d <= 0 {
+				return nil, fmt.Errorf("%q: duration must be positive", part)
+			}
+			st.duration = d
+		}
+		stages = append(stages, st)
+	}
+	return stages, nil
+}
+
+// videoSize maps request parameters to the "width*height" size DashScope reports
+func videoSize(resolution, size string) string {
+	if size != "" {
+		return size
+	}
+	switch strings.ToUpper(resolution) {
+	case "480P":
+		return "832*480"
+	case "1080P":
+		return "1920*1080"
+	default:
+		return "1280*720"
+	}
+}
+
+// submitFailStatus returns the HTTP status DashScope uses for an error code
+func submitFailStatus(code string) int {
+	switch {
+	case strings.HasPrefix(code, "Throttling"):
+		return http.StatusTooManyRequests
+	case code == "InvalidApiKey":
+		return http.StatusUnauthorized
+	case code == "DataInspectionFailed", strings.HasPrefix(code, "InvalidParameter"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errorBody builds a DashScope error response
+func errorBody(code, message string) gin.H {
+	return gin.H{
+		"request_id": uuid.New().String(),
+		"code":       code,
+		"message":    message,
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
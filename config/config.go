@@ -1,9 +1,12 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -29,6 +32,10 @@ type Config struct {
 	AI       AIConfig
 	Storage  StorageConfig
 	CORS     CORSConfig
+	Worker   WorkerConfig
+	Media    MediaConfig
+	Proxy    ImageProxyConfig
+	MediaGC  MediaGCConfig
 }
 
 // AppConfig holds application-level configuration
@@ -89,23 +96,141 @@ type GoogleConfig struct {
 
 // AIConfig holds AI provider configuration
 type AIConfig struct {
-	GeminiAPIKey    string
+	GeminiAPIKeys   []string
 	OpenAIAPIKey    string
 	RunwayAPIKey    string
 	PikaAPIKey      string
-	WanAIAPIKey     string
+	WanAIAPIKeys    []string
 	WanAIVersion    string
 	WanAIBaseURL    string
 	UseMockProvider bool
+	MockVideoURL    string // Sample video returned by the mock provider
+
+	// Mock provider timing; failure scenarios always progress over MockCompletionTime
+	MockSimulateTime   bool // Successful mock jobs progress over MockCompletionTime instead of completing instantly
+	MockCompletionTime time.Duration
+
+	// Provider API key pools
+	KeyStrategy string        // round_robin or least_used
+	KeyCooldown time.Duration // How long a key rejected for auth or quota errors stays disabled
+
+	// Record/replay of provider HTTP traffic
+	CassetteMode string // off, record or replay
+	CassetteDir  string
+
+	// Provider health monitoring
+	HealthCheckInterval time.Duration
+	HealthHistorySize   int
+
+	// Cost-aware routing
+	RoutingWeights  map[string]RoutingWeightsConfig // Overrides keyed by user tier
+	ProviderBudgets map[string]ProviderBudgetConfig // keyed by provider name
+
+	// Outbound provider API throttling
+	ProviderRateLimits  map[string]map[string]RateLimitConfig // keyed by provider name, then operation (submit, status)
+	ProviderDailyQuotas map[string]int                        // submissions per UTC day, keyed by provider name
+
+	// Weighted traffic splits between providers
+	Experiments []ExperimentConfig
+}
+
+// RoutingWeightsConfig holds provider routing weights for a user tier
+type RoutingWeightsConfig struct {
+	Cost    float64
+	Quality float64
+	Latency float64
+}
+
+// ProviderBudgetConfig holds spend caps for a provider in USD (zero means unlimited)
+type ProviderBudgetConfig struct {
+	Daily   float64
+	Monthly float64
+}
+
+// RateLimitConfig holds a token bucket: Rate tokens per second, up to Burst
+type RateLimitConfig struct {
+	Rate  float64
+	Burst int
+}
+
+// ExperimentConfig holds a weighted traffic split, optionally scoped to a user tier or template
+type ExperimentConfig struct {
+	Name       string                `json:"name"`
+	Tier       string                `json:"tier,omitempty"`
+	TemplateID string                `json:"template_id,omitempty"`
+	Arms       []ExperimentArmConfig `json:"arms"`
+}
+
+// ExperimentArmConfig holds one arm of an experiment
+type ExperimentArmConfig struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+	Weight   int    `json:"weight"`
 }
 
 // StorageConfig holds storage configuration
 type StorageConfig struct {
+	// Generated media: "s3" for an S3-compatible bucket, "local" for the filesystem
+	Backend      string
 	S3Bucket     string
 	S3Region     string
+	S3Endpoint   string // Custom endpoint for S3-compatible services such as MinIO
+	S3PathStyle  bool
 	CDNBaseURL   string
 	AWSAccessKey string
 	AWSSecretKey string
+	MediaDir     string // Root directory of the local backend
+
+	// Private user assets (e.g. start images), served only through signed URLs
+	AssetDir       string
+	AssetURLSecret string // HMAC key for signed asset URLs; must differ from the JWT secret
+	AssetURLTTL    time.Duration
+
+	// Signed media URLs. The first key signs and every key verifies, so keys rotate without breaking live links.
+	MediaURLKeys []URLSigningKeyConfig
+	MediaURLTTL  time.Duration
+}
+
+// URLSigningKeyConfig holds an identified HMAC key for signed URLs
+type URLSigningKeyConfig struct {
+	ID     string
+	Secret string
+}
+
+// MediaConfig holds media post-processing configuration
+type MediaConfig struct {
+	FFmpegPath        string // ffmpeg binary used for posters and previews (looked up on PATH if bare)
+	FFprobePath       string // ffprobe binary used to measure videos (looked up on PATH if bare)
+	HLSEnabled        bool   // Transcode completed videos into an adaptive HLS ladder
+	WatermarkImage    string // Logo overlaid on free-tier videos (empty disables watermarking)
+	WatermarkPosition string // top-left, top-right, bottom-left, bottom-right or center
+	WatermarkMargin   int    // Distance of the logo from the edges, in pixels
+}
+
+// ImageProxyConfig holds settings for the external image proxy
+type ImageProxyConfig struct {
+	AllowedHosts  []string // Exact hostnames, "*.domain" wildcards, or "*" for any public host (defaults to our own hosts)
+	HTTPOnlyHosts []string // Hosts fetched over plain HTTP because their TLS setup is broken
+	MaxBytes      int64
+	CacheDir      string
+	CacheMaxBytes int64
+	CacheTTL      time.Duration
+}
+
+// MediaGCConfig holds settings for garbage collecting orphaned media
+type MediaGCConfig struct {
+	Enabled     bool          // Run the periodic sweep; the admin endpoint works either way
+	Interval    time.Duration // Time between sweeps
+	GracePeriod time.Duration // Unreferenced files younger than this are kept
+	DryRun      bool          // Only log what periodic sweeps would delete
+}
+
+// WorkerConfig holds video worker configuration
+type WorkerConfig struct {
+	PollInterval  time.Duration // How often providers are polled for progress and queued watermark removals are checked
+	PollTimeout   time.Duration // How long a job may run before it fails
+	MaxPollErrors int           // Consecutive poll errors before a job fails
 }
 
 // CORSConfig holds CORS configuration
@@ -122,6 +247,11 @@ func Load() (*Config, error) {
 		_ = godotenv.Load()
 	}
 
+	experiments, err := getEnvExperiments("PROVIDER_EXPERIMENTS")
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
 		App: AppConfig{
 			Name:        getEnv("APP_NAME", "Arabella"),
@@ -169,21 +299,62 @@ func Load() (*Config, error) {
 			ClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
 		},
 		AI: AIConfig{
-			GeminiAPIKey:    getEnv("GEMINI_API_KEY", ""),
+			GeminiAPIKeys:   getEnvKeys("GEMINI_API_KEYS", "GEMINI_API_KEY"),
 			OpenAIAPIKey:    getEnv("OPENAI_API_KEY", ""),
 			RunwayAPIKey:    getEnv("RUNWAY_API_KEY", ""),
 			PikaAPIKey:      getEnv("PIKA_API_KEY", ""),
-			WanAIAPIKey:     getEnv("WANAI_API_KEY", ""),
+			WanAIAPIKeys:    getEnvKeys("WANAI_API_KEYS", "WANAI_API_KEY"),
 			WanAIVersion:    getEnv("WANAI_VERSION", "2.5"),
 			WanAIBaseURL:    getEnv("WANAI_BASE_URL", "https://dashscope-intl.aliyuncs.com/compatible-mode/v1"),
 			UseMockProvider: getEnvBool("USE_MOCK_PROVIDER", true),
+			MockVideoURL:    getEnv("MOCK_VIDEO_URL", "https://commondatastorage.googleapis.com/gtv-videos-bucket/sample/BigBuckBunny.mp4"),
+
+			MockSimulateTime:   getEnvBool("MOCK_SIMULATE_TIME", false),
+			MockCompletionTime: getEnvDuration("MOCK_COMPLETION_TIME", 30*time.Second),
+
+			KeyStrategy: getEnv("PROVIDER_KEY_STRATEGY", "round_robin"),
+			KeyCooldown: getEnvDuration("PROVIDER_KEY_COOLDOWN", 10*time.Minute),
+
+			CassetteMode: getEnv("PROVIDER_CASSETTE_MODE", "off"),
+			CassetteDir:  getEnv("PROVIDER_CASSETTE_DIR", "./testdata/cassettes"),
+
+			HealthCheckInterval: getEnvDuration("PROVIDER_HEALTH_CHECK_INTERVAL", 30*time.Second),
+			HealthHistorySize:   getEnvInt("PROVIDER_HEALTH_HISTORY_SIZE", 120),
+
+			// Weights are "cost,quality,latency"; tiers without an override keep the routing policy defaults
+			RoutingWeights: getEnvWeights(map[string]string{
+				"free":    "ROUTING_WEIGHTS_FREE",
+				"premium": "ROUTING_WEIGHTS_PREMIUM",
+				"pro":     "ROUTING_WEIGHTS_PRO",
+			}),
+			// Budgets are "provider:daily:monthly", e.g. "wan_ai:50:1000,gemini_veo:20:300"
+			ProviderBudgets: getEnvBudgets("PROVIDER_BUDGETS"),
+			// Rate limits are "provider:operation:rate:burst", quotas are "provider:count", e.g. "wan_ai:500"
+			ProviderRateLimits:  getEnvRateLimits("PROVIDER_RATE_LIMITS", []string{"wan_ai:submit:0.5:2", "wan_ai:status:5:10"}),
+			ProviderDailyQuotas: getEnvQuotas("PROVIDER_DAILY_QUOTAS"),
+			// Experiments are a JSON array, e.g.
+			// [{"name":"wan_2_6","tier":"free","arms":[{"name":"control","provider":"wan_ai","model":"2.5","weight":90},
+			//   {"name":"wan_2_6","provider":"wan_ai","model":"2.6","weight":10}]}]
+			Experiments: experiments,
 		},
 		Storage: StorageConfig{
+			Backend:      getEnv("STORAGE_BACKEND", "local"),
 			S3Bucket:     getEnv("S3_BUCKET", "arabella-videos"),
 			S3Region:     getEnv("S3_REGION", "us-east-1"),
+			S3Endpoint:   getEnv("S3_ENDPOINT", ""),
+			S3PathStyle:  getEnvBool("S3_FORCE_PATH_STYLE", false),
 			CDNBaseURL:   getEnv("CDN_BASE_URL", "https://cdn.arabella.app"),
 			AWSAccessKey: getEnv("AWS_ACCESS_KEY_ID", ""),
 			AWSSecretKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
+			MediaDir:     getEnv("MEDIA_STORAGE_DIR", "./storage/media"),
+
+			AssetDir:       getEnv("ASSET_STORAGE_DIR", "./storage/assets"),
+			AssetURLSecret: getEnv("ASSET_URL_SECRET", ""),
+			AssetURLTTL:    getEnvDuration("ASSET_URL_TTL", time.Hour),
+
+			// Keys are "id:secret" pairs, newest first, e.g. "2025b:new-secret,2025a:old-secret"
+			MediaURLKeys: getEnvSigningKeys("MEDIA_URL_KEYS"),
+			MediaURLTTL:  getEnvDuration("MEDIA_URL_TTL", 2*time.Hour),
 		},
 		CORS: CORSConfig{
 			AllowedOrigins: getEnvSlice("CORS_ALLOWED_ORIGINS", []string{
@@ -193,6 +364,39 @@ func Load() (*Config, error) {
 			AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 			AllowedHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With"},
 		},
+		Worker: WorkerConfig{
+			PollInterval:  getEnvDuration("WORKER_POLL_INTERVAL", 5*time.Second),
+			PollTimeout:   getEnvDuration("WORKER_POLL_TIMEOUT", 30*time.Minute),
+			MaxPollErrors: getEnvInt("WORKER_MAX_POLL_ERRORS", 5),
+		},
+		Proxy: ImageProxyConfig{
+			AllowedHosts:  getEnvSlice("IMAGE_PROXY_ALLOWED_HOSTS", nil),
+			HTTPOnlyHosts: getEnvSlice("IMAGE_PROXY_HTTP_ONLY_HOSTS", []string{"nanobanana.uz"}),
+			MaxBytes:      int64(getEnvInt("IMAGE_PROXY_MAX_BYTES", 20*1024*1024)),
+			CacheDir:      getEnv("IMAGE_PROXY_CACHE_DIR", "./static/temp-images"),
+			CacheMaxBytes: int64(getEnvInt("IMAGE_PROXY_CACHE_MAX_BYTES", 512*1024*1024)),
+			CacheTTL:      getEnvDuration("IMAGE_PROXY_CACHE_TTL", 24*time.Hour),
+		},
+		MediaGC: MediaGCConfig{
+			Enabled:     getEnvBool("MEDIA_GC_ENABLED", false),
+			Interval:    getEnvDuration("MEDIA_GC_INTERVAL", 24*time.Hour),
+			GracePeriod: getEnvDuration("MEDIA_GC_GRACE_PERIOD", 7*24*time.Hour),
+			DryRun:      getEnvBool("MEDIA_GC_DRY_RUN", true),
+		},
+		Media: MediaConfig{
+			FFmpegPath:        getEnv("FFMPEG_PATH", "ffmpeg"),
+			FFprobePath:       getEnv("FFPROBE_PATH", "ffprobe"),
+			HLSEnabled:        getEnvBool("HLS_ENABLED", false),
+			WatermarkImage:    getEnv("WATERMARK_IMAGE", ""),
+			WatermarkPosition: getEnv("WATERMARK_POSITION", "bottom-right"),
+			WatermarkMargin:   getEnvInt("WATERMARK_MARGIN", 24),
+		},
+	}
+
+	// By default the image proxy fetches from our own API, CDN and frontends plus the template image host
+	if len(cfg.Proxy.AllowedHosts) == 0 {
+		firstParty := append([]string{cfg.Server.BaseURL, cfg.Storage.CDNBaseURL}, cfg.CORS.AllowedOrigins...)
+		cfg.Proxy.AllowedHosts = append(urlHosts(firstParty), "nanobanana.uz", "*.nanobanana.uz")
 	}
 
 	// Validate required configuration
@@ -209,6 +413,26 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("JWT secret key is required")
 	}
 
+	// A leaked URL signing key must not let anyone mint auth tokens, so they never share the JWT secret
+	if c.Storage.AssetURLSecret == "" {
+		return fmt.Errorf("ASSET_URL_SECRET is required")
+	}
+	if c.Storage.AssetURLSecret == c.JWT.SecretKey {
+		return fmt.Errorf("ASSET_URL_SECRET must differ from JWT_SECRET")
+	}
+	if len(c.Storage.MediaURLKeys) == 0 {
+		return fmt.Errorf("MEDIA_URL_KEYS is required")
+	}
+	for _, key := range c.Storage.MediaURLKeys {
+		if key.Secret == c.JWT.SecretKey {
+			return fmt.Errorf("MEDIA_URL_KEYS key %q must differ from JWT_SECRET", key.ID)
+		}
+	}
+
+	if c.Storage.Backend != "local" && c.Storage.Backend != "s3" {
+		return fmt.Errorf("STORAGE_BACKEND must be local or s3, got %q", c.Storage.Backend)
+	}
+
 	if c.App.Environment == EnvProduction {
 		if c.JWT.SecretKey == "your-super-secret-key-change-in-production" {
 			return fmt.Errorf("JWT secret key must be changed in production")
@@ -264,6 +488,170 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+func getEnvKeys(poolKey, singleKey string) []string {
+	var keys []string
+	for _, key := range getEnvSlice(poolKey, nil) {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		if key := getEnv(singleKey, ""); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// getEnvWeights reads the routing weight overrides for each tier from its environment variable,
+// skipping tiers whose variable is unset or malformed
+func getEnvWeights(keys map[string]string) map[string]RoutingWeightsConfig {
+	weights := make(map[string]RoutingWeightsConfig)
+	for tier, key := range keys {
+		parts := getEnvSlice(key, nil)
+		if len(parts) != 3 {
+			continue
+		}
+
+		var values [3]float64
+		valid := true
+		for i, part := range parts {
+			value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil || value < 0 {
+				valid = false
+				break
+			}
+			values[i] = value
+		}
+		if valid {
+			weights[tier] = RoutingWeightsConfig{Cost: values[0], Quality: values[1], Latency: values[2]}
+		}
+	}
+	return weights
+}
+
+func getEnvBudgets(key string) map[string]ProviderBudgetConfig {
+	budgets := make(map[string]ProviderBudgetConfig)
+	for _, entry := range getEnvSlice(key, nil) {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 3 {
+			continue
+		}
+
+		daily, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			continue
+		}
+		monthly, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil {
+			continue
+		}
+
+		budgets[parts[0]] = ProviderBudgetConfig{Daily: daily, Monthly: monthly}
+	}
+	return budgets
+}
+
+func getEnvRateLimits(key string, defaultValue []string) map[string]map[string]RateLimitConfig {
+	limits := make(map[string]map[string]RateLimitConfig)
+	for _, entry := range getEnvSlice(key, defaultValue) {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 4 {
+			continue
+		}
+
+		rate, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil || rate <= 0 {
+			continue
+		}
+		burst, err := strconv.Atoi(parts[3])
+		if err != nil || burst <= 0 {
+			continue
+		}
+
+		if limits[parts[0]] == nil {
+			limits[parts[0]] = make(map[string]RateLimitConfig)
+		}
+		limits[parts[0]][parts[1]] = RateLimitConfig{Rate: rate, Burst: burst}
+	}
+	return limits
+}
+
+func getEnvQuotas(key string) map[string]int {
+	quotas := make(map[string]int)
+	for _, entry := range getEnvSlice(key, nil) {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 2 {
+			continue
+		}
+
+		quota, err := strconv.Atoi(parts[1])
+		if err != nil || quota <= 0 {
+			continue
+		}
+
+		quotas[parts[0]] = quota
+	}
+	return quotas
+}
+
+func getEnvSigningKeys(key string) []URLSigningKeyConfig {
+	var keys []URLSigningKeyConfig
+	for _, entry := range getEnvSlice(key, nil) {
+		id, secret, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" || secret == "" {
+			continue
+		}
+		keys = append(keys, URLSigningKeyConfig{ID: id, Secret: secret})
+	}
+	return keys
+}
+
+func getEnvExperiments(key string) ([]ExperimentConfig, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	var experiments []ExperimentConfig
+	if err := json.Unmarshal([]byte(value), &experiments); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	for _, experiment := range experiments {
+		if experiment.Name == "" || len(experiment.Arms) == 0 {
+			return nil, fmt.Errorf("invalid %s: every experiment needs a name and at least one arm", key)
+		}
+		for _, arm := range experiment.Arms {
+			if arm.Name == "" || arm.Provider == "" {
+				return nil, fmt.Errorf("invalid %s: every arm of experiment %q needs a name and a provider", key, experiment.Name)
+			}
+			// An arm without weight never receives traffic, which is almost certainly a mistake
+			if arm.Weight <= 0 {
+				return nil, fmt.Errorf("invalid %s: arm %q of experiment %q needs a positive weight", key, arm.Name, experiment.Name)
+			}
+		}
+	}
+	return experiments, nil
+}
+
+// urlHosts returns the distinct hostnames of a list of URLs, skipping any that don't parse
+func urlHosts(rawURLs []string) []string {
+	var hosts []string
+	seen := make(map[string]bool)
+	for _, rawURL := range rawURLs {
+		u, err := url.Parse(strings.TrimSpace(rawURL))
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
 func getEnvSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
 		// Simple comma-separated parsing
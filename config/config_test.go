@@ -0,0 +1,125 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLoadDefaultImageProxyHosts(t *testing.T) {
+	t.Setenv("APP_ENV", "production")
+	setRequiredEnv(t)
+	t.Setenv("IMAGE_PROXY_ALLOWED_HOSTS", "")
+	t.Setenv("API_BASE_URL", "https://api.arabella.uz")
+	t.Setenv("CDN_BASE_URL", "https://cdn.arabella.app/media")
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://arabella.uz, https://API.arabella.uz,not a url")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := []string{"api.arabella.uz", "cdn.arabella.app", "arabella.uz", "nanobanana.uz", "*.nanobanana.uz"}
+	if !reflect.DeepEqual(cfg.Proxy.AllowedHosts, want) {
+		t.Errorf("AllowedHosts = %v, want %v", cfg.Proxy.AllowedHosts, want)
+	}
+}
+
+func TestLoadExplicitImageProxyHosts(t *testing.T) {
+	t.Setenv("APP_ENV", "production")
+	setRequiredEnv(t)
+	t.Setenv("IMAGE_PROXY_ALLOWED_HOSTS", "images.example.com")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if want := []string{"images.example.com"}; !reflect.DeepEqual(cfg.Proxy.AllowedHosts, want) {
+		t.Errorf("AllowedHosts = %v, want %v", cfg.Proxy.AllowedHosts, want)
+	}
+}
+
+func TestLoadRoutingWeightOverrides(t *testing.T) {
+	t.Setenv("APP_ENV", "production")
+	setRequiredEnv(t)
+	t.Setenv("ROUTING_WEIGHTS_FREE", "0.5,0.3,0.2")
+	t.Setenv("ROUTING_WEIGHTS_PREMIUM", "")
+	t.Setenv("ROUTING_WEIGHTS_PRO", "1,bad,0")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := map[string]RoutingWeightsConfig{"free": {Cost: 0.5, Quality: 0.3, Latency: 0.2}}
+	if !reflect.DeepEqual(cfg.AI.RoutingWeights, want) {
+		t.Errorf("RoutingWeights = %v, want %v", cfg.AI.RoutingWeights, want)
+	}
+}
+
+func TestLoadRejectsInvalidExperimentArms(t *testing.T) {
+	tests := map[string]string{
+		"zero weight":      `[{"name":"wan_2_6","arms":[{"name":"control","provider":"wan_ai","weight":100},{"name":"wan_2_6","provider":"wan_ai","weight":0}]}]`,
+		"negative weight":  `[{"name":"wan_2_6","arms":[{"name":"control","provider":"wan_ai","weight":-5}]}]`,
+		"missing provider": `[{"name":"wan_2_6","arms":[{"name":"control","weight":100}]}]`,
+		"no arms":          `[{"name":"wan_2_6","arms":[]}]`,
+	}
+
+	for name, experiments := range tests {
+		t.Run(name, func(t *testing.T) {
+			setRequiredEnv(t)
+			t.Setenv("PROVIDER_EXPERIMENTS", experiments)
+
+			if _, err := Load(); err == nil {
+				t.Fatal("Load accepted an invalid experiment")
+			}
+		})
+	}
+}
+
+// setRequiredEnv sets the secrets Load refuses to start without
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-jwt-secret")
+	t.Setenv("ASSET_URL_SECRET", "test-asset-secret")
+	t.Setenv("MEDIA_URL_KEYS", "test:test-media-secret")
+}
+
+func TestLoadRequiresDistinctAssetURLSecret(t *testing.T) {
+	setRequiredEnv(t)
+	t.Setenv("JWT_SECRET", "shared-secret")
+
+	t.Setenv("ASSET_URL_SECRET", "")
+	if _, err := Load(); err == nil {
+		t.Error("Load started without an asset URL secret")
+	}
+
+	t.Setenv("ASSET_URL_SECRET", "shared-secret")
+	if _, err := Load(); err == nil {
+		t.Error("Load accepted an asset URL secret equal to the JWT secret")
+	}
+}
+
+func TestLoadRequiresMediaURLKeys(t *testing.T) {
+	setRequiredEnv(t)
+
+	t.Setenv("MEDIA_URL_KEYS", "")
+	if _, err := Load(); err == nil {
+		t.Error("Load started without a media URL signing key")
+	}
+
+	t.Setenv("MEDIA_URL_KEYS", "2025b:new-secret,2025a:test-jwt-secret")
+	if _, err := Load(); err == nil {
+		t.Error("Load accepted a media URL key equal to the JWT secret")
+	}
+
+	t.Setenv("MEDIA_URL_KEYS", "2025b:new-secret,2025a:old-secret")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	want := []URLSigningKeyConfig{{ID: "2025b", Secret: "new-secret"}, {ID: "2025a", Secret: "old-secret"}}
+	if !reflect.DeepEqual(cfg.Storage.MediaURLKeys, want) {
+		t.Errorf("MediaURLKeys = %v, want %v", cfg.Storage.MediaURLKeys, want)
+	}
+}
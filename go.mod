@@ -16,6 +16,7 @@ require (
 	github.com/swaggo/gin-swagger v1.6.0
 	github.com/swaggo/swag v1.16.2
 	go.uber.org/zap v1.26.0
+	golang.org/x/image v0.33.0
 	google.golang.org/api v0.257.0
 )
 
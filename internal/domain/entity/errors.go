@@ -21,18 +21,28 @@ var (
 	ErrJobAlreadyCompleted  = errors.New("video job already completed")
 	ErrJobCannotBeCancelled = errors.New("video job cannot be cancelled")
 	ErrJobAlreadyCancelled  = errors.New("video job already cancelled")
+	ErrVideoNotWatermarked  = errors.New("video has no watermark to remove")
+	ErrWatermarkRemovalNotAllowed = errors.New("removing watermarks requires a premium or pro plan")
+	ErrWatermarkRemovalInProgress = errors.New("watermark removal already queued for this video")
 
 	// Provider errors
 	ErrProviderUnavailable  = errors.New("AI provider unavailable")
 	ErrProviderRateLimited  = errors.New("AI provider rate limited")
 	ErrProviderTimeout      = errors.New("AI provider timeout")
 	ErrGenerationFailed     = errors.New("video generation failed")
+	ErrProviderBudgetExceeded = errors.New("AI provider budget exceeded")
+	ErrUnknownProvider      = errors.New("unknown AI provider")
+	ErrProviderQuotaExceeded = errors.New("AI provider daily quota exceeded")
+	ErrProviderOverrideNotAllowed = errors.New("provider override requires a premium or pro plan")
+	ErrStartImageNotSupported = errors.New("provider does not support image-to-video")
+	ErrAudioNotSupported = errors.New("provider does not support audio input")
 
 	// Validation errors
 	ErrInvalidInput         = errors.New("invalid input")
 	ErrInvalidPrompt        = errors.New("invalid prompt")
 	ErrInvalidParams        = errors.New("invalid video parameters")
 	ErrPromptTooLong        = errors.New("prompt exceeds maximum length")
+	ErrParamsNotSupported   = errors.New("requested video parameters are not supported")
 
 	// Authentication errors
 	ErrInvalidToken         = errors.New("invalid token")
@@ -45,6 +55,22 @@ var (
 	// Storage errors
 	ErrStorageUploadFailed  = errors.New("storage upload failed")
 	ErrStorageDownloadFailed = errors.New("storage download failed")
+	ErrInvalidMediaURL      = errors.New("invalid or expired media URL")
+	ErrMediaNotFound        = errors.New("media not found")
+	ErrMediaGCInProgress    = errors.New("media garbage collection is already running")
+
+	// Media processing errors
+	ErrMediaToolUnavailable = errors.New("media tool is not available")
+
+	// Image proxy errors
+	ErrProxyTargetNotAllowed = errors.New("image URL is not allowed")
+	ErrProxyFetchFailed      = errors.New("failed to fetch image")
+	ErrProxyInvalidImage     = errors.New("source did not return a valid image")
+
+	// Asset errors
+	ErrAssetNotFound        = errors.New("asset not found")
+	ErrInvalidAsset         = errors.New("invalid asset file")
+	ErrInvalidAssetURL      = errors.New("invalid or expired asset URL")
 )
 
 // DomainError represents a domain-level error with additional context
@@ -1,6 +1,9 @@
 package entity
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -39,6 +42,23 @@ const (
 	AspectRatio4x3  AspectRatio = "4:3"
 )
 
+// ratio returns the width divided by the height of an aspect ratio such as "16:9"
+func (a AspectRatio) ratio() (float64, bool) {
+	width, height, ok := strings.Cut(string(a), ":")
+	if !ok {
+		return 0, false
+	}
+	w, err := strconv.ParseFloat(width, 64)
+	if err != nil || w <= 0 {
+		return 0, false
+	}
+	h, err := strconv.ParseFloat(height, 64)
+	if err != nil || h <= 0 {
+		return 0, false
+	}
+	return w / h, true
+}
+
 // VideoParams contains configurable video generation parameters
 // VideoParams represents video generation parameters
 // @Description Parameters for video generation (duration, resolution, aspect ratio, etc.)
@@ -49,6 +69,77 @@ type VideoParams struct {
 	FPS            int             `json:"fps" example:"30"`                    // Frames per second
 	Style          string          `json:"style,omitempty" example:"cinematic"` // Visual style modifier
 	NegativePrompt string          `json:"negative_prompt,omitempty" example:"blurry, low quality"`
+	Advanced       *AdvancedParams `json:"advanced,omitempty"` // Optional provider-specific features
+}
+
+// ShotType controls whether a video is one continuous shot or cut into several
+type ShotType string
+
+const (
+	ShotTypeSingle ShotType = "single"
+	ShotTypeMulti  ShotType = "multi"
+)
+
+// ReferenceImageRole says whether a reference image supplies a subject or the background
+type ReferenceImageRole string
+
+const (
+	ReferenceImageObject     ReferenceImageRole = "obj"
+	ReferenceImageBackground ReferenceImageRole = "bg"
+)
+
+// MaxSeed is the largest seed providers accept
+const MaxSeed int64 = 2147483647
+
+// ReferenceImage is an image the provider draws a subject or background from
+type ReferenceImage struct {
+	URL  string             `json:"url" example:"https://cdn.arabella.app/refs/product.png"`
+	Role ReferenceImageRole `json:"role,omitempty" example:"obj" enums:"obj,bg"`
+}
+
+// AdvancedParams are optional generation features only some providers support.
+// Each one is checked against the selected provider's capabilities.
+// @Description Optional provider-specific features (seed, audio, multi-shot, reference images)
+type AdvancedParams struct {
+	Seed            *int64           `json:"seed,omitempty" example:"42"`    // Fixed seed for reproducible output
+	Audio           *bool            `json:"audio,omitempty" example:"true"` // Generate a soundtrack
+	AudioURL        string           `json:"audio_url,omitempty"`            // Custom soundtrack to sync the video to
+	ShotType        ShotType         `json:"shot_type,omitempty" example:"multi" enums:"single,multi"`
+	ReferenceImages []ReferenceImage `json:"reference_images,omitempty"`
+}
+
+// IsEmpty reports whether no advanced feature is requested
+func (a *AdvancedParams) IsEmpty() bool {
+	return a == nil || (a.Seed == nil && a.Audio == nil && a.AudioURL == "" && a.ShotType == "" && len(a.ReferenceImages) == 0)
+}
+
+// Validate checks advanced params are well formed; provider support is checked separately
+func (a *AdvancedParams) Validate() error {
+	if a == nil {
+		return nil
+	}
+	if a.Seed != nil && (*a.Seed < 0 || *a.Seed > MaxSeed) {
+		return fmt.Errorf("%w: seed must be between 0 and %d", ErrInvalidParams, MaxSeed)
+	}
+	if a.AudioURL != "" && !isHTTPURL(a.AudioURL) {
+		return fmt.Errorf("%w: audio_url must be an http(s) URL", ErrInvalidParams)
+	}
+	if a.ShotType != "" && a.ShotType != ShotTypeSingle && a.ShotType != ShotTypeMulti {
+		return fmt.Errorf("%w: shot_type must be single or multi", ErrInvalidParams)
+	}
+	for _, ref := range a.ReferenceImages {
+		if !isHTTPURL(ref.URL) {
+			return fmt.Errorf("%w: reference image URLs must be http(s) URLs", ErrInvalidParams)
+		}
+		if ref.Role != "" && ref.Role != ReferenceImageObject && ref.Role != ReferenceImageBackground {
+			return fmt.Errorf("%w: reference image role must be obj or bg", ErrInvalidParams)
+		}
+	}
+	return nil
+}
+
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
 }
 
 // DefaultVideoParams returns default video parameters
@@ -25,6 +25,7 @@ type User struct {
 	Credits               int        `json:"credits"`
 	Tier                  UserTier   `json:"tier"`
 	SubscriptionExpiresAt *time.Time `json:"subscription_expires_at,omitempty"`
+	IsAdmin               bool       `json:"-"` // Granted in the database only; never set from a login
 	CreatedAt             time.Time  `json:"created_at"`
 	UpdatedAt             time.Time  `json:"updated_at"`
 }
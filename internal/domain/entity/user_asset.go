@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AssetKind is the type of a user-uploaded asset
+type AssetKind string
+
+const (
+	AssetKindImage AssetKind = "image"
+	AssetKindAudio AssetKind = "audio"
+)
+
+// UserAsset is a file a user uploaded for use in their own generations.
+// Assets are stored privately and only reachable through signed URLs.
+// @Description User-uploaded asset such as a start image or soundtrack
+type UserAsset struct {
+	ID          uuid.UUID `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID      uuid.UUID `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Kind        AssetKind `json:"kind" example:"image" enums:"image,audio"`
+	StorageKey  string    `json:"-"`
+	ContentType string    `json:"content_type" example:"image/jpeg"`
+	SizeBytes   int64     `json:"size_bytes" example:"245760"`
+	Width       int       `json:"width,omitempty" example:"1280"`
+	Height      int       `json:"height,omitempty" example:"720"`
+	Duration    float64   `json:"duration_seconds,omitempty" example:"5.2"` // Audio length in seconds
+	URL         string    `json:"url,omitempty"`                            // Short-lived signed URL, set when the asset is returned
+	CreatedAt   time.Time `json:"created_at" example:"2025-12-13T16:00:00Z"`
+}
+
+// NewUserAsset creates a new asset record for a user
+func NewUserAsset(userID uuid.UUID, kind AssetKind, contentType string) *UserAsset {
+	return &UserAsset{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Kind:        kind,
+		ContentType: contentType,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// IsOwnedBy reports whether the asset belongs to the user
+func (a *UserAsset) IsOwnedBy(userID uuid.UUID) bool {
+	return a.UserID == userID
+}
@@ -1,6 +1,10 @@
 package entity
 
 import (
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,26 +35,104 @@ const (
 	ProviderMock       AIProvider = "mock" // For development
 )
 
+// WatermarkRemovalStatus tracks a watermark removal queued for the background worker
+type WatermarkRemovalStatus string
+
+const (
+	WatermarkRemovalPending    WatermarkRemovalStatus = "pending"
+	WatermarkRemovalProcessing WatermarkRemovalStatus = "processing"
+	WatermarkRemovalFailed     WatermarkRemovalStatus = "failed"
+)
+
 // VideoJob represents a video generation job
 // @Description Video generation job with status, progress, and result URLs
 type VideoJob struct {
-	ID              uuid.UUID   `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	UserID          uuid.UUID   `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	TemplateID      uuid.UUID   `json:"template_id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Prompt          string      `json:"prompt" example:"A beautiful sunset over mountains"`
-	Params          VideoParams `json:"params"`
-	Status          JobStatus   `json:"status" example:"completed" enums:"pending,processing,diffusing,uploading,completed,failed,cancelled"`
-	Progress        int         `json:"progress" example:"100" minimum:"0" maximum:"100"` // 0-100
-	Provider        AIProvider  `json:"provider" example:"gemini_veo" enums:"gemini_veo,openai_sora,runway,pika_labs,wan_ai,mock"`
-	ProviderJobID   *string     `json:"provider_job_id,omitempty" example:"gemini-job-123"`
-	VideoURL        *string     `json:"video_url,omitempty" example:"https://storage.googleapis.com/gemini-videos/abc123.mp4"`
-	ThumbnailURL    *string     `json:"thumbnail_url,omitempty" example:"https://cdn.arabella.app/thumbnails/abc123.jpg"`
-	DurationSeconds int         `json:"duration_seconds,omitempty" example:"15"`
-	CreditsCharged  int         `json:"credits_charged" example:"2"`
-	ErrorMessage    *string     `json:"error_message,omitempty" example:"Generation failed"`
-	CreatedAt       time.Time   `json:"created_at" example:"2025-12-13T16:00:00Z"`
-	StartedAt       *time.Time  `json:"started_at,omitempty" example:"2025-12-13T16:00:05Z"`
-	CompletedAt     *time.Time  `json:"completed_at,omitempty" example:"2025-12-13T16:02:00Z"`
+	ID                uuid.UUID               `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	UserID            uuid.UUID               `json:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TemplateID        uuid.UUID               `json:"template_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Prompt            string                  `json:"prompt" example:"A beautiful sunset over mountains"`
+	Params            VideoParams             `json:"params"`
+	Status            JobStatus               `json:"status" example:"completed" enums:"pending,processing,diffusing,uploading,completed,failed,cancelled"`
+	Progress          int                     `json:"progress" example:"100" minimum:"0" maximum:"100"` // 0-100
+	Provider          AIProvider              `json:"provider" example:"gemini_veo" enums:"gemini_veo,openai_sora,runway,pika_labs,wan_ai,mock"`
+	RequestedProvider *AIProvider             `json:"requested_provider,omitempty" example:"wan_ai"`
+	ProviderReason    *string                 `json:"provider_reason,omitempty" example:"template preference: wan_ai"`
+	ParamAdjustments  []ParamAdjustment       `json:"param_adjustments,omitempty"`
+	ProviderJobID     *string                 `json:"provider_job_id,omitempty" example:"gemini-job-123"`
+	ProviderKeyID     *string                 `json:"-"`
+	Experiment        *string                 `json:"-"` // Traffic-split experiment the job was routed by
+	ExperimentArm     *string                 `json:"-"`
+	UserRating        *int                    `json:"user_rating,omitempty" example:"4" minimum:"1" maximum:"5"`
+	Seed              *int64                  `json:"seed,omitempty" example:"42"`                                            // Seed used for generation, for reproducible regenerations
+	ParentJobID       *uuid.UUID              `json:"parent_job_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // Job this one was regenerated from
+	StartImageID      *uuid.UUID              `json:"start_image_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	AudioID           *uuid.UUID              `json:"audio_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"` // Uploaded soundtrack the video is synchronised to
+	VideoURL          *string                 `json:"video_url,omitempty" example:"https://storage.googleapis.com/gemini-videos/abc123.mp4"`
+	VideoStorageKey   *string                 `json:"-"` // Key of the copy in our media storage
+	SourceKey         *string                 `json:"-"` // Key of the clean original kept for watermarked videos
+	Watermarked       bool                    `json:"watermarked" example:"false"`
+	WatermarkRemoval  *WatermarkRemovalStatus `json:"watermark_removal,omitempty" example:"pending" enums:"pending,processing,failed"` // Set while a removal is queued or running, and after one fails
+	HLSPlaylistURL    *string                 `json:"hls_playlist_url,omitempty" example:"https://cdn.arabella.app/hls/abc123/master.m3u8"`
+	HLSStorageKey     *string                 `json:"-"`
+	ThumbnailURL      *string                 `json:"thumbnail_url,omitempty" example:"https://cdn.arabella.app/thumbnails/abc123.jpg"`
+	ThumbnailKey      *string                 `json:"-"`
+	PreviewURL        *string                 `json:"preview_url,omitempty" example:"https://cdn.arabella.app/previews/abc123.gif"` // Short animated preview for gallery hover
+	PreviewKey        *string                 `json:"-"`
+	ShareToken        *string                 `json:"-"` // Grants access to the video stream without signing in
+	MockScenario      string                  `json:"-"` // Mock provider scenario for this run; carried by the queue, never stored
+	DurationSeconds   int                     `json:"duration_seconds,omitempty" example:"15"`
+	OutputResolution  *VideoResolution        `json:"output_resolution,omitempty" example:"720p"`
+	Metadata          *VideoMetadata          `json:"metadata,omitempty"` // Measured from the stored video (nil if it could not be probed)
+	VideoExpiresAt    *time.Time              `json:"video_expires_at,omitempty" example:"2025-12-14T16:02:00Z"`
+	CreditsCharged    int                     `json:"credits_charged" example:"2"`
+	ErrorMessage      *string                 `json:"error_message,omitempty" example:"Generation failed"`
+	CreatedAt         time.Time               `json:"created_at" example:"2025-12-13T16:00:00Z"`
+	StartedAt         *time.Time              `json:"started_at,omitempty" example:"2025-12-13T16:00:05Z"`
+	CompletedAt       *time.Time              `json:"completed_at,omitempty" example:"2025-12-13T16:02:00Z"`
+}
+
+// ParamAdjustment records a change made to a requested parameter so a provider could honour the request
+type ParamAdjustment struct {
+	Field     string `json:"field" example:"duration"`
+	Requested string `json:"requested" example:"8"`
+	Applied   string `json:"applied" example:"10"`
+	Reason    string `json:"reason" example:"supported durations are 5, 10, 15 seconds"`
+}
+
+// VideoMetadata describes a video as measured by probing the file, rather than as its provider reported it
+type VideoMetadata struct {
+	Width           int                `json:"width" example:"1280"`
+	Height          int                `json:"height" example:"720"`
+	FPS             float64            `json:"fps" example:"24"`
+	Codec           string             `json:"codec" example:"h264"`
+	SizeBytes       int64              `json:"size_bytes" example:"5242880"`
+	DurationSeconds float64            `json:"duration_seconds" example:"10.04"`
+	Mismatches      []MetadataMismatch `json:"mismatches,omitempty"` // Where the video differs from the requested params
+}
+
+// MetadataMismatch records a measured property of a video that differs from what was requested
+type MetadataMismatch struct {
+	Field     string `json:"field" example:"resolution"`
+	Requested string `json:"requested" example:"1080p"`
+	Measured  string `json:"measured" example:"1280x720"`
+}
+
+const (
+	// durationTolerance is how many seconds a video may be longer or shorter than requested
+	durationTolerance = 1.0
+
+	// fpsTolerance lets NTSC rates such as 29.97 match 30
+	fpsTolerance = 0.5
+
+	// aspectRatioTolerance is the relative difference allowed between the measured and requested aspect ratio
+	aspectRatioTolerance = 0.02
+)
+
+// resolutionHeights maps each output resolution to the length of the video's shorter side
+var resolutionHeights = map[VideoResolution]int{
+	Resolution720p:  720,
+	Resolution1080p: 1080,
+	Resolution4K:    2160,
 }
 
 // NewVideoJob creates a new video generation job
@@ -69,13 +151,26 @@ func NewVideoJob(userID, templateID uuid.UUID, prompt string, params VideoParams
 }
 
 // StartProcessing marks the job as being processed
-func (j *VideoJob) StartProcessing(provider AIProvider) {
+func (j *VideoJob) StartProcessing() {
 	j.Status = JobStatusProcessing
-	j.Provider = provider
 	now := time.Now()
 	j.StartedAt = &now
 }
 
+// Defer returns a job to pending so it can be retried later (e.g. when providers are throttled)
+func (j *VideoJob) Defer(reason string) {
+	j.Status = JobStatusPending
+	j.Progress = 0
+	j.StartedAt = nil
+	j.ProviderReason = &reason
+}
+
+// AssignProvider records the selected provider and why it was chosen
+func (j *VideoJob) AssignProvider(provider AIProvider, reason string) {
+	j.Provider = provider
+	j.ProviderReason = &reason
+}
+
 // UpdateProgress updates the job progress
 func (j *VideoJob) UpdateProgress(progress int, status JobStatus) {
 	j.Progress = progress
@@ -87,6 +182,37 @@ func (j *VideoJob) SetProviderJobID(providerJobID string) {
 	j.ProviderJobID = &providerJobID
 }
 
+// SetProviderKeyID records which provider API key submitted the job
+func (j *VideoJob) SetProviderKeyID(keyID string) {
+	if keyID == "" {
+		return
+	}
+	j.ProviderKeyID = &keyID
+}
+
+// AssignExperiment records the experiment and arm that routed the job
+func (j *VideoJob) AssignExperiment(experiment, arm string) {
+	j.Experiment = &experiment
+	j.ExperimentArm = &arm
+}
+
+// Rate records the user's 1-5 rating of the generated video
+func (j *VideoJob) Rate(rating int) error {
+	if rating < 1 || rating > 5 {
+		return ErrInvalidInput
+	}
+	j.UserRating = &rating
+	return nil
+}
+
+// SetSeed records the seed used for generation
+func (j *VideoJob) SetSeed(seed *int64) {
+	if seed == nil {
+		return
+	}
+	j.Seed = seed
+}
+
 // Complete marks the job as completed
 func (j *VideoJob) Complete(videoURL, thumbnailURL string, duration int) {
 	j.Status = JobStatusCompleted
@@ -98,6 +224,142 @@ func (j *VideoJob) Complete(videoURL, thumbnailURL string, duration int) {
 	j.CompletedAt = &now
 }
 
+// CompleteWithResult marks the job as completed once its media has been stored. Anything storing
+// and probing the video did not set is taken from the provider result, then from the requested params.
+func (j *VideoJob) CompleteWithResult(result *VideoResult) {
+	videoURL := result.VideoURL
+	if j.VideoURL != nil {
+		videoURL = *j.VideoURL
+	} else {
+		j.VideoExpiresAt = result.ExpiresAt
+	}
+	thumbnailURL := result.ThumbnailURL
+	if j.ThumbnailURL != nil {
+		thumbnailURL = *j.ThumbnailURL
+	}
+	duration := j.DurationSeconds
+	if duration == 0 {
+		duration = result.Duration
+	}
+	if duration == 0 {
+		duration = j.Params.Duration
+	}
+	j.Complete(videoURL, thumbnailURL, duration)
+
+	if j.OutputResolution == nil {
+		resolution := result.Resolution
+		if resolution == "" {
+			resolution = j.Params.Resolution
+		}
+		j.OutputResolution = &resolution
+	}
+}
+
+// StoreVideo points the job at the durable copy of its video stored under key and served from videoURL
+func (j *VideoJob) StoreVideo(key, videoURL string) {
+	j.VideoStorageKey = &key
+	j.VideoURL = &videoURL
+	j.VideoExpiresAt = nil
+}
+
+// StoreWatermarked points the job at a watermarked video stored under key and served from videoURL,
+// keeping the key of the clean original so the watermark can be removed later
+func (j *VideoJob) StoreWatermarked(key, videoURL, sourceKey string) {
+	j.StoreVideo(key, videoURL)
+	j.SourceKey = &sourceKey
+	j.Watermarked = true
+}
+
+// RemoveWatermark points the job at its clean original, served from videoURL, and returns the key
+// of the watermarked video it replaces. HLS renditions are cut from the watermarked video, so the
+// caller replaces them with StoreHLS or drops them with ClearHLS.
+func (j *VideoJob) RemoveWatermark(videoURL string) string {
+	watermarkedKey := *j.VideoStorageKey
+	j.StoreVideo(*j.SourceKey, videoURL)
+	j.SourceKey = nil
+	j.Watermarked = false
+	return watermarkedKey
+}
+
+// StoreThumbnail points the job at a thumbnail stored under key and served from thumbnailURL
+func (j *VideoJob) StoreThumbnail(key, thumbnailURL string) {
+	j.ThumbnailKey = &key
+	j.ThumbnailURL = &thumbnailURL
+}
+
+// StorePreview points the job at an animated preview stored under key and served from previewURL
+func (j *VideoJob) StorePreview(key, previewURL string) {
+	j.PreviewKey = &key
+	j.PreviewURL = &previewURL
+}
+
+// StoreHLS points the job at an HLS master playlist stored under key and served from playlistURL
+func (j *VideoJob) StoreHLS(key, playlistURL string) {
+	j.HLSStorageKey = &key
+	j.HLSPlaylistURL = &playlistURL
+}
+
+// ClearHLS drops the job's HLS renditions, leaving the MP4 to be played directly
+func (j *VideoJob) ClearHLS() {
+	j.HLSStorageKey = nil
+	j.HLSPlaylistURL = nil
+}
+
+// RecordMetadata stores what probing the job's video measured and flags where it differs from the
+// requested params. The measured duration and resolution replace what the provider reported.
+func (j *VideoJob) RecordMetadata(metadata VideoMetadata) {
+	metadata.Mismatches = metadata.compare(j.Params)
+	j.Metadata = &metadata
+
+	if metadata.DurationSeconds > 0 {
+		j.DurationSeconds = int(math.Round(metadata.DurationSeconds))
+	}
+	if resolution := metadata.Resolution(); resolution != "" {
+		j.OutputResolution = &resolution
+	}
+}
+
+// SignMediaURLs replaces the URLs of media in our storage with signed, expiring links.
+// HLS playlists are signed with signDir so the renditions and segments they reference are covered.
+func (j *VideoJob) SignMediaURLs(sign, signDir func(key string) string) {
+	if j.VideoStorageKey != nil {
+		videoURL := sign(*j.VideoStorageKey)
+		j.VideoURL = &videoURL
+	}
+	if j.ThumbnailKey != nil {
+		thumbnailURL := sign(*j.ThumbnailKey)
+		j.ThumbnailURL = &thumbnailURL
+	}
+	if j.PreviewKey != nil {
+		previewURL := sign(*j.PreviewKey)
+		j.PreviewURL = &previewURL
+	}
+	if j.HLSStorageKey != nil {
+		playlistURL := signDir(*j.HLSStorageKey)
+		j.HLSPlaylistURL = &playlistURL
+	}
+}
+
+// Share sets the token that grants access to the job's video stream through a share link
+func (j *VideoJob) Share(token string) {
+	j.ShareToken = &token
+}
+
+// Unshare revokes the job's share link
+func (j *VideoJob) Unshare() {
+	j.ShareToken = nil
+}
+
+// CanBeStreamedBy reports whether the job's video may be streamed by userID (nil when signed out)
+// holding shareToken (empty when none was given)
+func (j *VideoJob) CanBeStreamedBy(userID *uuid.UUID, shareToken string) bool {
+	if userID != nil && *userID == j.UserID {
+		return true
+	}
+	return shareToken != "" && j.ShareToken != nil &&
+		subtle.ConstantTimeCompare([]byte(shareToken), []byte(*j.ShareToken)) == 1
+}
+
 // Fail marks the job as failed
 func (j *VideoJob) Fail(errorMessage string) {
 	j.Status = JobStatusFailed
@@ -128,9 +390,20 @@ func (j *VideoJob) CanBeCancelled() bool {
 // GenerationResult represents the result from an AI provider
 type GenerationResult struct {
 	ProviderJobID string
+	ProviderKeyID string // ID of the API key that submitted the job (empty if the provider has no keys)
 	VideoURL      string
 	ThumbnailURL  string
 	Duration      int
+	Seed          *int64 // Seed the provider generated with (nil if the provider does not use seeds)
+}
+
+// VideoResult represents the final output of a completed generation
+type VideoResult struct {
+	VideoURL     string
+	ThumbnailURL string
+	Duration     int             // Actual duration in seconds (0 if the provider does not report it)
+	Resolution   VideoResolution // Actual resolution (empty if the provider does not report it)
+	ExpiresAt    *time.Time      // When the provider-hosted URLs stop working (nil if they do not expire)
 }
 
 // Progress represents generation progress from an AI provider
@@ -139,3 +412,59 @@ type Progress struct {
 	Stage   string
 	Message string
 }
+
+// Resolution returns the output resolution matching the video's shorter side, or "" if none does
+func (m *VideoMetadata) Resolution() VideoResolution {
+	shortSide := min(m.Width, m.Height)
+	for resolution, height := range resolutionHeights {
+		if shortSide == height {
+			return resolution
+		}
+	}
+	return ""
+}
+
+// compare lists where the measured video differs from params. Properties that were not requested
+// or could not be measured are not compared.
+func (m *VideoMetadata) compare(params VideoParams) []MetadataMismatch {
+	var mismatches []MetadataMismatch
+	dimensions := fmt.Sprintf("%dx%d", m.Width, m.Height)
+	hasDimensions := m.Width > 0 && m.Height > 0
+
+	if params.Duration > 0 && m.DurationSeconds > 0 && math.Abs(m.DurationSeconds-float64(params.Duration)) > durationTolerance {
+		mismatches = append(mismatches, MetadataMismatch{
+			Field:     "duration",
+			Requested: fmt.Sprintf("%ds", params.Duration),
+			Measured:  fmt.Sprintf("%.1fs", m.DurationSeconds),
+		})
+	}
+
+	if height, ok := resolutionHeights[params.Resolution]; ok && hasDimensions && min(m.Width, m.Height) != height {
+		mismatches = append(mismatches, MetadataMismatch{
+			Field:     "resolution",
+			Requested: string(params.Resolution),
+			Measured:  dimensions,
+		})
+	}
+
+	if ratio, ok := params.AspectRatio.ratio(); ok && hasDimensions {
+		measured := float64(m.Width) / float64(m.Height)
+		if math.Abs(measured-ratio)/ratio > aspectRatioTolerance {
+			mismatches = append(mismatches, MetadataMismatch{
+				Field:     "aspect_ratio",
+				Requested: string(params.AspectRatio),
+				Measured:  dimensions,
+			})
+		}
+	}
+
+	if params.FPS > 0 && m.FPS > 0 && math.Abs(m.FPS-float64(params.FPS)) > fpsTolerance {
+		mismatches = append(mismatches, MetadataMismatch{
+			Field:     "fps",
+			Requested: strconv.Itoa(params.FPS),
+			Measured:  strconv.FormatFloat(m.FPS, 'f', -1, 64),
+		})
+	}
+
+	return mismatches
+}
@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRecordMetadataMismatches(t *testing.T) {
+	params := VideoParams{Duration: 5, Resolution: Resolution1080p, AspectRatio: AspectRatio9x16, FPS: 30}
+
+	tests := []struct {
+		name     string
+		metadata VideoMetadata
+		want     []string
+	}{
+		{"matches", VideoMetadata{Width: 1080, Height: 1920, FPS: 29.97, DurationSeconds: 5.04}, nil},
+		{"duration within tolerance", VideoMetadata{Width: 1080, Height: 1920, FPS: 30, DurationSeconds: 6}, nil},
+		{"too short", VideoMetadata{Width: 1080, Height: 1920, FPS: 30, DurationSeconds: 3.5}, []string{"duration"}},
+		{"lower resolution", VideoMetadata{Width: 720, Height: 1280, FPS: 30, DurationSeconds: 5}, []string{"resolution"}},
+		{"landscape", VideoMetadata{Width: 1920, Height: 1080, FPS: 30, DurationSeconds: 5}, []string{"aspect_ratio"}},
+		{"frame rate", VideoMetadata{Width: 1080, Height: 1920, FPS: 24, DurationSeconds: 5}, []string{"fps"}},
+		// Fields the probe could not measure are not reported as mismatches
+		{"unmeasured", VideoMetadata{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := NewVideoJob(uuid.New(), uuid.New(), "prompt", params, 1)
+			job.RecordMetadata(tt.metadata)
+
+			var fields []string
+			for _, mismatch := range job.Metadata.Mismatches {
+				fields = append(fields, mismatch.Field)
+			}
+			if !slices.Equal(fields, tt.want) {
+				t.Errorf("mismatches = %v, want %v", fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordMetadataResolution(t *testing.T) {
+	tests := []struct {
+		width, height int
+		want          VideoResolution
+	}{
+		{1280, 720, Resolution720p},
+		{1080, 1920, Resolution1080p},
+		{3840, 2160, Resolution4K},
+		{960, 540, ""},
+	}
+
+	for _, tt := range tests {
+		job := NewVideoJob(uuid.New(), uuid.New(), "prompt", VideoParams{}, 1)
+		job.RecordMetadata(VideoMetadata{Width: tt.width, Height: tt.height, DurationSeconds: 4.6})
+
+		var got VideoResolution
+		if job.OutputResolution != nil {
+			got = *job.OutputResolution
+		}
+		if got != tt.want {
+			t.Errorf("%dx%d: resolution = %q, want %q", tt.width, tt.height, got, tt.want)
+		}
+		if job.DurationSeconds != 5 {
+			t.Errorf("%dx%d: duration = %d, want 5", tt.width, tt.height, job.DurationSeconds)
+		}
+	}
+}
+
+func TestCompleteWithResultKeepsStoredMedia(t *testing.T) {
+	expires := time.Now().Add(time.Hour)
+	result := &VideoResult{
+		VideoURL:     "https://provider.example.com/video.mp4?Expires=1",
+		ThumbnailURL: "https://provider.example.com/thumb.jpg",
+		Duration:     6,
+		Resolution:   Resolution1080p,
+		ExpiresAt:    &expires,
+	}
+
+	stored := NewVideoJob(uuid.New(), uuid.New(), "prompt", VideoParams{Duration: 5, Resolution: Resolution720p}, 1)
+	stored.StoreVideo("videos/u/j.mp4", "https://cdn.arabella.app/videos/u/j.mp4")
+	stored.RecordMetadata(VideoMetadata{Width: 1280, Height: 720, DurationSeconds: 5.04})
+	stored.CompleteWithResult(result)
+
+	if stored.Status != JobStatusCompleted || stored.CompletedAt == nil {
+		t.Fatalf("status = %s, want completed", stored.Status)
+	}
+	if *stored.VideoURL != "https://cdn.arabella.app/videos/u/j.mp4" || stored.VideoExpiresAt != nil {
+		t.Errorf("video URL = %q (expires %v), want the stored copy", *stored.VideoURL, stored.VideoExpiresAt)
+	}
+	if *stored.ThumbnailURL != result.ThumbnailURL {
+		t.Errorf("thumbnail URL = %q, want the provider's", *stored.ThumbnailURL)
+	}
+	if stored.DurationSeconds != 5 || *stored.OutputResolution != Resolution720p {
+		t.Errorf("duration %d, resolution %s; want the measured 5s and 720p", stored.DurationSeconds, *stored.OutputResolution)
+	}
+
+	// Without stored media the provider's values are used
+	reported := NewVideoJob(uuid.New(), uuid.New(), "prompt", VideoParams{Duration: 5, Resolution: Resolution720p}, 1)
+	reported.CompleteWithResult(result)
+	if *reported.VideoURL != result.VideoURL || reported.VideoExpiresAt != &expires {
+		t.Errorf("video URL = %q, want the provider's", *reported.VideoURL)
+	}
+	if reported.DurationSeconds != 6 || *reported.OutputResolution != Resolution1080p {
+		t.Errorf("duration %d, resolution %s; want the reported 6s and 1080p", reported.DurationSeconds, *reported.OutputResolution)
+	}
+}
@@ -50,5 +50,8 @@ type TemplateRepository interface {
 
 	// GetCategories retrieves all unique categories
 	GetCategories(ctx context.Context) ([]entity.TemplateCategory, error)
+
+	// ListMediaReferences returns the media URLs and default parameters of every template, active or not
+	ListMediaReferences(ctx context.Context) ([]string, error)
 }
 
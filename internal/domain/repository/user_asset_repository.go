@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// UserAssetRepository defines the interface for user asset data access
+type UserAssetRepository interface {
+	// Create creates a new asset record
+	Create(ctx context.Context, asset *entity.UserAsset) error
+
+	// GetByID retrieves an asset by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.UserAsset, error)
+
+	// Delete deletes an asset record
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// ListStorageKeys returns the storage key of every asset
+	ListStorageKeys(ctx context.Context) ([]string, error)
+}
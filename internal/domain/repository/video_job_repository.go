@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/arabella/ai-studio-backend/internal/domain/entity"
 	"github.com/google/uuid"
@@ -14,6 +15,21 @@ type VideoJobFilter struct {
 	Provider *entity.AIProvider
 }
 
+// ExperimentArmStats summarises the outcomes of jobs routed to one experiment arm
+type ExperimentArmStats struct {
+	Experiment        string            `json:"experiment" example:"wan_2_6_rollout"`
+	Arm               string            `json:"arm" example:"wan_2_6"`
+	Provider          entity.AIProvider `json:"provider" example:"wan_ai"`
+	TotalJobs         int64             `json:"total_jobs" example:"120"`
+	CompletedJobs     int64             `json:"completed_jobs" example:"110"`
+	FailedJobs        int64             `json:"failed_jobs" example:"8"`
+	CompletionRate    float64           `json:"completion_rate" example:"0.9167"`
+	AvgLatencySeconds float64           `json:"avg_latency_seconds" example:"184.5"` // Creation to completion, completed jobs only
+	AvgRating         float64           `json:"avg_rating" example:"4.2"`
+	RatingCount       int64             `json:"rating_count" example:"35"`
+	MismatchedJobs    int64             `json:"mismatched_jobs" example:"6"` // Videos that differ from the requested params
+}
+
 // VideoJobRepository defines the interface for video job data access
 type VideoJobRepository interface {
 	// Create creates a new video job
@@ -25,6 +41,21 @@ type VideoJobRepository interface {
 	// Update updates an existing video job
 	Update(ctx context.Context, job *entity.VideoJob) error
 
+	// RequestWatermarkRemoval queues a watermark removal for the background worker. It reports false,
+	// changing nothing, if the job has no watermark or a removal is already queued or running.
+	RequestWatermarkRemoval(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// ClaimWatermarkRemoval marks the oldest queued watermark removal as running and returns its job,
+	// or nil if none is queued. Removals left running for longer than staleAfter are claimed again.
+	ClaimWatermarkRemoval(ctx context.Context, staleAfter time.Duration) (*entity.VideoJob, error)
+
+	// CompleteWatermarkRemoval stores the job's unwatermarked video and HLS renditions if its removal is
+	// still running, reporting whether it was
+	CompleteWatermarkRemoval(ctx context.Context, job *entity.VideoJob) (bool, error)
+
+	// FailWatermarkRemoval marks a running watermark removal as failed so it can be requested again
+	FailWatermarkRemoval(ctx context.Context, id uuid.UUID) error
+
 	// UpdateStatus updates the status and progress of a job
 	UpdateStatus(ctx context.Context, id uuid.UUID, status entity.JobStatus, progress int) error
 
@@ -51,5 +82,11 @@ type VideoJobRepository interface {
 
 	// CountByStatus returns the count of jobs by status
 	CountByStatus(ctx context.Context, status entity.JobStatus) (int64, error)
+
+	// GetExperimentStats returns per-arm outcomes, optionally for a single experiment
+	GetExperimentStats(ctx context.Context, experiment *string) ([]*ExperimentArmStats, error)
+
+	// ListStorageKeys returns the storage key of every stored video, source, thumbnail, preview and HLS playlist
+	ListStorageKeys(ctx context.Context) ([]string, error)
 }
 
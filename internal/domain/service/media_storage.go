@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// MediaStorage durably stores generated media so results outlive provider-hosted URLs
+type MediaStorage interface {
+	// Put stores size bytes read from body under key, replacing any existing object
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+
+	// Open opens the object stored under key for reading from any offset, so it can serve range requests
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, MediaObjectInfo, error)
+
+	// Delete removes the object stored under key; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+
+	// URL returns the public URL the object under key is served from
+	URL(key string) string
+
+	// Walk calls fn for every object whose key starts with prefix, stopping at the first error fn returns
+	Walk(ctx context.Context, prefix string, fn func(key string, info MediaObjectInfo) error) error
+}
+
+// MediaObjectInfo describes a stored media object
+type MediaObjectInfo struct {
+	Size        int64
+	ModTime     time.Time
+	ContentType string // Empty when the backend does not record one
+	ETag        string // Quoted entity tag identifying this version of the object
+}
@@ -2,40 +2,63 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/arabella/ai-studio-backend/internal/domain/entity"
 )
 
 // GenerationRequest represents a video generation request to an AI provider
 type GenerationRequest struct {
-	JobID        string
-	Prompt       string
-	Params       entity.VideoParams
-	TemplateID   string
-	BasePrompt   string
-	ThumbnailURL string // Template thumbnail URL for image-to-video
-	UserTier     entity.UserTier
+	JobID         string
+	Prompt        string
+	Params        entity.VideoParams
+	TemplateID    string
+	BasePrompt    string
+	ThumbnailURL  string // Template thumbnail URL for image-to-video
+	StartImageURL string // Signed URL of the user's start image; takes precedence over ThumbnailURL
+	AudioURL      string // Signed URL of the user's soundtrack to synchronise the video to
+	TemplateTags  []string
+	UserTier      entity.UserTier
+	Model         string // Provider-specific model override (e.g. from an experiment arm)
+	MockScenario  string // Scripts the mock provider; ignored by real providers
 }
 
 // ProviderCapabilities describes what a provider can do
 type ProviderCapabilities struct {
-	Name            string
-	MaxDuration     int // Maximum video duration in seconds
-	MaxResolution   entity.VideoResolution
-	SupportedRatios []entity.AspectRatio
-	EstimatedTime   int    // Estimated time per second of video
-	QualityTier     string // budget, standard, premium
-	SupportsStyles  bool
-	CostPerSecond   float64
+	Name               string
+	MaxDuration        int   // Maximum video duration in seconds
+	SupportedDurations []int // Discrete durations in seconds (empty means any up to MaxDuration)
+	MaxResolution      entity.VideoResolution
+	SupportedRatios    []entity.AspectRatio
+	SupportedFPS       []int  // Supported frame rates (empty means any)
+	EstimatedTime      int    // Estimated time per second of video
+	QualityTier        string // budget, standard, premium
+	SupportsStyles     bool
+	CostPerSecond      float64
+
+	// Input and advanced features (see entity.AdvancedParams)
+	SupportsImageToVideo bool // Can animate a supplied start image
+	SupportsSeed         bool
+	SupportsAudio        bool // Generated soundtrack and custom audio
+	SupportsShotType     bool // Single or multi-shot
+	MaxReferenceImages   int  // Zero means reference images are not supported
 }
 
 // ProviderHealth represents the health status of a provider
 type ProviderHealth struct {
-	IsHealthy    bool
-	QueueDepth   int
-	ResponseTime int64 // in milliseconds
-	ErrorRate    float64
-	LastChecked  int64 // unix timestamp
+	IsHealthy    bool    `json:"is_healthy"`
+	QueueDepth   int     `json:"queue_depth"`
+	ResponseTime int64   `json:"response_time_ms"` // in milliseconds
+	ErrorRate    float64 `json:"error_rate"`
+	LastChecked  int64   `json:"last_checked"` // unix timestamp
+}
+
+// ProviderHealthReport contains the current and historical health of a provider
+type ProviderHealthReport struct {
+	Provider entity.AIProvider `json:"provider"`
+	Current  *ProviderHealth   `json:"current,omitempty"`
+	History  []ProviderHealth  `json:"history"`
 }
 
 // VideoProvider defines the contract for AI video generation providers
@@ -49,6 +72,9 @@ type VideoProvider interface {
 	// GetProgress retrieves generation progress
 	GetProgress(ctx context.Context, providerJobID string) (*entity.Progress, error)
 
+	// GetResult retrieves the result of a completed generation
+	GetResult(ctx context.Context, providerJobID string) (*entity.VideoResult, error)
+
 	// CancelGeneration cancels an ongoing generation
 	CancelGeneration(ctx context.Context, providerJobID string) error
 
@@ -62,20 +88,130 @@ type VideoProvider interface {
 // ProviderSelector defines the interface for selecting the best provider
 type ProviderSelector interface {
 	// SelectProvider selects the best provider based on requirements
-	SelectProvider(ctx context.Context, req ProviderSelectionRequest) (VideoProvider, error)
+	SelectProvider(ctx context.Context, req ProviderSelectionRequest) (*ProviderSelection, error)
+
+	// IsRegistered reports whether a provider with the given name is registered
+	IsRegistered(name entity.AIProvider) bool
+
+	// NormalizeParams checks params against provider capabilities and returns what would change
+	NormalizeParams(ctx context.Context, req ProviderSelectionRequest, params entity.VideoParams) (*ParamNormalization, error)
 
 	// GetAvailableProviders returns all available providers
 	GetAvailableProviders(ctx context.Context) ([]VideoProvider, error)
 
 	// RefreshHealth refreshes health status for all providers
 	RefreshHealth(ctx context.Context) error
+
+	// GetHealthReport returns cached current and historical health for all providers
+	GetHealthReport(ctx context.Context) ([]ProviderHealthReport, error)
+
+	// RecordSpend records the estimated cost of a submitted generation against the provider budget
+	RecordSpend(ctx context.Context, provider VideoProvider, req ProviderSelectionRequest) error
+
+	// GetSpendReport returns current spend and caps for all providers
+	GetSpendReport(ctx context.Context) ([]ProviderSpendReport, error)
+}
+
+// SpendTracker tracks provider spend shared across instances
+type SpendTracker interface {
+	// GetSpend returns the provider's spend for the current day and month
+	GetSpend(ctx context.Context, provider entity.AIProvider) (daily, monthly float64, err error)
+
+	// AddSpend adds an amount to the provider's daily and monthly spend
+	AddSpend(ctx context.Context, provider entity.AIProvider, amount float64) error
+}
+
+// ProviderOperation identifies a class of outbound provider API call with its own rate limit
+type ProviderOperation string
+
+const (
+	ProviderOperationSubmit ProviderOperation = "submit"
+	ProviderOperationStatus ProviderOperation = "status"
+)
+
+// ProviderRateLimiter throttles outbound provider API calls and tracks daily quotas across instances
+type ProviderRateLimiter interface {
+	// Reserve takes a token for an operation, returning how long to wait when none is available (zero means allowed)
+	Reserve(ctx context.Context, provider entity.AIProvider, op ProviderOperation) (time.Duration, error)
+
+	// QuotaExhausted reports whether the provider's daily submission quota is used up
+	QuotaExhausted(ctx context.Context, provider entity.AIProvider) (bool, error)
+
+	// ConsumeQuota records a submission against the provider's daily quota
+	ConsumeQuota(ctx context.Context, provider entity.AIProvider) error
+}
+
+// RateLimitError reports that a provider call was throttled and when it can be retried
+type RateLimitError struct {
+	Provider   entity.AIProvider
+	Operation  ProviderOperation
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s %s calls throttled, retry after %s", e.Provider, e.Operation, e.RetryAfter)
+}
+
+// Unwrap returns the underlying domain error
+func (e *RateLimitError) Unwrap() error {
+	return entity.ErrProviderRateLimited
+}
+
+// providerKeyIDKey is the context key carrying the provider API key ID a job was submitted with
+type providerKeyIDKey struct{}
+
+// WithProviderKeyID returns a context that directs provider calls to the API key with the given ID,
+// so status calls for a job use the key that submitted it
+func WithProviderKeyID(ctx context.Context, keyID string) context.Context {
+	return context.WithValue(ctx, providerKeyIDKey{}, keyID)
+}
+
+// ProviderKeyIDFromContext returns the provider API key ID carried by the context, if any
+func ProviderKeyIDFromContext(ctx context.Context) (string, bool) {
+	keyID, ok := ctx.Value(providerKeyIDKey{}).(string)
+	return keyID, ok && keyID != ""
+}
+
+// ProviderSpendReport contains spend and budget caps for a provider
+type ProviderSpendReport struct {
+	Provider     entity.AIProvider `json:"provider"`
+	DailySpend   float64           `json:"daily_spend"`
+	DailyLimit   float64           `json:"daily_limit"`
+	MonthlySpend float64           `json:"monthly_spend"`
+	MonthlyLimit float64           `json:"monthly_limit"`
+	OverBudget   bool              `json:"over_budget"`
 }
 
 // ProviderSelectionRequest contains criteria for provider selection
 type ProviderSelectionRequest struct {
+	JobID              string // Stable key for experiment arm assignment
+	TemplateID         string
 	UserTier           entity.UserTier
-	PreferredProvider  *entity.AIProvider
+	RequestedProvider  *entity.AIProvider // Explicit override from the request (entitled tiers only)
+	TemplateProvider   *entity.AIProvider // Preference stored on the template
 	RequiredResolution entity.VideoResolution
 	RequiredDuration   int
+	RequiredFPS        int
 	AspectRatio        entity.AspectRatio
+	Advanced           *entity.AdvancedParams // Advanced features the provider must support
+	RequiresImageInput bool                   // The job animates a user start image
+	RequiresAudioInput bool                   // The job is synchronised to a user soundtrack
+}
+
+// ParamNormalization is the outcome of checking requested params against provider capabilities
+type ParamNormalization struct {
+	Params      entity.VideoParams       // Params every adjustment has been applied to
+	Provider    entity.AIProvider        // Provider the params were fitted to (empty when no change was needed)
+	Adjustments []entity.ParamAdjustment // Changes made to the requested params
+	Explanation string                   // Human-readable summary of the adjustments
+}
+
+// ProviderSelection is the outcome of provider selection
+type ProviderSelection struct {
+	Provider   VideoProvider
+	Reason     string // Human-readable explanation of why the provider was chosen
+	Experiment string // Experiment that chose the provider, if any
+	Arm        string // Experiment arm the job was assigned to
+	Model      string // Model override from the experiment arm
 }
@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	rateLimitKeyPrefix = "arabella:provider:ratelimit:"
+	quotaKeyPrefix     = "arabella:provider:quota:"
+)
+
+// tokenBucketScript atomically refills and takes from a token bucket using the Redis clock,
+// so every instance shares the same view of the bucket.
+// KEYS[1] bucket key; ARGV[1] refill rate per second; ARGV[2] burst size.
+// Returns {allowed, wait in milliseconds}.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+return {allowed, wait}
+`)
+
+// RateLimit configures a token bucket (Rate tokens per second, up to Burst)
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// ProviderRateLimiter implements service.ProviderRateLimiter with Redis token buckets and daily counters
+type ProviderRateLimiter struct {
+	client *redis.Client
+	limits map[entity.AIProvider]map[service.ProviderOperation]RateLimit
+	quotas map[entity.AIProvider]int
+}
+
+// NewProviderRateLimiter creates a new ProviderRateLimiter.
+// Operations without a configured limit and providers without a quota are unlimited.
+func NewProviderRateLimiter(
+	client *redis.Client,
+	limits map[entity.AIProvider]map[service.ProviderOperation]RateLimit,
+	quotas map[entity.AIProvider]int,
+) *ProviderRateLimiter {
+	return &ProviderRateLimiter{
+		client: client,
+		limits: limits,
+		quotas: quotas,
+	}
+}
+
+// Reserve takes a token for an operation, returning how long to wait when none is available
+func (l *ProviderRateLimiter) Reserve(ctx context.Context, provider entity.AIProvider, op service.ProviderOperation) (time.Duration, error) {
+	limit, ok := l.limits[provider][op]
+	if !ok || limit.Rate <= 0 {
+		return 0, nil
+	}
+	burst := limit.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	key := fmt.Sprintf("%s%s:%s", rateLimitKeyPrefix, provider, op)
+	result, err := tokenBucketScript.Run(ctx, l.client, []string{key},
+		strconv.FormatFloat(limit.Rate, 'f', -1, 64), burst,
+	).Int64Slice()
+	if err != nil {
+		return 0, err
+	}
+
+	if result[0] == 1 {
+		return 0, nil
+	}
+	return time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// QuotaExhausted reports whether the provider's daily submission quota is used up
+func (l *ProviderRateLimiter) QuotaExhausted(ctx context.Context, provider entity.AIProvider) (bool, error) {
+	quota, ok := l.quotas[provider]
+	if !ok || quota <= 0 {
+		return false, nil
+	}
+
+	used, err := l.client.Get(ctx, dailyQuotaKey(provider, time.Now().UTC())).Int()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return used >= quota, nil
+}
+
+// ConsumeQuota records a submission against the provider's daily quota
+func (l *ProviderRateLimiter) ConsumeQuota(ctx context.Context, provider entity.AIProvider) error {
+	if quota, ok := l.quotas[provider]; !ok || quota <= 0 {
+		return nil
+	}
+
+	key := dailyQuotaKey(provider, time.Now().UTC())
+
+	pipe := l.client.Pipeline()
+	pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, 48*time.Hour)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// dailyQuotaKey returns the Redis key for a provider's submissions on a given day
+func dailyQuotaKey(provider entity.AIProvider, t time.Time) string {
+	return fmt.Sprintf("%s%s:%s", quotaKeyPrefix, provider, t.Format("20060102"))
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/redis/go-redis/v9"
+)
+
+const spendKeyPrefix = "arabella:provider:spend:"
+
+// SpendTracker tracks provider spend in Redis so caps are shared across instances
+type SpendTracker struct {
+	client *redis.Client
+}
+
+// NewSpendTracker creates a new SpendTracker
+func NewSpendTracker(client *redis.Client) *SpendTracker {
+	return &SpendTracker{client: client}
+}
+
+// GetSpend returns the provider's spend for the current day and month
+func (t *SpendTracker) GetSpend(ctx context.Context, provider entity.AIProvider) (float64, float64, error) {
+	now := time.Now().UTC()
+
+	values, err := t.client.MGet(ctx, dailySpendKey(provider, now), monthlySpendKey(provider, now)).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return parseSpend(values[0]), parseSpend(values[1]), nil
+}
+
+// AddSpend adds an amount to the provider's daily and monthly spend
+func (t *SpendTracker) AddSpend(ctx context.Context, provider entity.AIProvider, amount float64) error {
+	now := time.Now().UTC()
+	dailyKey := dailySpendKey(provider, now)
+	monthlyKey := monthlySpendKey(provider, now)
+
+	pipe := t.client.Pipeline()
+	pipe.IncrByFloat(ctx, dailyKey, amount)
+	pipe.Expire(ctx, dailyKey, 48*time.Hour)
+	pipe.IncrByFloat(ctx, monthlyKey, amount)
+	pipe.Expire(ctx, monthlyKey, 32*24*time.Hour)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// dailySpendKey returns the Redis key for a provider's spend on a given day
+func dailySpendKey(provider entity.AIProvider, t time.Time) string {
+	return fmt.Sprintf("%s%s:day:%s", spendKeyPrefix, provider, t.Format("20060102"))
+}
+
+// monthlySpendKey returns the Redis key for a provider's spend in a given month
+func monthlySpendKey(provider entity.AIProvider, t time.Time) string {
+	return fmt.Sprintf("%s%s:month:%s", spendKeyPrefix, provider, t.Format("200601"))
+}
+
+// parseSpend parses a spend value returned by MGET
+func parseSpend(value interface{}) float64 {
+	s, ok := value.(string)
+	if !ok {
+		return 0
+	}
+	spend, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return spend
+}
@@ -0,0 +1,204 @@
+package imageproxy
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheFilePattern matches the files the cache writes: {sha256 of the URL}{extension}
+var cacheFilePattern = regexp.MustCompile(`^([0-9a-f]{64})\.(jpg|png|gif|webp)$`)
+
+// cacheEntry is one cached image
+type cacheEntry struct {
+	key      string
+	name     string
+	size     int64
+	storedAt time.Time
+}
+
+// diskCache keeps images on disk, evicting the least recently used once the byte budget is
+// exceeded and treating entries older than the TTL as missing
+type diskCache struct {
+	dir      string
+	maxBytes int64
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // Front is most recently used
+	size    int64
+}
+
+// newDiskCache creates a cache in dir, indexing images already there from a previous run
+func newDiskCache(dir string, maxBytes int64, ttl time.Duration) (*diskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create image cache directory: %w", err)
+	}
+	c := &diskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image cache directory: %w", err)
+	}
+	var existing []*cacheEntry
+	for _, file := range files {
+		match := cacheFilePattern.FindStringSubmatch(file.Name())
+		if match == nil {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		existing = append(existing, &cacheEntry{key: match[1], name: file.Name(), size: info.Size(), storedAt: info.ModTime()})
+	}
+
+	// Without access times, the oldest files are treated as least recently used
+	sort.Slice(existing, func(i, j int) bool { return existing[i].storedAt.After(existing[j].storedAt) })
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, entry := range existing {
+		c.entries[entry.key] = c.lru.PushBack(entry)
+		c.size += entry.size
+	}
+	c.evict()
+
+	return c, nil
+}
+
+// get returns the fresh entry for key and marks it recently used
+func (c *diskCache) get(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Since(entry.storedAt) > c.ttl {
+		c.remove(element)
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return entry, true
+}
+
+// put writes data for key, replacing any previous entry, and evicts entries over the budget
+func (c *diskCache) put(key, ext string, data []byte) (*cacheEntry, error) {
+	entry := &cacheEntry{key: key, name: key + ext, size: int64(len(data)), storedAt: time.Now()}
+
+	// Write to a temporary file first so readers never see a partial image
+	tmp, err := os.CreateTemp(c.dir, ".download-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(c.dir, entry.name))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		previous := element.Value.(*cacheEntry)
+		c.lru.Remove(element)
+		delete(c.entries, key)
+		c.size -= previous.size
+		if previous.name != entry.name {
+			os.Remove(filepath.Join(c.dir, previous.name))
+		}
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	c.size += entry.size
+	c.evict()
+
+	return entry, nil
+}
+
+// path returns the file an entry is stored in
+func (c *diskCache) path(entry *cacheEntry) string {
+	return filepath.Join(c.dir, entry.name)
+}
+
+// files calls fn for every file in the cache directory whose name starts with prefix, including
+// files the index does not track, such as temporary files left by an interrupted write
+func (c *diskCache) files(prefix string, fn func(name string, info os.FileInfo) error) error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read image cache directory: %w", err)
+	}
+	for _, file := range files {
+		if !file.Type().IsRegular() || !strings.HasPrefix(file.Name(), prefix) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		if err := fn(file.Name(), info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// delete removes a file from the cache directory, dropping its entry if the index tracks it
+func (c *diskCache) delete(name string) error {
+	if name == "" || name == "." || name == ".." || name != filepath.Base(name) {
+		return fmt.Errorf("invalid cache file name %q", name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if match := cacheFilePattern.FindStringSubmatch(name); match != nil {
+		if element, ok := c.entries[match[1]]; ok && element.Value.(*cacheEntry).name == name {
+			c.remove(element)
+			return nil
+		}
+	}
+	if err := os.Remove(filepath.Join(c.dir, name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// evict removes least recently used entries until the cache fits its budget; the newest entry
+// is always kept. The caller must hold mu.
+func (c *diskCache) evict() {
+	for c.size > c.maxBytes && c.lru.Len() > 1 {
+		c.remove(c.lru.Back())
+	}
+}
+
+// remove deletes an entry and its file. The caller must hold mu.
+func (c *diskCache) remove(element *list.Element) {
+	entry := element.Value.(*cacheEntry)
+	c.lru.Remove(element)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+	os.Remove(filepath.Join(c.dir, entry.name))
+}
@@ -0,0 +1,106 @@
+package imageproxy
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+)
+
+// blockedNetworks are non-public ranges not covered by the net.IP classification methods
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "This" network
+	"100.64.0.0/10", // Carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // Benchmarking
+	"240.0.0.0/4",   // Reserved, including broadcast
+	"64:ff9b::/96",  // NAT64, which can reach private IPv4 addresses
+)
+
+// hostAllowlist matches hostnames against exact names, "*.domain" wildcards and "*" for any host
+type hostAllowlist []string
+
+// allows reports whether host is on the allowlist
+func (l hostAllowlist) allows(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, entry := range l {
+		entry = strings.ToLower(entry)
+		switch {
+		case entry == "*":
+			return true
+		case strings.HasPrefix(entry, "*."):
+			if strings.HasSuffix(host, entry[1:]) {
+				return true
+			}
+		case host == entry:
+			return true
+		}
+	}
+	return false
+}
+
+// checkURL validates the scheme, host and port of a URL the proxy is asked to fetch, including
+// every redirect. The address it resolves to is checked separately when connecting.
+func (p *Proxy) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%w: only http and https URLs can be proxied", entity.ErrProxyTargetNotAllowed)
+	}
+	if u.User != nil {
+		return fmt.Errorf("%w: URLs with credentials cannot be proxied", entity.ErrProxyTargetNotAllowed)
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		return fmt.Errorf("%w: port %s", entity.ErrProxyTargetNotAllowed, port)
+	}
+	if !p.allowedHosts.allows(u.Hostname()) {
+		return fmt.Errorf("%w: host %s is not on the allowlist", entity.ErrProxyTargetNotAllowed, u.Hostname())
+	}
+	return nil
+}
+
+// checkDialAddress refuses connections to non-public addresses. It runs after DNS resolution,
+// on the address actually dialled, so a hostname cannot be rebound to an internal address.
+func checkDialAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s is not a public address", entity.ErrProxyTargetNotAllowed, host)
+	}
+	return nil
+}
+
+// isPublicIP reports whether ip is a globally routable unicast address
+func isPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// mustParseCIDRs parses CIDR blocks, panicking on a malformed one
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
@@ -0,0 +1,259 @@
+package imageproxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+	"go.uber.org/zap"
+)
+
+const (
+	// fetchTimeout bounds downloading a single image, including redirects
+	fetchTimeout = 60 * time.Second
+
+	// fetchAttempts is how many times a failed download is tried; some hosts drop connections mid-transfer
+	fetchAttempts = 3
+
+	// maxRedirects is how many redirects are followed, each checked like the original URL
+	maxRedirects = 5
+
+	// userAgent mimics a browser, since some image hosts refuse other clients
+	userAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+)
+
+// imageTypes maps the content types detected from an image's bytes to cache file extensions.
+// SVG is deliberately absent: it can carry scripts.
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Config holds image proxy settings
+type Config struct {
+	AllowedHosts  []string // Hostnames that may be fetched: exact names, "*.domain" wildcards or "*" for any public host
+	HTTPOnlyHosts []string // Hosts always fetched over plain HTTP because their TLS setup is broken
+	MaxBytes      int64    // Largest image fetched
+	CacheDir      string
+	CacheMaxBytes int64         // Disk budget of the cache; least recently used images are evicted beyond it
+	CacheTTL      time.Duration // How long a cached image is served before it is fetched again
+}
+
+// Proxy fetches images from allowlisted public hosts and caches them on disk
+type Proxy struct {
+	allowedHosts  hostAllowlist
+	httpOnlyHosts hostAllowlist
+	maxBytes      int64
+	cache         *diskCache
+	client        *http.Client
+	logger        *zap.Logger
+}
+
+// New creates an image proxy
+func New(cfg Config, logger *zap.Logger) (*Proxy, error) {
+	if cfg.MaxBytes <= 0 {
+		return nil, fmt.Errorf("image proxy size limit must be positive")
+	}
+	cache, err := newDiskCache(cfg.CacheDir, cfg.CacheMaxBytes, cfg.CacheTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Proxy{
+		allowedHosts:  cfg.AllowedHosts,
+		httpOnlyHosts: cfg.HTTPOnlyHosts,
+		maxBytes:      cfg.MaxBytes,
+		cache:         cache,
+		logger:        logger,
+	}
+
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: checkDialAddress,
+	}
+	p.client = &http.Client{
+		Timeout: fetchTimeout,
+		Transport: &http.Transport{
+			Proxy:                 nil, // An outbound proxy would dial on our behalf, bypassing the address check
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: 30 * time.Second,
+			MaxIdleConns:          20,
+			IdleConnTimeout:       90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			p.downgrade(req.URL)
+			return p.checkURL(req.URL)
+		},
+	}
+
+	return p, nil
+}
+
+// Open returns the image at rawURL from the cache, fetching it first if it is missing or stale
+func (p *Proxy) Open(ctx context.Context, rawURL string) (io.ReadSeekCloser, service.MediaObjectInfo, error) {
+	entry, err := p.fetch(ctx, rawURL)
+	if err != nil {
+		return nil, service.MediaObjectInfo{}, err
+	}
+
+	file, err := os.Open(p.cache.path(entry))
+	if err != nil {
+		return nil, service.MediaObjectInfo{}, fmt.Errorf("%w: %v", entity.ErrProxyFetchFailed, err)
+	}
+	return file, service.MediaObjectInfo{
+		Size:        entry.size,
+		ModTime:     entry.storedAt,
+		ContentType: contentTypeOf(entry.name),
+		ETag:        fmt.Sprintf(`"%s-%x"`, entry.key[:16], entry.storedAt.Unix()),
+	}, nil
+}
+
+// CacheImage makes sure the image at rawURL is cached and returns its file name in the cache directory
+func (p *Proxy) CacheImage(ctx context.Context, rawURL string) (string, error) {
+	entry, err := p.fetch(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+	return entry.name, nil
+}
+
+// Walk calls fn for every file in the cache directory whose name starts with prefix, so stale
+// images and files the cache does not track can be garbage collected
+func (p *Proxy) Walk(ctx context.Context, prefix string, fn func(name string, info service.MediaObjectInfo) error) error {
+	return p.cache.files(prefix, func(name string, info os.FileInfo) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return fn(name, service.MediaObjectInfo{Size: info.Size(), ModTime: info.ModTime()})
+	})
+}
+
+// Delete removes a file from the cache directory; deleting a missing file is not an error
+func (p *Proxy) Delete(ctx context.Context, name string) error {
+	return p.cache.delete(name)
+}
+
+// fetch returns the cache entry for rawURL, downloading the image if needed
+func (p *Proxy) fetch(ctx context.Context, rawURL string) (*cacheEntry, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%w: invalid URL", entity.ErrProxyTargetNotAllowed)
+	}
+	p.downgrade(u)
+	if err := p.checkURL(u); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(u.String()))
+	key := hex.EncodeToString(sum[:])
+	if entry, ok := p.cache.get(key); ok {
+		return entry, nil
+	}
+
+	var data []byte
+	for attempt := 1; attempt <= fetchAttempts; attempt++ {
+		data, err = p.download(ctx, u.String())
+		if err == nil || errors.Is(err, entity.ErrProxyTargetNotAllowed) || errors.Is(err, entity.ErrProxyInvalidImage) {
+			break
+		}
+		p.logger.Debug("Image download failed",
+			zap.String("url", u.String()),
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+		)
+		if attempt < fetchAttempts {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Duration(attempt) * time.Second):
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ext := imageTypes[http.DetectContentType(data)]
+	entry, err := p.cache.put(key, ext, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrProxyFetchFailed, err)
+	}
+	return entry, nil
+}
+
+// download fetches an image into memory, enforcing the size limit and checking its bytes are an image
+func (p *Proxy) download(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrProxyTargetNotAllowed, err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "image/avif,image/webp,image/apng,image/*;q=0.8")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if errors.Is(err, entity.ErrProxyTargetNotAllowed) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", entity.ErrProxyFetchFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: source returned status %d", entity.ErrProxyFetchFailed, resp.StatusCode)
+	}
+	if resp.ContentLength > p.maxBytes {
+		return nil, fmt.Errorf("%w: image is larger than %d bytes", entity.ErrProxyInvalidImage, p.maxBytes)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrProxyFetchFailed, err)
+	}
+	if int64(len(data)) > p.maxBytes {
+		return nil, fmt.Errorf("%w: image is larger than %d bytes", entity.ErrProxyInvalidImage, p.maxBytes)
+	}
+	if resp.ContentLength > 0 && int64(len(data)) < resp.ContentLength {
+		return nil, fmt.Errorf("%w: connection closed after %d of %d bytes", entity.ErrProxyFetchFailed, len(data), resp.ContentLength)
+	}
+
+	// The source's Content-Type is not trusted; the bytes decide
+	if _, ok := imageTypes[http.DetectContentType(data)]; !ok {
+		return nil, fmt.Errorf("%w: source did not return a jpeg, png, gif or webp image", entity.ErrProxyInvalidImage)
+	}
+	return data, nil
+}
+
+// downgrade switches hosts configured as HTTP-only to plain HTTP
+func (p *Proxy) downgrade(u *url.URL) {
+	if u.Scheme == "https" && p.httpOnlyHosts.allows(u.Hostname()) {
+		u.Scheme = "http"
+	}
+}
+
+// contentTypeOf returns the content type of a cache file from its extension
+func contentTypeOf(name string) string {
+	for contentType, ext := range imageTypes {
+		if strings.HasSuffix(name, ext) {
+			return contentType
+		}
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,217 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+)
+
+// Soundtrack limits. DashScope accepts WAV or MP3 audio between 3 and 30 seconds, up to 15MB.
+const (
+	MinAudioSeconds = 3.0
+	MaxAudioSeconds = 30.0
+	MaxAudioBytes   = 15 * 1024 * 1024
+)
+
+// audioInfo describes an uploaded soundtrack
+type audioInfo struct {
+	ContentType     string
+	Extension       string
+	DurationSeconds float64
+}
+
+// AudioInspector validates uploaded soundtracks and measures their duration
+type AudioInspector struct{}
+
+// NewAudioInspector creates a new AudioInspector
+func NewAudioInspector() *AudioInspector {
+	return &AudioInspector{}
+}
+
+// InspectAudio detects the format of a WAV or MP3 file, measures its duration and checks
+// both against the soundtrack limits
+func (a *AudioInspector) InspectAudio(data []byte) (string, string, float64, error) {
+	if len(data) > MaxAudioBytes {
+		return "", "", 0, fmt.Errorf("%w: audio must be at most %dMB", entity.ErrInvalidAsset, MaxAudioBytes/(1024*1024))
+	}
+
+	var info audioInfo
+	var err error
+	switch {
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		info, err = inspectWAV(data)
+	default:
+		info, err = inspectMP3(data)
+	}
+	if err != nil {
+		return "", "", 0, fmt.Errorf("%w: %v (wav and mp3 are accepted)", entity.ErrInvalidAsset, err)
+	}
+
+	if info.DurationSeconds < MinAudioSeconds || info.DurationSeconds > MaxAudioSeconds {
+		return "", "", 0, fmt.Errorf("%w: audio is %.1f seconds but must be between %.0f and %.0f seconds",
+			entity.ErrInvalidAsset, info.DurationSeconds, MinAudioSeconds, MaxAudioSeconds)
+	}
+
+	return info.ContentType, info.Extension, info.DurationSeconds, nil
+}
+
+// inspectWAV reads the fmt and data chunks of a RIFF/WAVE file
+func inspectWAV(data []byte) (audioInfo, error) {
+	var byteRate uint32
+	var dataSize uint32
+	foundData := false
+
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := binary.LittleEndian.Uint32(data[offset+4 : offset+8])
+		body := offset + 8
+
+		switch id {
+		case "fmt ":
+			if size < 16 || body+16 > len(data) {
+				return audioInfo{}, fmt.Errorf("truncated WAV format chunk")
+			}
+			format := binary.LittleEndian.Uint16(data[body : body+2])
+			if format != 1 && format != 3 && format != 0xFFFE {
+				return audioInfo{}, fmt.Errorf("unsupported WAV encoding %d", format)
+			}
+			byteRate = binary.LittleEndian.Uint32(data[body+8 : body+12])
+		case "data":
+			dataSize = size
+			// Streams written before their length is known may report a larger size than the file holds
+			if int(dataSize) > len(data)-body {
+				dataSize = uint32(len(data) - body)
+			}
+			foundData = true
+		}
+
+		if foundData && byteRate > 0 {
+			break
+		}
+
+		// Chunks are padded to an even length
+		offset = body + int(size) + int(size%2)
+	}
+
+	if byteRate == 0 || !foundData {
+		return audioInfo{}, fmt.Errorf("WAV file has no audio data")
+	}
+
+	return audioInfo{
+		ContentType:     "audio/wav",
+		Extension:       ".wav",
+		DurationSeconds: float64(dataSize) / float64(byteRate),
+	}, nil
+}
+
+// MPEG audio lookup tables, indexed by [version][layer] where version is 0 for MPEG-1
+// and 1 for MPEG-2/2.5, and layer is 0 for Layer I through 2 for Layer III
+var (
+	mp3Bitrates = [2][3][16]int{
+		{
+			{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448, 0},
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384, 0},
+			{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+		},
+		{
+			{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256, 0},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+			{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+		},
+	}
+	mp3SamplesPerFrame = [2][3]int{
+		{384, 1152, 1152},
+		{384, 1152, 576},
+	}
+	mp3SampleRates = map[byte][3]int{
+		3: {44100, 48000, 32000}, // MPEG-1
+		2: {22050, 24000, 16000}, // MPEG-2
+		0: {11025, 12000, 8000},  // MPEG-2.5
+	}
+)
+
+// minMP3Frames is how many consecutive valid frames a file needs to be treated as MP3
+const minMP3Frames = 3
+
+// inspectMP3 walks the MPEG audio frames of an MP3 file, summing their durations
+func inspectMP3(data []byte) (audioInfo, error) {
+	offset := 0
+
+	// Skip an ID3v2 tag
+	if len(data) >= 10 && bytes.Equal(data[0:3], []byte("ID3")) {
+		size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+		offset = 10 + size
+		if data[5]&0x10 != 0 {
+			offset += 10 // Footer
+		}
+	}
+
+	frames := 0
+	duration := 0.0
+	for offset+4 <= len(data) {
+		length, seconds, ok := mp3Frame(data[offset : offset+4])
+		if !ok || offset+length > len(data) {
+			if frames == 0 {
+				// Tolerate junk before the first frame by resynchronising
+				offset++
+				continue
+			}
+			break
+		}
+		frames++
+		duration += seconds
+		offset += length
+	}
+
+	if frames < minMP3Frames {
+		return audioInfo{}, fmt.Errorf("unrecognised audio format")
+	}
+
+	return audioInfo{
+		ContentType:     "audio/mpeg",
+		Extension:       ".mp3",
+		DurationSeconds: duration,
+	}, nil
+}
+
+// mp3Frame decodes an MPEG audio frame header and returns the frame length in bytes and its duration
+func mp3Frame(header []byte) (int, float64, bool) {
+	if header[0] != 0xFF || header[1]&0xE0 != 0xE0 {
+		return 0, 0, false
+	}
+
+	versionBits := (header[1] >> 3) & 0x03
+	layerBits := (header[1] >> 1) & 0x03
+	bitrateIndex := header[2] >> 4
+	sampleRateIndex := (header[2] >> 2) & 0x03
+	padding := int((header[2] >> 1) & 0x01)
+
+	rates, ok := mp3SampleRates[versionBits]
+	if !ok || layerBits == 0 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		return 0, 0, false
+	}
+
+	version := 1
+	if versionBits == 3 {
+		version = 0
+	}
+	layer := 3 - int(layerBits) // Layer bits are 3 for Layer I down to 1 for Layer III
+
+	bitrate := mp3Bitrates[version][layer][bitrateIndex] * 1000
+	sampleRate := rates[sampleRateIndex]
+	samples := mp3SamplesPerFrame[version][layer]
+
+	var length int
+	if layer == 0 {
+		length = (12*bitrate/sampleRate + padding) * 4
+	} else {
+		length = samples/8*bitrate/sampleRate + padding
+	}
+	if length < 4 {
+		return 0, 0, false
+	}
+
+	return length, float64(samples) / float64(sampleRate), true
+}
@@ -0,0 +1,128 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+)
+
+const (
+	// ffmpegTimeout bounds a single ffmpeg run
+	ffmpegTimeout = 2 * time.Minute
+
+	// posterWidth is the width of extracted poster frames; height follows the aspect ratio
+	posterWidth = 640
+
+	// previewWidth, previewFPS and previewSeconds keep animated previews small enough for gallery grids
+	previewWidth   = 320
+	previewFPS     = 10
+	previewSeconds = 3
+)
+
+// FFmpeg extracts posters and previews from videos by running the ffmpeg binary, and measures
+// them with ffprobe
+type FFmpeg struct {
+	path      string
+	probePath string
+	watermark *WatermarkConfig
+}
+
+// NewFFmpeg creates an FFmpeg runner for the ffmpeg and ffprobe binaries at path and probePath
+// (bare names are looked up on PATH)
+func NewFFmpeg(path, probePath string) *FFmpeg {
+	if path == "" {
+		path = "ffmpeg"
+	}
+	if probePath == "" {
+		probePath = "ffprobe"
+	}
+	return &FFmpeg{path: path, probePath: probePath}
+}
+
+// Available reports whether the ffmpeg binary can be found
+func (f *FFmpeg) Available() bool {
+	_, err := exec.LookPath(f.path)
+	return err == nil
+}
+
+// ProbeAvailable reports whether the ffprobe binary can be found
+func (f *FFmpeg) ProbeAvailable() bool {
+	_, err := exec.LookPath(f.probePath)
+	return err == nil
+}
+
+// ExtractPoster returns a JPEG of the frame one second into the video, past any fade-in
+func (f *FFmpeg) ExtractPoster(ctx context.Context, videoPath string) ([]byte, error) {
+	return f.render(ctx, ".jpg",
+		"-ss", "1", "-i", videoPath,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:-2", posterWidth),
+		"-q:v", "3",
+	)
+}
+
+// ExtractPreview returns a short looping animated GIF from the start of the video
+func (f *FFmpeg) ExtractPreview(ctx context.Context, videoPath string) ([]byte, error) {
+	// Generating a palette from the clip itself keeps the GIF from banding
+	filter := fmt.Sprintf("fps=%d,scale=%d:-2:flags=lanczos,split[a][b];[a]palettegen[p];[b][p]paletteuse",
+		previewFPS, previewWidth)
+	return f.render(ctx, ".gif",
+		"-t", fmt.Sprint(previewSeconds), "-i", videoPath,
+		"-vf", filter,
+		"-loop", "0",
+	)
+}
+
+// render runs ffmpeg with args and an output file of the given extension, returning the output
+func (f *FFmpeg) render(ctx context.Context, ext string, args ...string) ([]byte, error) {
+	if !f.Available() {
+		return nil, fmt.Errorf("%w: %s not found", entity.ErrMediaToolUnavailable, f.path)
+	}
+
+	dir, err := os.MkdirTemp("", "ffmpeg-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "out"+ext)
+
+	ctx, cancel := context.WithTimeout(ctx, ffmpegTimeout)
+	defer cancel()
+	if err := f.run(ctx, append(args, output)...); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg produced no output: %w", err)
+	}
+	return data, nil
+}
+
+// run executes ffmpeg non-interactively, including the end of its log in any error
+func (f *FFmpeg) run(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, f.path, append([]string{"-hide_banner", "-loglevel", "error", "-nostdin", "-y"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, lastLines(stderr.String(), 3))
+	}
+	return nil
+}
+
+// lastLines returns the last n non-empty lines of s
+func lastLines(s string, n int) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, " | ")
+}
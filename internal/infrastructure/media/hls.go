@@ -0,0 +1,159 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+)
+
+const (
+	// hlsMasterPlaylist is the name of the master playlist written by TranscodeHLS
+	hlsMasterPlaylist = "master.m3u8"
+
+	// hlsTimeout bounds transcoding a whole ladder
+	hlsTimeout = 15 * time.Minute
+
+	// hlsSegmentSeconds is the target segment length
+	hlsSegmentSeconds = 4
+)
+
+// hlsRendition is one rung of the HLS ladder
+type hlsRendition struct {
+	Name         string
+	ShortSide    int // Length of the shorter side, so portrait and landscape rungs have the same pixels; zero keeps the source size
+	VideoBitrate int // kbit/s
+	AudioBitrate int // kbit/s
+}
+
+// hlsLadder lists the renditions produced for each video; rungs at or above the source's short side are skipped
+var hlsLadder = []hlsRendition{
+	{Name: "360p", ShortSide: 360, VideoBitrate: 800, AudioBitrate: 96},
+	{Name: "540p", ShortSide: 540, VideoBitrate: 1400, AudioBitrate: 96},
+	{Name: "720p", ShortSide: 720, VideoBitrate: 2800, AudioBitrate: 128},
+	{Name: "source", VideoBitrate: 5000, AudioBitrate: 128},
+}
+
+// TranscodeHLS writes an HLS ladder of videoPath into outDir: one directory of segments and a
+// playlist per rendition, plus a master playlist referencing them by relative path. It returns
+// the master playlist's path relative to outDir.
+func (f *FFmpeg) TranscodeHLS(ctx context.Context, videoPath, outDir string) (string, error) {
+	if !f.Available() {
+		return "", fmt.Errorf("%w: %s not found", entity.ErrMediaToolUnavailable, f.path)
+	}
+
+	width, height, hasAudio, err := f.probeStreams(ctx, videoPath)
+	if err != nil {
+		return "", err
+	}
+
+	ladder := hlsLadderFor(width, height)
+	args := []string{"-i", videoPath, "-filter_complex", hlsFilter(ladder)}
+	var streamMap []string
+	for i, rendition := range ladder {
+		args = append(args, "-map", fmt.Sprintf("[v%d]", i))
+		if hasAudio {
+			args = append(args, "-map", "0:a:0")
+		}
+
+		bitrate := strconv.Itoa(rendition.VideoBitrate) + "k"
+		args = append(args,
+			fmt.Sprintf("-b:v:%d", i), bitrate,
+			fmt.Sprintf("-maxrate:v:%d", i), bitrate,
+			fmt.Sprintf("-bufsize:v:%d", i), strconv.Itoa(rendition.VideoBitrate*2)+"k",
+		)
+		entry := fmt.Sprintf("v:%d", i)
+		if hasAudio {
+			args = append(args, fmt.Sprintf("-b:a:%d", i), strconv.Itoa(rendition.AudioBitrate)+"k")
+			entry += fmt.Sprintf(",a:%d", i)
+		}
+		streamMap = append(streamMap, entry+",name:"+rendition.Name)
+	}
+
+	// Fixed keyframe intervals keep segment boundaries aligned across renditions
+	args = append(args,
+		"-c:v", "libx264", "-preset", "veryfast", "-profile:v", "main",
+		"-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", hlsSegmentSeconds),
+		"-sc_threshold", "0",
+	)
+	if hasAudio {
+		args = append(args, "-c:a", "aac", "-ac", "2")
+	}
+	args = append(args,
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(hlsSegmentSeconds),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(outDir, "%v", "segment_%03d.ts"),
+		"-master_pl_name", hlsMasterPlaylist,
+		"-var_stream_map", strings.Join(streamMap, " "),
+		filepath.Join(outDir, "%v", "index.m3u8"),
+	)
+
+	ctx, cancel := context.WithTimeout(ctx, hlsTimeout)
+	defer cancel()
+	if err := f.run(ctx, args...); err != nil {
+		return "", err
+	}
+
+	// ffmpeg places the master playlist relative to the variant playlists, so look for it
+	var master string
+	filepath.WalkDir(outDir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && master == "" && entry.Name() == hlsMasterPlaylist {
+			master, _ = filepath.Rel(outDir, path)
+		}
+		return nil
+	})
+	if master == "" {
+		return "", fmt.Errorf("ffmpeg did not write a master playlist")
+	}
+	return master, nil
+}
+
+// hlsLadderFor returns the rungs of hlsLadder smaller than a width x height source, compared on the
+// short side so a portrait video gets the same rungs as the landscape video with its pixel count
+func hlsLadderFor(width, height int) []hlsRendition {
+	sourceShortSide := min(width, height)
+	var ladder []hlsRendition
+	for _, rendition := range hlsLadder {
+		if rendition.ShortSide == 0 || rendition.ShortSide < sourceShortSide {
+			ladder = append(ladder, rendition)
+		}
+	}
+	return ladder
+}
+
+// hlsFilter splits the decoded video once and scales each branch to its rung, so the source is only
+// decoded once. Rungs are scaled on the short side, keeping portrait videos portrait at the same pixel count.
+func hlsFilter(ladder []hlsRendition) string {
+	filter := fmt.Sprintf("[0:v]split=%d", len(ladder))
+	for i := range ladder {
+		filter += fmt.Sprintf("[s%d]", i)
+	}
+	for i, rendition := range ladder {
+		if rendition.ShortSide == 0 {
+			filter += fmt.Sprintf(";[s%d]null[v%d]", i, i)
+		} else {
+			filter += fmt.Sprintf(";[s%d]scale='if(gt(iw,ih),-2,%d)':'if(gt(iw,ih),%d,-2)'[v%d]", i, rendition.ShortSide, rendition.ShortSide, i)
+		}
+	}
+	return filter
+}
+
+// probeStreams reads the displayed video dimensions and whether there is an audio track with ffprobe
+func (f *FFmpeg) probeStreams(ctx context.Context, videoPath string) (int, int, bool, error) {
+	report, err := f.probe(ctx, videoPath)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	metadata, err := report.metadata()
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("%w in %s", err, filepath.Base(videoPath))
+	}
+
+	return metadata.Width, metadata.Height, report.hasAudio(), nil
+}
@@ -0,0 +1,103 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+// HLSTranscoder cuts a local video into an HLS ladder, as FFmpeg.TranscodeHLS does
+type HLSTranscoder interface {
+	TranscodeHLS(ctx context.Context, videoPath, outDir string) (masterPlaylist string, err error)
+}
+
+// HLSPublisher transcodes videos into HLS ladders and stores every playlist and segment in media storage
+type HLSPublisher struct {
+	transcoder HLSTranscoder
+	storage    service.MediaStorage
+}
+
+// NewHLSPublisher creates an HLSPublisher
+func NewHLSPublisher(transcoder HLSTranscoder, storage service.MediaStorage) *HLSPublisher {
+	return &HLSPublisher{
+		transcoder: transcoder,
+		storage:    storage,
+	}
+}
+
+// Publish transcodes the local video and stores the ladder under prefix, returning the key of the
+// master playlist. Playlists reference segments by relative path, so the layout is kept under the prefix.
+func (p *HLSPublisher) Publish(ctx context.Context, videoPath, prefix string) (string, error) {
+	dir, err := os.MkdirTemp("", "hls-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	masterPlaylist, err := p.transcoder.TranscodeHLS(ctx, videoPath, dir)
+	if err != nil {
+		return "", err
+	}
+
+	err = filepath.WalkDir(dir, func(file string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		return p.storage.Put(ctx, path.Join(prefix, filepath.ToSlash(rel)), bytes.NewReader(data), int64(len(data)), hlsContentType(file))
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return path.Join(prefix, filepath.ToSlash(masterPlaylist)), nil
+}
+
+// PublishStored transcodes the video stored under videoKey and stores the ladder under prefix,
+// returning the key of the master playlist
+func (p *HLSPublisher) PublishStored(ctx context.Context, videoKey, prefix string) (string, error) {
+	content, _, err := p.storage.Open(ctx, videoKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to open video: %w", err)
+	}
+	defer content.Close()
+
+	// ffmpeg reads the video from disk
+	file, err := os.CreateTemp("", "hls-source-*.mp4")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download video: %w", err)
+	}
+
+	return p.Publish(ctx, file.Name(), prefix)
+}
+
+// hlsContentType returns the content type of an HLS playlist or segment file
+func hlsContentType(file string) string {
+	if strings.HasSuffix(file, ".m3u8") {
+		return "application/vnd.apple.mpegurl"
+	}
+	return "video/mp2t"
+}
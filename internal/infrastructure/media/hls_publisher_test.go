@@ -0,0 +1,94 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/arabella/ai-studio-backend/internal/infrastructure/storage"
+)
+
+// fakeTranscoder writes a one-rendition ladder and records the video it was given
+type fakeTranscoder struct {
+	source []byte
+}
+
+func (t *fakeTranscoder) TranscodeHLS(ctx context.Context, videoPath, outDir string) (string, error) {
+	var err error
+	if t.source, err = os.ReadFile(videoPath); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Join(outDir, "720p"), 0o755); err != nil {
+		return "", err
+	}
+	files := map[string]string{
+		"master.m3u8":         "#EXTM3U\n720p.m3u8\n",
+		"720p.m3u8":           "#EXTM3U\n720p/segment_000.ts\n",
+		"720p/segment_000.ts": "segment",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(outDir, name), []byte(content), 0o644); err != nil {
+			return "", err
+		}
+	}
+	return "master.m3u8", nil
+}
+
+func TestHLSPublisherPublishStored(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewLocalMediaStorage(t.TempDir(), "https://api.arabella.uz/media")
+	source := []byte("clean original")
+	if err := store.Put(ctx, "sources/u/j.mp4", bytes.NewReader(source), int64(len(source)), "video/mp4"); err != nil {
+		t.Fatal(err)
+	}
+
+	transcoder := &fakeTranscoder{}
+	playlistKey, err := NewHLSPublisher(transcoder, store).PublishStored(ctx, "sources/u/j.mp4", "hls/u/j-1")
+	if err != nil {
+		t.Fatalf("PublishStored: %v", err)
+	}
+
+	if playlistKey != "hls/u/j-1/master.m3u8" {
+		t.Errorf("playlist key = %q", playlistKey)
+	}
+	if !bytes.Equal(transcoder.source, source) {
+		t.Errorf("transcoded %q, want the stored video", transcoder.source)
+	}
+
+	for key, want := range map[string]string{
+		"hls/u/j-1/master.m3u8":         "#EXTM3U\n720p.m3u8\n",
+		"hls/u/j-1/720p.m3u8":           "#EXTM3U\n720p/segment_000.ts\n",
+		"hls/u/j-1/720p/segment_000.ts": "segment",
+	} {
+		content, _, err := store.Open(ctx, key)
+		if err != nil {
+			t.Errorf("%s not stored: %v", key, err)
+			continue
+		}
+		data, _ := io.ReadAll(content)
+		content.Close()
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", key, data, want)
+		}
+	}
+}
+
+func TestHLSPublisherPublishStoredMissingVideo(t *testing.T) {
+	store := storage.NewLocalMediaStorage(t.TempDir(), "")
+
+	if _, err := NewHLSPublisher(&fakeTranscoder{}, store).PublishStored(context.Background(), "sources/missing.mp4", "hls/x"); err == nil {
+		t.Error("PublishStored succeeded for a missing video")
+	}
+}
+
+func TestHLSContentType(t *testing.T) {
+	if got := hlsContentType("720p.m3u8"); got != "application/vnd.apple.mpegurl" {
+		t.Errorf("playlist content type = %q", got)
+	}
+	if got := hlsContentType("720p/segment_000.ts"); got != "video/mp2t" {
+		t.Errorf("segment content type = %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+package media
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHLSLadderFor(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		want          []string
+	}{
+		{"landscape 720p", 1280, 720, []string{"360p", "540p", "source"}},
+		// A 720x1280 portrait video has the same pixels as landscape 720p, so it gets the same rungs
+		{"portrait 720p", 720, 1280, []string{"360p", "540p", "source"}},
+		{"portrait 1080p", 1080, 1920, []string{"360p", "540p", "720p", "source"}},
+		{"square", 540, 540, []string{"360p", "source"}},
+		{"small", 640, 360, []string{"source"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, rendition := range hlsLadderFor(tt.width, tt.height) {
+				got = append(got, rendition.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ladder = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHLSFilterScalesShortSide(t *testing.T) {
+	filter := hlsFilter(hlsLadderFor(1080, 1920))
+
+	want := "[0:v]split=4[s0][s1][s2][s3]" +
+		";[s0]scale='if(gt(iw,ih),-2,360)':'if(gt(iw,ih),360,-2)'[v0]" +
+		";[s1]scale='if(gt(iw,ih),-2,540)':'if(gt(iw,ih),540,-2)'[v1]" +
+		";[s2]scale='if(gt(iw,ih),-2,720)':'if(gt(iw,ih),720,-2)'[v2]" +
+		";[s3]null[v3]"
+	if filter != want {
+		t.Errorf("filter =\n%s\nwant\n%s", filter, want)
+	}
+	// Scaling by height alone would turn a portrait 720p rung into 405x720
+	if strings.Contains(filter, "scale=-2:") {
+		t.Error("filter scales on height only")
+	}
+}
@@ -0,0 +1,145 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // Register GIF decoding
+	"image/jpeg"
+	_ "image/png" // Register PNG decoding
+	"net/http"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"golang.org/x/image/draw"
+)
+
+// Start image limits. DashScope image-to-video accepts images between 360 and 2000 pixels per side.
+const (
+	MinStartImageSide = 360
+	MaxStartImageSide = 2000
+
+	// maxDecodedPixels guards against decompression bombs before an image is fully decoded. A 24MP
+	// image still costs about 100MB as RGBA, so the limit stays close to what cameras produce.
+	maxDecodedPixels = 24_000_000
+
+	// maxStartImageRatio is the widest (or tallest) aspect ratio accepted
+	maxStartImageRatio = 4
+
+	startImageJPEGQuality = 90
+
+	// uploadJPEGQuality balances size and fidelity for uploaded site images
+	uploadJPEGQuality = 85
+)
+
+// sniffedImageFormats maps the content types detected from magic bytes to image decoder formats
+var sniffedImageFormats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// ImageProcessor validates and normalises uploaded images
+type ImageProcessor struct{}
+
+// NewImageProcessor creates a new ImageProcessor
+func NewImageProcessor() *ImageProcessor {
+	return &ImageProcessor{}
+}
+
+// PrepareStartImage validates an uploaded image and re-encodes it as a JPEG no larger than
+// MaxStartImageSide on its longest edge. Re-encoding also strips any embedded metadata.
+func (p *ImageProcessor) PrepareStartImage(data []byte) ([]byte, int, int, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	if width < MinStartImageSide || height < MinStartImageSide {
+		return nil, 0, 0, fmt.Errorf("%w: image must be at least %dx%d pixels", entity.ErrInvalidAsset, MinStartImageSide, MinStartImageSide)
+	}
+	if width > height*maxStartImageRatio || height > width*maxStartImageRatio {
+		return nil, 0, 0, fmt.Errorf("%w: aspect ratio must be between 1:%d and %d:1", entity.ErrInvalidAsset, maxStartImageRatio, maxStartImageRatio)
+	}
+
+	return encodeJPEG(img, MaxStartImageSide, startImageJPEGQuality)
+}
+
+// PrepareUploadImage validates an uploaded image by its content and re-encodes it as a JPEG no
+// larger than maxSide on its longest edge, upright and without EXIF, GPS or other metadata
+func (p *ImageProcessor) PrepareUploadImage(data []byte, maxSide int) ([]byte, int, int, error) {
+	img, err := decodeImage(data)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return encodeJPEG(img, maxSide, uploadJPEGQuality)
+}
+
+// decodeImage decodes a JPEG, PNG or GIF (first frame) after checking its magic bytes agree with
+// its decoded format and its dimensions are sane. JPEGs are rotated upright by their EXIF orientation.
+func decodeImage(data []byte) (image.Image, error) {
+	sniffed, ok := sniffedImageFormats[http.DetectContentType(data)]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported or corrupt image (jpeg, png and gif are accepted)", entity.ErrInvalidAsset)
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || format != sniffed {
+		return nil, fmt.Errorf("%w: unsupported or corrupt image (jpeg, png and gif are accepted)", entity.ErrInvalidAsset)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxDecodedPixels {
+		return nil, fmt.Errorf("%w: image dimensions %dx%d are out of range", entity.ErrInvalidAsset, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode %s image", entity.ErrInvalidAsset, format)
+	}
+
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+	return img, nil
+}
+
+// encodeJPEG downscales img to fit within maxSide and encodes it as a JPEG, returning its size
+func encodeJPEG(img image.Image, maxSide, quality int) ([]byte, int, int, error) {
+	width, height := fitWithin(img.Bounds().Dx(), img.Bounds().Dy(), maxSide)
+	if width != img.Bounds().Dx() || height != img.Bounds().Dy() {
+		img = downscale(img, width, height)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: quality}); err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	return buf.Bytes(), width, height, nil
+}
+
+// fitWithin scales width and height down so neither exceeds maxSide, keeping the aspect ratio
+func fitWithin(width, height, maxSide int) (int, int) {
+	if width <= maxSide && height <= maxSide {
+		return width, height
+	}
+	if width >= height {
+		return maxSide, max(1, height*maxSide/width)
+	}
+	return max(1, width*maxSide/height), maxSide
+}
+
+// downscale resizes img to width x height with a Catmull-Rom filter
+func downscale(img image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
+
+// flatten composites img onto white so transparent areas do not turn black in the JPEG
+func flatten(img image.Image) image.Image {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(bounds)
+	draw.Draw(dst, bounds, image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, bounds, img, bounds.Min, draw.Over)
+	return dst
+}
@@ -0,0 +1,101 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+)
+
+// encodePNG encodes a width x height PNG filled with c
+func encodePNG(t *testing.T, width, height int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withPNGDimensions rewrites a PNG's header to claim other dimensions, as a decompression bomb does
+func withPNGDimensions(data []byte, width, height uint32) []byte {
+	bomb := bytes.Clone(data)
+	// The IHDR chunk follows the 8-byte signature: length, type, width, height, ..., CRC
+	binary.BigEndian.PutUint32(bomb[16:], width)
+	binary.BigEndian.PutUint32(bomb[20:], height)
+	binary.BigEndian.PutUint32(bomb[29:], crc32.ChecksumIEEE(bomb[12:29]))
+	return bomb
+}
+
+func TestPrepareUploadImageRejectsOversizedDimensions(t *testing.T) {
+	p := NewImageProcessor()
+	small := encodePNG(t, 64, 64, color.White)
+
+	// 5000x5000 is 25MP: past the limit, though the file itself is tiny
+	_, _, _, err := p.PrepareUploadImage(withPNGDimensions(small, 5000, 5000), 2000)
+	if !errors.Is(err, entity.ErrInvalidAsset) {
+		t.Fatalf("error = %v, want ErrInvalidAsset", err)
+	}
+}
+
+func TestPrepareUploadImageDownscales(t *testing.T) {
+	p := NewImageProcessor()
+	red := color.NRGBA{R: 200, G: 30, B: 30, A: 255}
+
+	data, width, height, err := p.PrepareUploadImage(encodePNG(t, 1600, 400, red), 800)
+	if err != nil {
+		t.Fatalf("PrepareUploadImage: %v", err)
+	}
+	if width != 800 || height != 200 {
+		t.Fatalf("size = %dx%d, want 800x200", width, height)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if bounds := img.Bounds(); bounds.Dx() != 800 || bounds.Dy() != 200 {
+		t.Errorf("encoded size = %v, want 800x200", bounds)
+	}
+	// A flat colour survives resampling and JPEG encoding within a small tolerance
+	r, g, b, _ := img.At(400, 100).RGBA()
+	if diff(r>>8, 200) > 8 || diff(g>>8, 30) > 8 || diff(b>>8, 30) > 8 {
+		t.Errorf("centre pixel = %d,%d,%d, want about 200,30,30", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestPrepareUploadImageFlattensTransparency(t *testing.T) {
+	p := NewImageProcessor()
+
+	data, _, _, err := p.PrepareUploadImage(encodePNG(t, 400, 400, color.NRGBA{}), 200)
+	if err != nil {
+		t.Fatalf("PrepareUploadImage: %v", err)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode result: %v", err)
+	}
+	if r, g, b, _ := img.At(100, 100).RGBA(); r>>8 < 250 || g>>8 < 250 || b>>8 < 250 {
+		t.Errorf("transparent pixel = %d,%d,%d, want white", r>>8, g>>8, b>>8)
+	}
+}
+
+func diff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
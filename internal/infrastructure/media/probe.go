@@ -0,0 +1,180 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+)
+
+// probeReport is the part of ffprobe's JSON report that videos are measured from
+type probeReport struct {
+	Streams []probeStream `json:"streams"`
+	Format  struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+// probeStream is one stream of an ffprobe report
+type probeStream struct {
+	CodecType    string `json:"codec_type"`
+	CodecName    string `json:"codec_name"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	AvgFrameRate string `json:"avg_frame_rate"`
+	RFrameRate   string `json:"r_frame_rate"`
+	Duration     string `json:"duration"`
+	Disposition  struct {
+		AttachedPic int `json:"attached_pic"`
+	} `json:"disposition"`
+	Tags struct {
+		Rotate string `json:"rotate"`
+	} `json:"tags"`
+	SideDataList []struct {
+		SideDataType string  `json:"side_data_type"`
+		Rotation     float64 `json:"rotation"`
+	} `json:"side_data_list"`
+}
+
+// ProbeVideo measures a video's dimensions, frame rate, codec, duration and file size with ffprobe.
+// Rotated videos report the dimensions they are displayed at.
+func (f *FFmpeg) ProbeVideo(ctx context.Context, videoPath string) (*entity.VideoMetadata, error) {
+	stat, err := os.Stat(videoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := f.probe(ctx, videoPath)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := report.metadata()
+	if err != nil {
+		return nil, fmt.Errorf("%w in %s", err, filepath.Base(videoPath))
+	}
+	metadata.SizeBytes = stat.Size()
+
+	return metadata, nil
+}
+
+// probe runs ffprobe on a file and parses its report. A non-zero exit means the file could not be
+// read, whatever ffprobe printed.
+func (f *FFmpeg) probe(ctx context.Context, videoPath string) (*probeReport, error) {
+	if !f.ProbeAvailable() {
+		return nil, fmt.Errorf("%w: %s not found", entity.ErrMediaToolUnavailable, f.probePath)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ffmpegTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, f.probePath,
+		"-v", "error", "-print_format", "json", "-show_streams", "-show_format", videoPath)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed on %s: %w: %s", filepath.Base(videoPath), err, lastLines(stderr.String(), 3))
+	}
+	return parseProbeReport(output)
+}
+
+// parseProbeReport decodes the JSON printed by "ffprobe -print_format json -show_streams -show_format"
+func parseProbeReport(data []byte) (*probeReport, error) {
+	report := &probeReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("invalid ffprobe report: %w", err)
+	}
+	return report, nil
+}
+
+// videoStream returns the first video stream, skipping cover art, or nil if there is none
+func (r *probeReport) videoStream() *probeStream {
+	for i := range r.Streams {
+		if r.Streams[i].CodecType == "video" && r.Streams[i].Disposition.AttachedPic == 0 {
+			return &r.Streams[i]
+		}
+	}
+	return nil
+}
+
+// hasAudio reports whether the file has an audio stream
+func (r *probeReport) hasAudio() bool {
+	for _, stream := range r.Streams {
+		if stream.CodecType == "audio" {
+			return true
+		}
+	}
+	return false
+}
+
+// metadata reads the first video stream and the container duration
+func (r *probeReport) metadata() (*entity.VideoMetadata, error) {
+	stream := r.videoStream()
+	if stream == nil {
+		return nil, fmt.Errorf("no video stream found")
+	}
+	metadata := &entity.VideoMetadata{
+		Width:  stream.Width,
+		Height: stream.Height,
+		Codec:  stream.CodecName,
+	}
+
+	metadata.FPS = parseFrameRate(stream.AvgFrameRate)
+	if metadata.FPS == 0 {
+		metadata.FPS = parseFrameRate(stream.RFrameRate)
+	}
+
+	// Phones record portrait video as landscape frames with a rotation for the player to apply
+	if degrees, ok := stream.rotation(); ok && int(math.Round(degrees))%180 != 0 {
+		metadata.Width, metadata.Height = metadata.Height, metadata.Width
+	}
+
+	duration := r.Format.Duration
+	if duration == "" {
+		duration = stream.Duration
+	}
+	metadata.DurationSeconds, _ = strconv.ParseFloat(duration, 64)
+
+	return metadata, nil
+}
+
+// rotation returns the stream's display rotation in degrees, from its display matrix or, as older
+// files store it, its rotate tag
+func (s *probeStream) rotation() (float64, bool) {
+	for _, sideData := range s.SideDataList {
+		if sideData.SideDataType == "Display Matrix" {
+			return sideData.Rotation, true
+		}
+	}
+	if s.Tags.Rotate != "" {
+		degrees, err := strconv.ParseFloat(s.Tags.Rotate, 64)
+		return degrees, err == nil
+	}
+	return 0, false
+}
+
+// parseFrameRate converts an ffprobe rate such as "30000/1001" to frames per second, rounded to two
+// decimals; zero means the rate is unknown
+func parseFrameRate(rate string) float64 {
+	num, den, found := strings.Cut(rate, "/")
+	numerator, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0
+	}
+	denominator := 1.0
+	if found {
+		if denominator, err = strconv.ParseFloat(den, 64); err != nil || denominator == 0 {
+			return 0
+		}
+	}
+	return math.Round(numerator/denominator*100) / 100
+}
@@ -0,0 +1,133 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+)
+
+func TestProbeReportMetadata(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    entity.VideoMetadata
+	}{
+		{"landscape_720p.json", entity.VideoMetadata{Width: 1280, Height: 720, FPS: 24, Codec: "h264", DurationSeconds: 5.04}},
+		// Phone recordings store landscape frames and a display matrix, so the displayed size is portrait
+		{"rotated_phone.json", entity.VideoMetadata{Width: 1080, Height: 1920, FPS: 29.97, Codec: "hevc", DurationSeconds: 12.37}},
+		// Older files carry a rotate tag on the video stream instead
+		{"rotated_legacy.json", entity.VideoMetadata{Width: 720, Height: 1280, FPS: 30.01, Codec: "h264", DurationSeconds: 8.1}},
+		{"upside_down.json", entity.VideoMetadata{Width: 1280, Height: 720, FPS: 30, Codec: "h264", DurationSeconds: 4}},
+		// An unknown average rate falls back to the base rate
+		{"high_fps.json", entity.VideoMetadata{Width: 1920, Height: 1080, FPS: 1000, Codec: "vp9", DurationSeconds: 6.52}},
+		{"no_audio_4k.json", entity.VideoMetadata{Width: 3840, Height: 2160, FPS: 23.98, Codec: "hevc", DurationSeconds: 8}},
+		{"no_duration.json", entity.VideoMetadata{Width: 720, Height: 1280, FPS: 25, Codec: "h264"}},
+		// Cover art is skipped, and another stream's rotation does not turn the video
+		{"cover_art.json", entity.VideoMetadata{Width: 1280, Height: 720, FPS: 24, Codec: "h264", DurationSeconds: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			report, err := parseProbeReport(readProbeFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatalf("parseProbeReport: %v", err)
+			}
+			got, err := report.metadata()
+			if err != nil {
+				t.Fatalf("metadata: %v", err)
+			}
+			if got.Width != tt.want.Width || got.Height != tt.want.Height {
+				t.Errorf("dimensions = %dx%d, want %dx%d", got.Width, got.Height, tt.want.Width, tt.want.Height)
+			}
+			if got.FPS != tt.want.FPS {
+				t.Errorf("fps = %v, want %v", got.FPS, tt.want.FPS)
+			}
+			if got.Codec != tt.want.Codec {
+				t.Errorf("codec = %q, want %q", got.Codec, tt.want.Codec)
+			}
+			if got.DurationSeconds != tt.want.DurationSeconds {
+				t.Errorf("duration = %v, want %v", got.DurationSeconds, tt.want.DurationSeconds)
+			}
+		})
+	}
+}
+
+func TestProbeReportWithoutVideo(t *testing.T) {
+	report, err := parseProbeReport(readProbeFixture(t, "no_video.json"))
+	if err != nil {
+		t.Fatalf("parseProbeReport: %v", err)
+	}
+	if _, err := report.metadata(); err == nil {
+		t.Fatal("expected an error for an audio-only file")
+	}
+	if !report.hasAudio() {
+		t.Error("audio stream not found")
+	}
+	if _, err := parseProbeReport(nil); err == nil {
+		t.Fatal("expected an error for empty ffprobe output")
+	}
+}
+
+func TestProbeVideo(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("stub ffprobe is a shell script")
+	}
+	video := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(video, []byte("not really a video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := filepath.Abs(filepath.Join("testdata", "probe", "rotated_phone.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("report", func(t *testing.T) {
+		ffmpeg := NewFFmpeg("", stubFFprobe(t, "cat '"+fixture+"'"))
+		metadata, err := ffmpeg.ProbeVideo(context.Background(), video)
+		if err != nil {
+			t.Fatalf("ProbeVideo: %v", err)
+		}
+		if metadata.Width != 1080 || metadata.Height != 1920 || metadata.SizeBytes != 18 {
+			t.Errorf("metadata = %dx%d, %d bytes; want 1080x1920, 18 bytes", metadata.Width, metadata.Height, metadata.SizeBytes)
+		}
+	})
+
+	// A report printed before ffprobe gave up on the file is not trusted
+	t.Run("non-zero exit", func(t *testing.T) {
+		ffmpeg := NewFFmpeg("", stubFFprobe(t, "cat '"+fixture+"'; echo 'moov atom not found' >&2; exit 1"))
+		if _, err := ffmpeg.ProbeVideo(context.Background(), video); err == nil {
+			t.Fatal("ProbeVideo succeeded although ffprobe failed")
+		}
+	})
+
+	t.Run("missing ffprobe", func(t *testing.T) {
+		ffmpeg := NewFFmpeg("", filepath.Join(t.TempDir(), "ffprobe"))
+		if _, err := ffmpeg.ProbeVideo(context.Background(), video); !errors.Is(err, entity.ErrMediaToolUnavailable) {
+			t.Errorf("ProbeVideo error = %v, want %v", err, entity.ErrMediaToolUnavailable)
+		}
+	})
+}
+
+// stubFFprobe writes an executable script running body in place of ffprobe and returns its path
+func stubFFprobe(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ffprobe")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// readProbeFixture loads a report captured with "ffprobe -v error -print_format json -show_streams -show_format"
+func readProbeFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "probe", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return data
+}
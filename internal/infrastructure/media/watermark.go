@@ -0,0 +1,75 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+)
+
+// watermarkTimeout bounds re-encoding a whole video with the watermark burned in
+const watermarkTimeout = 10 * time.Minute
+
+// WatermarkPositions maps each supported watermark position to its ffmpeg overlay coordinates,
+// where W and H are the video size, w and h the logo size and m the margin
+var WatermarkPositions = map[string]string{
+	"top-left":     "m:m",
+	"top-right":    "W-w-m:m",
+	"bottom-left":  "m:H-h-m",
+	"bottom-right": "W-w-m:H-h-m",
+	"center":       "(W-w)/2:(H-h)/2",
+}
+
+// WatermarkConfig describes the logo overlaid on watermarked videos
+type WatermarkConfig struct {
+	ImagePath string // PNG logo, drawn at its own size; transparency is kept
+	Position  string // One of WatermarkPositions
+	Margin    int    // Distance in pixels from the edges
+}
+
+// SetWatermark configures the logo used by ApplyWatermark. Watermarked videos cannot be
+// completed without ffmpeg, so it must be available.
+func (f *FFmpeg) SetWatermark(cfg WatermarkConfig) error {
+	if !f.Available() {
+		return fmt.Errorf("%w: watermarking needs %s", entity.ErrMediaToolUnavailable, f.path)
+	}
+	if _, ok := WatermarkPositions[cfg.Position]; !ok {
+		return fmt.Errorf("invalid watermark position %q", cfg.Position)
+	}
+	if _, err := os.Stat(cfg.ImagePath); err != nil {
+		return fmt.Errorf("watermark image: %w", err)
+	}
+	f.watermark = &cfg
+	return nil
+}
+
+// ApplyWatermark writes a copy of videoPath to outPath with the configured logo overlaid.
+// Audio is copied unchanged; the video is re-encoded since the logo is burned into every frame.
+func (f *FFmpeg) ApplyWatermark(ctx context.Context, videoPath, outPath string) error {
+	if f.watermark == nil {
+		return fmt.Errorf("no watermark image is configured")
+	}
+	if !f.Available() {
+		return fmt.Errorf("%w: %s not found", entity.ErrMediaToolUnavailable, f.path)
+	}
+
+	position := strings.ReplaceAll(WatermarkPositions[f.watermark.Position], "m", strconv.Itoa(f.watermark.Margin))
+
+	ctx, cancel := context.WithTimeout(ctx, watermarkTimeout)
+	defer cancel()
+	return f.run(ctx,
+		"-i", videoPath,
+		"-i", f.watermark.ImagePath,
+		"-filter_complex", "[0:v][1:v]overlay="+position+":format=auto[v]",
+		"-map", "[v]", "-map", "0:a?",
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "20", "-pix_fmt", "yuv420p",
+		"-c:a", "copy",
+		"-movflags", "+faststart",
+		"-f", "mp4",
+		outPath,
+	)
+}
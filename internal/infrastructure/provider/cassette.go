@@ -0,0 +1,310 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// CassetteMode controls how provider HTTP traffic is recorded or replayed
+type CassetteMode string
+
+const (
+	CassetteModeOff    CassetteMode = "off"
+	CassetteModeRecord CassetteMode = "record"
+	CassetteModeReplay CassetteMode = "replay"
+)
+
+// redactedValue replaces secrets in recorded traffic
+const redactedValue = "REDACTED"
+
+// redactedHeaders are never written to a cassette
+var redactedHeaders = []string{"Authorization", "X-Api-Key", "X-Goog-Api-Key", "Cookie", "Set-Cookie"}
+
+// redactedParams are query parameters whose values are replaced in URLs and bodies, matched case-insensitively
+var redactedParams = []string{
+	"key", "api_key", "access_token", "token", "security-token",
+	"sig", "signature", "ossaccesskeyid",
+	"x-amz-signature", "x-amz-credential", "x-amz-security-token",
+	"x-goog-signature", "x-goog-credential",
+}
+
+// signedParamPattern matches secret query parameters embedded in bodies (e.g. signed result URLs),
+// including separators escaped as \u0026 in JSON or &amp; in HTML
+var signedParamPattern = regexp.MustCompile(`(?i)((?:[?&]|\\u0026|&amp;)(?:` + strings.Join(redactedParams, "|") + `))=[^&"\s\\]+`)
+
+// Interaction is a single recorded request/response pair
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the redacted form of a provider request
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the redacted form of a provider response
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Cassette is an http.RoundTripper that records provider traffic to a file or replays it from one.
+// In replay mode, requests are matched on method and redacted URL; repeated requests
+// (such as status polling) are served in recorded order and the last response is repeated.
+type Cassette struct {
+	path      string
+	mode      CassetteMode
+	transport http.RoundTripper
+	logger    *zap.Logger
+
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     map[string]int
+}
+
+// NewCassette creates a cassette for the given file. Replay mode loads the file immediately.
+func NewCassette(path string, mode CassetteMode, transport http.RoundTripper, logger *zap.Logger) (*Cassette, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	c := &Cassette{
+		path:      path,
+		mode:      mode,
+		transport: transport,
+		logger:    logger,
+		replayed:  make(map[string]int),
+	}
+
+	if mode == CassetteModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(data, &c.interactions); err != nil {
+			return nil, fmt.Errorf("failed to decode cassette: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch c.mode {
+	case CassetteModeRecord:
+		return c.record(req)
+	case CassetteModeReplay:
+		return c.replay(req)
+	default:
+		return c.transport.RoundTrip(req)
+	}
+}
+
+// record performs the real request and appends the redacted interaction to the cassette file
+func (c *Cassette) record(req *http.Request) (*http.Response, error) {
+	reqBody, err := readAndRestore(&req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	resp, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := readAndRestore(&resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     redactURL(req.URL),
+			Headers: redactHeaders(req.Header),
+			Body:    redactBody(reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    redactHeaders(resp.Header),
+			Body:       redactBody(respBody),
+		},
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.interactions = append(c.interactions, interaction)
+	if err := c.save(); err != nil {
+		c.logger.Warn("Failed to save cassette",
+			zap.String("path", c.path),
+			zap.Error(err),
+		)
+	}
+
+	return resp, nil
+}
+
+// replay serves the next recorded response matching the request
+func (c *Cassette) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	method, reqURL := req.Method, redactURL(req.URL)
+	key := method + " " + reqURL
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var matches []Interaction
+	for _, interaction := range c.interactions {
+		if interaction.Request.Method == method && interaction.Request.URL == reqURL {
+			matches = append(matches, interaction)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("cassette %s: no recorded response for %s", filepath.Base(c.path), key)
+	}
+
+	index := c.replayed[key]
+	if index >= len(matches) {
+		index = len(matches) - 1
+	}
+	c.replayed[key] = index + 1
+
+	recorded := matches[index].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Headers.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// save writes all recorded interactions to the cassette file
+func (c *Cassette) save() error {
+	data, err := json.MarshalIndent(c.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// AttachCassettes routes every registered provider's HTTP traffic through a per-provider
+// cassette file in dir (e.g. dir/wan_ai.json)
+func AttachCassettes(registry *ProviderRegistry, mode CassetteMode, dir string, logger *zap.Logger) error {
+	if mode == "" || mode == CassetteModeOff {
+		return nil
+	}
+
+	for _, p := range registry.GetAll() {
+		base, ok := p.(interface{ SetTransport(http.RoundTripper) })
+		if !ok {
+			continue
+		}
+
+		path := filepath.Join(dir, string(p.GetName())+".json")
+		cassette, err := NewCassette(path, mode, nil, logger)
+		if err != nil {
+			return fmt.Errorf("provider %s: %w", p.GetName(), err)
+		}
+		base.SetTransport(cassette)
+
+		logger.Info("Provider HTTP traffic routed through cassette",
+			zap.String("provider", string(p.GetName())),
+			zap.String("mode", string(mode)),
+			zap.String("path", path),
+		)
+	}
+
+	return nil
+}
+
+// readAndRestore reads a body and replaces it with an equivalent unread copy
+func readAndRestore(body *io.ReadCloser) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return "", err
+	}
+
+	*body = io.NopCloser(bytes.NewReader(data))
+	return string(data), nil
+}
+
+// redactURL returns the URL with secret query parameters replaced
+func redactURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	for name := range query {
+		if isRedactedParam(name) {
+			query.Set(name, redactedValue)
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
+// isRedactedParam reports whether a query parameter holds a secret
+func isRedactedParam(name string) bool {
+	for _, param := range redactedParams {
+		if strings.EqualFold(name, param) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeaders returns a copy of the headers with secrets replaced.
+// Content-Length is dropped because redaction can change the body length.
+func redactHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	redacted.Del("Content-Length")
+	for _, name := range redactedHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, redactedValue)
+		}
+	}
+	return redacted
+}
+
+// redactBody replaces secret query parameters in URLs embedded in a body
+func redactBody(body string) string {
+	return signedParamPattern.ReplaceAllString(body, "${1}="+redactedValue)
+}
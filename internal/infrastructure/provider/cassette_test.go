@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestCassetteRecordRedactsSecrets(t *testing.T) {
+	secrets := []string{"sk-live-secret", "url-key-secret", "asset-sig-secret", "oss-key-id", "oss-signature", "amz-sig-value", "cdn-sig-secret", "set-cookie-secret"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=set-cookie-secret")
+		// A signed OSS result URL, an S3 presigned URL and an HTML-escaped CDN link
+		io.WriteString(w, `{"video_url":"https://result.oss-ap-southeast-1.aliyuncs.com/video.mp4?Expires=1760000262&OSSAccessKeyId=oss-key-id&Signature=oss-signature",`+
+			`"backup":"https://bucket.s3.amazonaws.com/v.mp4?X-Amz-Expires=900\u0026x-amz-signature=amz-sig-value",`+
+			`"html":"<a href=\"https://cdn.arabella.app/v.mp4?expires=1&amp;SIG=cdn-sig-secret\">"}`)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "wan_ai.json")
+	recorder, err := NewCassette(path, CassetteModeRecord, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewCassette: %v", err)
+	}
+
+	// json.Marshal escapes & as \u0026, so signed URLs in request bodies carry escaped separators
+	body, _ := json.Marshal(map[string]string{
+		"img_url": "https://api.arabella.uz/api/v1/assets/1/content?expires=1760000000&sig=asset-sig-secret",
+	})
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/tasks?Key=url-key-secret&model=wan2.6", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer sk-live-secret")
+
+	resp, err := (&http.Client{Transport: recorder}).Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	received, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(received), "oss-signature") {
+		t.Error("the caller should receive the unredacted response")
+	}
+
+	recorded, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read cassette: %v", err)
+	}
+	for _, secret := range secrets {
+		if strings.Contains(string(recorded), secret) {
+			t.Errorf("cassette contains secret %q:\n%s", secret, recorded)
+		}
+	}
+	for _, kept := range []string{"Expires=1760000262", "model=wan2.6", "expires=1760000000"} {
+		if !strings.Contains(string(recorded), kept) {
+			t.Errorf("cassette lost non-secret parameter %q", kept)
+		}
+	}
+
+	// The redacted recording must still replay the same request
+	replay, err := NewCassette(path, CassetteModeReplay, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewCassette replay: %v", err)
+	}
+	replayReq, _ := http.NewRequest(http.MethodPost, server.URL+"/tasks?Key=another-key&model=wan2.6", bytes.NewReader(body))
+	replayed, err := replay.RoundTrip(replayReq)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	replayed.Body.Close()
+	if replayed.StatusCode != http.StatusOK {
+		t.Errorf("replayed status = %d, want 200", replayed.StatusCode)
+	}
+}
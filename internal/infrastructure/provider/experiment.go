@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+// ExperimentArm is one side of a traffic split
+type ExperimentArm struct {
+	Name     string
+	Provider entity.AIProvider
+	Model    string // Provider-specific model override (empty uses the provider default)
+	Weight   int
+}
+
+// Experiment splits matching traffic between provider arms by weight.
+// An empty Tier or TemplateID matches every tier or template.
+type Experiment struct {
+	Name       string
+	Tier       entity.UserTier
+	TemplateID string
+	Arms       []ExperimentArm
+}
+
+// matches reports whether a selection request is in the experiment's scope
+func (e Experiment) matches(req service.ProviderSelectionRequest) bool {
+	if e.Tier != "" && e.Tier != req.UserTier {
+		return false
+	}
+	if e.TemplateID != "" && e.TemplateID != req.TemplateID {
+		return false
+	}
+	return true
+}
+
+// assign picks an arm by weight. The pick is derived from the key so a job stays in the
+// same arm when it is retried or deferred.
+func (e Experiment) assign(key string) (ExperimentArm, bool) {
+	total := 0
+	for _, arm := range e.Arms {
+		if arm.Weight > 0 {
+			total += arm.Weight
+		}
+	}
+	if total == 0 {
+		return ExperimentArm{}, false
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(e.Name + ":" + key))
+	point := int(h.Sum32() % uint32(total))
+
+	for _, arm := range e.Arms {
+		if arm.Weight <= 0 {
+			continue
+		}
+		if point < arm.Weight {
+			return arm, true
+		}
+		point -= arm.Weight
+	}
+	return ExperimentArm{}, false
+}
+
+// ValidateExperiments checks that every experiment can receive traffic and that each arm routes to a
+// registered provider, so a misspelt provider name fails startup instead of silently falling back
+func ValidateExperiments(experiments []Experiment, registry *ProviderRegistry) error {
+	for _, experiment := range experiments {
+		total := 0
+		for _, arm := range experiment.Arms {
+			if _, ok := registry.Get(arm.Provider); !ok {
+				return fmt.Errorf("experiment %q: arm %q uses unregistered provider %q", experiment.Name, arm.Name, arm.Provider)
+			}
+			if arm.Weight > 0 {
+				total += arm.Weight
+			}
+		}
+		if total == 0 {
+			return fmt.Errorf("experiment %q: arms have no positive weight", experiment.Name)
+		}
+	}
+	return nil
+}
+
+// ExperimentFor returns the first experiment in scope for a request and the arm assigned to it
+func (p RoutingPolicy) ExperimentFor(req service.ProviderSelectionRequest) (Experiment, ExperimentArm, bool) {
+	if req.JobID == "" {
+		return Experiment{}, ExperimentArm{}, false
+	}
+
+	for _, experiment := range p.Experiments {
+		if !experiment.matches(req) {
+			continue
+		}
+		if arm, ok := experiment.assign(req.JobID); ok {
+			return experiment, arm, true
+		}
+	}
+	return Experiment{}, ExperimentArm{}, false
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"go.uber.org/zap"
+)
+
+func TestValidateExperiments(t *testing.T) {
+	registry := NewProviderRegistry(zap.NewNop())
+	registry.Register(NewMockProvider(zap.NewNop(), false, time.Second, ""))
+
+	tests := []struct {
+		name    string
+		arms    []ExperimentArm
+		wantErr string
+	}{
+		{"valid", []ExperimentArm{{Name: "control", Provider: entity.ProviderMock, Weight: 90}, {Name: "treatment", Provider: entity.ProviderMock, Weight: 10}}, ""},
+		{"unregistered provider", []ExperimentArm{{Name: "control", Provider: entity.ProviderMock, Weight: 90}, {Name: "treatment", Provider: "wan_aii", Weight: 10}}, `unregistered provider "wan_aii"`},
+		{"no positive weight", []ExperimentArm{{Name: "control", Provider: entity.ProviderMock, Weight: 0}}, "no positive weight"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateExperiments([]Experiment{{Name: "split", Arms: tt.arms}}, registry)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ValidateExperiments: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
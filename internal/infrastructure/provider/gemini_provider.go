@@ -16,6 +16,9 @@ import (
 
 const (
 	geminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+	// geminiResultTTL is how long Gemini keeps generated videos available
+	geminiResultTTL = 48 * time.Hour
 )
 
 // GeminiProvider implements the Gemini VEO video generation provider
@@ -44,6 +47,7 @@ type GeminiPart struct {
 type GeminiConfig struct {
 	Temperature     float64 `json:"temperature,omitempty"`
 	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
+	Seed            *int64  `json:"seed,omitempty"`
 }
 
 // GeminiGenerateResponse represents a Gemini generation response
@@ -69,9 +73,9 @@ type GeminiVideoResponse struct {
 }
 
 // NewGeminiProvider creates a new Gemini provider
-func NewGeminiProvider(apiKey string, logger *zap.Logger) service.VideoProvider {
+func NewGeminiProvider(keys *KeyPool, logger *zap.Logger) service.VideoProvider {
 	return &GeminiProvider{
-		BaseProvider: NewBaseProvider(apiKey, geminiBaseURL, 5*time.Minute, logger),
+		BaseProvider: NewBaseProvider(keys, geminiBaseURL, 5*time.Minute, logger),
 	}
 }
 
@@ -82,9 +86,15 @@ func (p *GeminiProvider) GetName() entity.AIProvider {
 
 // GenerateVideo initiates video generation with Gemini VEO
 func (p *GeminiProvider) GenerateVideo(ctx context.Context, req service.GenerationRequest) (*entity.GenerationResult, error) {
+	seed := seedFor(req.Params)
+	model := "gemini-2.0-flash-exp"
+	if req.Model != "" {
+		model = req.Model
+	}
+
 	// Build the request
 	geminiReq := GeminiGenerateRequest{
-		Model: "gemini-2.0-flash-exp",
+		Model: model,
 		Contents: []GeminiContent{
 			{
 				Parts: []GeminiPart{
@@ -94,6 +104,7 @@ func (p *GeminiProvider) GenerateVideo(ctx context.Context, req service.Generati
 		},
 		Config: GeminiConfig{
 			Temperature: 0.7,
+			Seed:        &seed,
 		},
 	}
 
@@ -102,8 +113,13 @@ func (p *GeminiProvider) GenerateVideo(ctx context.Context, req service.Generati
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	key, err := p.apiKeyFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Make the API request
-	url := fmt.Sprintf("%s/models/gemini-2.0-flash-exp:generateContent?key=%s", p.baseURL, p.apiKey)
+	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", p.baseURL, model, key.Secret)
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -123,6 +139,10 @@ func (p *GeminiProvider) GenerateVideo(ctx context.Context, req service.Generati
 			zap.Int("status", resp.StatusCode),
 			zap.String("body", string(bodyBytes)),
 		)
+		if isGeminiKeyError(resp.StatusCode) {
+			p.rejectKey(key, fmt.Sprintf("Gemini API status %d", resp.StatusCode))
+			return nil, fmt.Errorf("%w: Gemini API key %s rejected with status %d", entity.ErrProviderRateLimited, key.ID, resp.StatusCode)
+		}
 		return nil, fmt.Errorf("Gemini API error: %d", resp.StatusCode)
 	}
 
@@ -137,16 +157,23 @@ func (p *GeminiProvider) GenerateVideo(ctx context.Context, req service.Generati
 
 	return &entity.GenerationResult{
 		ProviderJobID: geminiResp.Name,
+		ProviderKeyID: key.ID,
 		VideoURL:      geminiResp.Response.VideoURL,
 		ThumbnailURL:  geminiResp.Response.ThumbnailURL,
 		Duration:      geminiResp.Response.Duration,
+		Seed:          &seed,
 	}, nil
 }
 
 // GetProgress retrieves generation progress
 func (p *GeminiProvider) GetProgress(ctx context.Context, providerJobID string) (*entity.Progress, error) {
+	key, err := p.apiKeyFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Make the API request to check operation status
-	url := fmt.Sprintf("%s/operations/%s?key=%s", p.baseURL, providerJobID, p.apiKey)
+	url := fmt.Sprintf("%s/operations/%s?key=%s", p.baseURL, providerJobID, key.Secret)
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -178,10 +205,67 @@ func (p *GeminiProvider) GetProgress(ctx context.Context, providerJobID string)
 	}, nil
 }
 
+// GetResult retrieves the result of a completed generation
+func (p *GeminiProvider) GetResult(ctx context.Context, providerJobID string) (*entity.VideoResult, error) {
+	key, err := p.apiKeyFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make the API request to fetch the finished operation
+	url := fmt.Sprintf("%s/operations/%s?key=%s", p.baseURL, providerJobID, key.Secret)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Gemini API error: %d", resp.StatusCode)
+	}
+
+	var opResp GeminiGenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&opResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if opResp.Error != nil {
+		return nil, fmt.Errorf("Gemini error: %s", opResp.Error.Message)
+	}
+
+	if !opResp.Done || opResp.Response == nil {
+		return nil, fmt.Errorf("Gemini operation not completed: %s", providerJobID)
+	}
+
+	if opResp.Response.VideoURL == "" {
+		return nil, fmt.Errorf("Gemini operation completed but returned no video URL")
+	}
+
+	// Gemini deletes generated videos two days after generation
+	expiresAt := time.Now().Add(geminiResultTTL)
+
+	return &entity.VideoResult{
+		VideoURL:     opResp.Response.VideoURL,
+		ThumbnailURL: opResp.Response.ThumbnailURL,
+		Duration:     opResp.Response.Duration,
+		ExpiresAt:    &expiresAt,
+	}, nil
+}
+
 // CancelGeneration cancels an ongoing generation
 func (p *GeminiProvider) CancelGeneration(ctx context.Context, providerJobID string) error {
+	key, err := p.apiKeyFor(ctx)
+	if err != nil {
+		return err
+	}
+
 	// Make the API request to cancel
-	url := fmt.Sprintf("%s/operations/%s:cancel?key=%s", p.baseURL, providerJobID, p.apiKey)
+	url := fmt.Sprintf("%s/operations/%s:cancel?key=%s", p.baseURL, providerJobID, key.Secret)
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -207,17 +291,29 @@ func (p *GeminiProvider) GetCapabilities() service.ProviderCapabilities {
 		MaxDuration:     120,
 		MaxResolution:   entity.Resolution4K,
 		SupportedRatios: []entity.AspectRatio{entity.AspectRatio16x9, entity.AspectRatio9x16, entity.AspectRatio1x1},
+		SupportedFPS:    []int{24, 30},
 		EstimatedTime:   30,
 		QualityTier:     "premium",
 		SupportsStyles:  true,
 		CostPerSecond:   0.05,
+		SupportsSeed:    true,
 	}
 }
 
 // HealthCheck performs a health check
 func (p *GeminiProvider) HealthCheck(ctx context.Context) (*service.ProviderHealth, error) {
+	// Every key is cooling down
+	key, ok := p.keys.Any()
+	if !ok {
+		return &service.ProviderHealth{
+			IsHealthy:   false,
+			ErrorRate:   1.0,
+			LastChecked: time.Now().Unix(),
+		}, nil
+	}
+
 	// Make a simple request to check if the API is available
-	url := fmt.Sprintf("%s/models?key=%s", p.baseURL, p.apiKey)
+	url := fmt.Sprintf("%s/models?key=%s", p.baseURL, key.Secret)
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -248,3 +344,9 @@ func (p *GeminiProvider) HealthCheck(ctx context.Context) (*service.ProviderHeal
 	}, nil
 }
 
+// isGeminiKeyError reports whether a Gemini API status means the key is invalid or out of quota
+func isGeminiKeyError(statusCode int) bool {
+	return statusCode == http.StatusUnauthorized ||
+		statusCode == http.StatusForbidden ||
+		statusCode == http.StatusTooManyRequests
+}
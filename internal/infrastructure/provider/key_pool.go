@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+// KeySelectionStrategy controls which key a pool hands out next
+type KeySelectionStrategy string
+
+const (
+	KeySelectionRoundRobin KeySelectionStrategy = "round_robin"
+	KeySelectionLeastUsed  KeySelectionStrategy = "least_used"
+)
+
+// defaultKeyCooldown is how long a rejected key stays disabled when no cool-down is configured
+const defaultKeyCooldown = 10 * time.Minute
+
+// APIKey is a provider API key handed out by a KeyPool
+type APIKey struct {
+	ID     string // Stable, non-secret identifier recorded on jobs
+	Secret string
+}
+
+// pooledKey is a key with its usage and cool-down state
+type pooledKey struct {
+	APIKey
+	uses          int64
+	disabledUntil time.Time
+}
+
+// KeyPool hands out a provider's API keys and disables keys the provider rejects for a cool-down period
+type KeyPool struct {
+	provider entity.AIProvider
+	strategy KeySelectionStrategy
+	cooldown time.Duration
+
+	mu   sync.Mutex
+	keys []*pooledKey
+	next int
+}
+
+// NewKeyPool creates a key pool for a provider. Empty and duplicate secrets are ignored.
+func NewKeyPool(provider entity.AIProvider, secrets []string, strategy KeySelectionStrategy, cooldown time.Duration) *KeyPool {
+	if strategy != KeySelectionLeastUsed {
+		strategy = KeySelectionRoundRobin
+	}
+	if cooldown <= 0 {
+		cooldown = defaultKeyCooldown
+	}
+
+	pool := &KeyPool{
+		provider: provider,
+		strategy: strategy,
+		cooldown: cooldown,
+	}
+
+	seen := make(map[string]bool)
+	for _, secret := range secrets {
+		if secret == "" || seen[secret] {
+			continue
+		}
+		seen[secret] = true
+		pool.keys = append(pool.keys, &pooledKey{
+			APIKey: APIKey{ID: keyID(secret), Secret: secret},
+		})
+	}
+
+	return pool
+}
+
+// Size returns the number of keys in the pool
+func (p *KeyPool) Size() int {
+	return len(p.keys)
+}
+
+// Acquire returns the next enabled key according to the pool's strategy.
+// When every key is cooling down it returns a RateLimitError with the time until the first key is re-enabled.
+func (p *KeyPool) Acquire() (APIKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.keys) == 0 {
+		return APIKey{}, fmt.Errorf("%w: no API keys configured for %s", entity.ErrProviderUnavailable, p.provider)
+	}
+
+	now := time.Now()
+	var chosen *pooledKey
+	switch p.strategy {
+	case KeySelectionLeastUsed:
+		for _, key := range p.keys {
+			if key.disabledUntil.After(now) {
+				continue
+			}
+			if chosen == nil || key.uses < chosen.uses {
+				chosen = key
+			}
+		}
+	default:
+		for i := 0; i < len(p.keys); i++ {
+			key := p.keys[(p.next+i)%len(p.keys)]
+			if key.disabledUntil.After(now) {
+				continue
+			}
+			chosen = key
+			p.next = (p.next + i + 1) % len(p.keys)
+			break
+		}
+	}
+
+	if chosen == nil {
+		return APIKey{}, &service.RateLimitError{
+			Provider:   p.provider,
+			Operation:  service.ProviderOperationSubmit,
+			RetryAfter: p.earliestReenable(now),
+		}
+	}
+
+	chosen.uses++
+	return chosen.APIKey, nil
+}
+
+// Lookup returns the key with the given ID, even if it is cooling down,
+// because provider tasks can only be queried with the key that created them
+func (p *KeyPool) Lookup(id string) (APIKey, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, key := range p.keys {
+		if key.ID == id {
+			return key.APIKey, true
+		}
+	}
+	return APIKey{}, false
+}
+
+// Any returns an enabled key without counting it as a use (e.g. for health checks)
+func (p *KeyPool) Any() (APIKey, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range p.keys {
+		if !key.disabledUntil.After(now) {
+			return key.APIKey, true
+		}
+	}
+	return APIKey{}, false
+}
+
+// Disable takes a key out of rotation for the pool's cool-down period
+func (p *KeyPool) Disable(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, key := range p.keys {
+		if key.ID == id {
+			key.disabledUntil = time.Now().Add(p.cooldown)
+			return
+		}
+	}
+}
+
+// earliestReenable returns how long until the first disabled key is back in rotation
+func (p *KeyPool) earliestReenable(now time.Time) time.Duration {
+	var earliest time.Duration
+	for _, key := range p.keys {
+		wait := key.disabledUntil.Sub(now)
+		if earliest == 0 || wait < earliest {
+			earliest = wait
+		}
+	}
+	return earliest
+}
+
+// keyID derives a stable identifier from a secret without revealing it
+func keyID(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return "key_" + hex.EncodeToString(sum[:6])
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/arabella/ai-studio-backend/internal/domain/entity"
@@ -12,26 +15,76 @@ import (
 	"go.uber.org/zap"
 )
 
-// MockProvider implements a mock AI provider for development
+// MockScenario scripts how the mock provider behaves for a job
+type MockScenario string
+
+const (
+	MockScenarioSuccess         MockScenario = "success"          // Completes normally
+	MockScenarioFailSubmit      MockScenario = "fail_submit"      // GenerateVideo returns an error
+	MockScenarioFailProgress    MockScenario = "fail_progress"    // Reports FAILED halfway through
+	MockScenarioStall           MockScenario = "stall"            // Never gets past the first stage
+	MockScenarioRateLimit       MockScenario = "rate_limit"       // GenerateVideo is throttled
+	MockScenarioProgressErrors  MockScenario = "progress_errors"  // Every progress call errors
+	MockScenarioMalformedResult MockScenario = "malformed_result" // Completes with an unusable result
+)
+
+// mockScenarios lists the scenarios the mock provider accepts
+var mockScenarios = map[MockScenario]bool{
+	MockScenarioSuccess:         true,
+	MockScenarioFailSubmit:      true,
+	MockScenarioFailProgress:    true,
+	MockScenarioStall:           true,
+	MockScenarioRateLimit:       true,
+	MockScenarioProgressErrors:  true,
+	MockScenarioMalformedResult: true,
+}
+
+// mockScenarioPattern matches a scenario keyword in a prompt or template tag, e.g. "mock:stall"
+var mockScenarioPattern = regexp.MustCompile(`\bmock:([a-z_]+)`)
+
+const (
+	// defaultMockCompletionTime is how long a simulated job takes when no completion time is configured
+	defaultMockCompletionTime = 30 * time.Second
+
+	// mockRateLimitRetryAfter is the retry hint returned by the rate_limit scenario
+	mockRateLimitRetryAfter = 10 * time.Second
+
+	// mockStallProgress is where stalled jobs stop
+	mockStallProgress = 10
+)
+
+// MockProvider implements a mock AI provider for development.
+// Scenarios are chosen per job, in order of precedence, by the X-Mock-Scenario header (carried with
+// the queued job), a "mock:<scenario>" template tag or a "mock:<scenario>" keyword in the prompt.
 type MockProvider struct {
-	logger       *zap.Logger
-	jobs         map[string]*mockJob
-	simulateTime bool
+	logger         *zap.Logger
+	simulateTime   bool
+	completionTime time.Duration
+	sampleVideoURL string // Video returned for every completed job
+
+	mu   sync.Mutex
+	jobs map[string]*mockJob
 }
 
 type mockJob struct {
 	id        string
-	progress  int
-	status    string
+	scenario  MockScenario
 	startTime time.Time
+	params    entity.VideoParams
 }
 
-// NewMockProvider creates a new MockProvider
-func NewMockProvider(logger *zap.Logger, simulateTime bool) service.VideoProvider {
+// NewMockProvider creates a new MockProvider. With simulateTime, successful jobs complete after completionTime;
+// otherwise they complete on submission. Failure scenarios always run asynchronously.
+func NewMockProvider(logger *zap.Logger, simulateTime bool, completionTime time.Duration, sampleVideoURL string) service.VideoProvider {
+	if completionTime <= 0 {
+		completionTime = defaultMockCompletionTime
+	}
 	return &MockProvider{
-		logger:       logger,
-		jobs:         make(map[string]*mockJob),
-		simulateTime: simulateTime,
+		logger:         logger,
+		jobs:           make(map[string]*mockJob),
+		simulateTime:   simulateTime,
+		completionTime: completionTime,
+		sampleVideoURL: sampleVideoURL,
 	}
 }
 
@@ -42,51 +95,80 @@ func (p *MockProvider) GetName() entity.AIProvider {
 
 // GenerateVideo initiates mock video generation
 func (p *MockProvider) GenerateVideo(ctx context.Context, req service.GenerationRequest) (*entity.GenerationResult, error) {
-	jobID := uuid.New().String()
+	scenario := p.scenarioFor(req)
 
-	p.jobs[jobID] = &mockJob{
+	switch scenario {
+	case MockScenarioFailSubmit:
+		return nil, fmt.Errorf("mock: simulated submission failure")
+	case MockScenarioRateLimit:
+		return nil, &service.RateLimitError{
+			Provider:   entity.ProviderMock,
+			Operation:  service.ProviderOperationSubmit,
+			RetryAfter: mockRateLimitRetryAfter,
+		}
+	}
+
+	jobID := uuid.New().String()
+	seed := seedFor(req.Params)
+	job := &mockJob{
 		id:        jobID,
-		progress:  0,
-		status:    "processing",
+		scenario:  scenario,
 		startTime: time.Now(),
+		params:    req.Params,
 	}
 
+	p.mu.Lock()
+	p.jobs[jobID] = job
+	p.mu.Unlock()
+
 	p.logger.Info("Mock video generation started",
 		zap.String("job_id", jobID),
+		zap.String("scenario", string(scenario)),
 		zap.String("prompt", req.Prompt),
 	)
 
 	// Simulate instant completion for fast development
-	if !p.simulateTime {
+	if !p.simulateTime && scenario == MockScenarioSuccess {
 		return &entity.GenerationResult{
 			ProviderJobID: jobID,
-			VideoURL:      fmt.Sprintf("https://cdn.arabella.app/videos/%s.mp4", jobID),
-			ThumbnailURL:  fmt.Sprintf("https://cdn.arabella.app/thumbnails/%s.jpg", jobID),
+			VideoURL:      p.sampleVideoURL,
 			Duration:      req.Params.Duration,
+			Seed:          &seed,
 		}, nil
 	}
 
 	// Return job ID for async processing
 	return &entity.GenerationResult{
 		ProviderJobID: jobID,
+		Seed:          &seed,
 	}, nil
 }
 
 // GetProgress retrieves generation progress
 func (p *MockProvider) GetProgress(ctx context.Context, providerJobID string) (*entity.Progress, error) {
-	job, ok := p.jobs[providerJobID]
+	job, ok := p.getJob(providerJobID)
 	if !ok {
 		return nil, entity.ErrJobNotFound
 	}
 
-	// Simulate progress
-	elapsed := time.Since(job.startTime)
-	progress := int(elapsed.Seconds() / 30 * 100) // 30 seconds to complete
-	if progress > 100 {
-		progress = 100
-	}
+	progress := p.progressOf(job)
 
-	job.progress = progress
+	switch job.scenario {
+	case MockScenarioProgressErrors:
+		return nil, fmt.Errorf("mock: simulated progress error")
+	case MockScenarioStall:
+		if progress > mockStallProgress {
+			progress = mockStallProgress
+		}
+	case MockScenarioFailProgress:
+		if progress >= 50 {
+			return &entity.Progress{
+				Percent: progress,
+				Stage:   "FAILED",
+				Message: "Mock: simulated generation failure",
+			}, nil
+		}
+	}
 
 	stage := "PROCESSING"
 	if progress > 30 && progress < 80 {
@@ -104,13 +186,46 @@ func (p *MockProvider) GetProgress(ctx context.Context, providerJobID string) (*
 	}, nil
 }
 
+// GetResult returns the sample video for a completed mock job
+func (p *MockProvider) GetResult(ctx context.Context, providerJobID string) (*entity.VideoResult, error) {
+	job, ok := p.getJob(providerJobID)
+	if !ok {
+		return nil, entity.ErrJobNotFound
+	}
+
+	if job.scenario != MockScenarioSuccess && job.scenario != MockScenarioMalformedResult {
+		return nil, fmt.Errorf("mock job %s has no result in scenario %s", providerJobID, job.scenario)
+	}
+
+	if p.progressOf(job) < 100 && (p.simulateTime || job.scenario != MockScenarioSuccess) {
+		return nil, fmt.Errorf("mock job %s not completed", providerJobID)
+	}
+
+	if job.scenario == MockScenarioMalformedResult {
+		return &entity.VideoResult{
+			VideoURL: "mock://malformed",
+			Duration: -1,
+		}, nil
+	}
+
+	return &entity.VideoResult{
+		VideoURL:   p.sampleVideoURL,
+		Duration:   job.params.Duration,
+		Resolution: job.params.Resolution,
+	}, nil
+}
+
 // CancelGeneration cancels an ongoing generation
 func (p *MockProvider) CancelGeneration(ctx context.Context, providerJobID string) error {
-	if _, ok := p.jobs[providerJobID]; !ok {
+	p.mu.Lock()
+	_, ok := p.jobs[providerJobID]
+	delete(p.jobs, providerJobID)
+	p.mu.Unlock()
+
+	if !ok {
 		return entity.ErrJobNotFound
 	}
 
-	delete(p.jobs, providerJobID)
 	p.logger.Info("Mock generation cancelled", zap.String("job_id", providerJobID))
 
 	return nil
@@ -127,6 +242,12 @@ func (p *MockProvider) GetCapabilities() service.ProviderCapabilities {
 		QualityTier:     "standard",
 		SupportsStyles:  true,
 		CostPerSecond:   0.0,
+		// The mock accepts every input and advanced feature so clients can exercise them locally
+		SupportsImageToVideo: true,
+		SupportsSeed:         true,
+		SupportsAudio:        true,
+		SupportsShotType:     true,
+		MaxReferenceImages:   3,
 	}
 }
 
@@ -144,3 +265,46 @@ func (p *MockProvider) HealthCheck(ctx context.Context) (*service.ProviderHealth
 	}, nil
 }
 
+// scenarioFor picks the scenario for a request from its params, template tags or prompt
+func (p *MockProvider) scenarioFor(req service.GenerationRequest) MockScenario {
+	candidates := []string{req.MockScenario}
+	for _, tag := range req.TemplateTags {
+		if match := mockScenarioPattern.FindStringSubmatch(tag); match != nil {
+			candidates = append(candidates, match[1])
+		}
+	}
+	if match := mockScenarioPattern.FindStringSubmatch(req.Prompt); match != nil {
+		candidates = append(candidates, match[1])
+	}
+
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		scenario := MockScenario(strings.ToLower(candidate))
+		if mockScenarios[scenario] {
+			return scenario
+		}
+		p.logger.Warn("Unknown mock scenario, ignoring", zap.String("scenario", candidate))
+	}
+
+	return MockScenarioSuccess
+}
+
+// getJob returns a mock job by ID
+func (p *MockProvider) getJob(id string) (*mockJob, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	job, ok := p.jobs[id]
+	return job, ok
+}
+
+// progressOf returns a job's simulated progress based on elapsed time
+func (p *MockProvider) progressOf(job *mockJob) int {
+	progress := int(time.Since(job.startTime) * 100 / p.completionTime)
+	if progress > 100 {
+		progress = 100
+	}
+	return progress
+}
@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+// normalizeParams fits params to a provider's capabilities and returns the adjustments it had to make.
+// An empty adjustment list means the provider can honour the params as requested.
+func normalizeParams(caps service.ProviderCapabilities, params entity.VideoParams) (entity.VideoParams, []entity.ParamAdjustment) {
+	var adjustments []entity.ParamAdjustment
+
+	if params.Resolution != "" && !supportsResolution(caps.MaxResolution, params.Resolution) {
+		adjustments = append(adjustments, entity.ParamAdjustment{
+			Field:     "resolution",
+			Requested: string(params.Resolution),
+			Applied:   string(caps.MaxResolution),
+			Reason:    fmt.Sprintf("maximum resolution is %s", caps.MaxResolution),
+		})
+		params.Resolution = caps.MaxResolution
+	}
+
+	if params.Duration > 0 {
+		duration := params.Duration
+		reason := ""
+		if len(caps.SupportedDurations) > 0 && !containsInt(caps.SupportedDurations, duration) {
+			duration = nearestInt(caps.SupportedDurations, duration)
+			reason = fmt.Sprintf("supported durations are %s seconds", joinInts(caps.SupportedDurations))
+		} else if caps.MaxDuration > 0 && duration > caps.MaxDuration {
+			duration = caps.MaxDuration
+			reason = fmt.Sprintf("maximum duration is %d seconds", caps.MaxDuration)
+		}
+		if duration != params.Duration {
+			adjustments = append(adjustments, entity.ParamAdjustment{
+				Field:     "duration",
+				Requested: strconv.Itoa(params.Duration),
+				Applied:   strconv.Itoa(duration),
+				Reason:    reason,
+			})
+			params.Duration = duration
+		}
+	}
+
+	if params.AspectRatio != "" && len(caps.SupportedRatios) > 0 && !containsRatio(caps.SupportedRatios, params.AspectRatio) {
+		adjustments = append(adjustments, entity.ParamAdjustment{
+			Field:     "aspect_ratio",
+			Requested: string(params.AspectRatio),
+			Applied:   string(caps.SupportedRatios[0]),
+			Reason:    fmt.Sprintf("supported aspect ratios are %s", joinRatios(caps.SupportedRatios)),
+		})
+		params.AspectRatio = caps.SupportedRatios[0]
+	}
+
+	if params.FPS > 0 && len(caps.SupportedFPS) > 0 && !containsInt(caps.SupportedFPS, params.FPS) {
+		fps := nearestInt(caps.SupportedFPS, params.FPS)
+		adjustments = append(adjustments, entity.ParamAdjustment{
+			Field:     "fps",
+			Requested: strconv.Itoa(params.FPS),
+			Applied:   strconv.Itoa(fps),
+			Reason:    fmt.Sprintf("supported frame rates are %s fps", joinInts(caps.SupportedFPS)),
+		})
+		params.FPS = fps
+	}
+
+	if !params.Advanced.IsEmpty() {
+		advanced, advancedAdjustments := normalizeAdvanced(caps, *params.Advanced)
+		adjustments = append(adjustments, advancedAdjustments...)
+		params.Advanced = &advanced
+		if advanced.IsEmpty() {
+			params.Advanced = nil
+		}
+	}
+
+	return params, adjustments
+}
+
+// normalizeAdvanced drops the advanced features a provider does not support
+func normalizeAdvanced(caps service.ProviderCapabilities, advanced entity.AdvancedParams) (entity.AdvancedParams, []entity.ParamAdjustment) {
+	var adjustments []entity.ParamAdjustment
+	unsupported := func(field, requested string) {
+		adjustments = append(adjustments, entity.ParamAdjustment{
+			Field:     field,
+			Requested: requested,
+			Applied:   "none",
+			Reason:    fmt.Sprintf("%s does not support %s", caps.Name, strings.ReplaceAll(field, "_", " ")),
+		})
+	}
+
+	if advanced.Seed != nil && !caps.SupportsSeed {
+		unsupported("seed", strconv.FormatInt(*advanced.Seed, 10))
+		advanced.Seed = nil
+	}
+	if advanced.Audio != nil && !caps.SupportsAudio {
+		unsupported("audio", strconv.FormatBool(*advanced.Audio))
+		advanced.Audio = nil
+	}
+	if advanced.AudioURL != "" && !caps.SupportsAudio {
+		unsupported("audio_url", advanced.AudioURL)
+		advanced.AudioURL = ""
+	}
+	if advanced.ShotType != "" && !caps.SupportsShotType {
+		unsupported("shot_type", string(advanced.ShotType))
+		advanced.ShotType = ""
+	}
+
+	if len(advanced.ReferenceImages) > caps.MaxReferenceImages {
+		if caps.MaxReferenceImages == 0 {
+			unsupported("reference_images", strconv.Itoa(len(advanced.ReferenceImages)))
+			advanced.ReferenceImages = nil
+		} else {
+			adjustments = append(adjustments, entity.ParamAdjustment{
+				Field:     "reference_images",
+				Requested: strconv.Itoa(len(advanced.ReferenceImages)),
+				Applied:   strconv.Itoa(caps.MaxReferenceImages),
+				Reason:    fmt.Sprintf("maximum reference images is %d", caps.MaxReferenceImages),
+			})
+			advanced.ReferenceImages = advanced.ReferenceImages[:caps.MaxReferenceImages]
+		}
+	}
+
+	return advanced, adjustments
+}
+
+// describeAdjustments summarises adjustments for error messages and selection reasons
+func describeAdjustments(adjustments []entity.ParamAdjustment) string {
+	parts := make([]string, 0, len(adjustments))
+	for _, adj := range adjustments {
+		parts = append(parts, fmt.Sprintf("%s %s -> %s (%s)", adj.Field, adj.Requested, adj.Applied, adj.Reason))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// containsInt reports whether values contains v
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// nearestInt returns the value closest to v, preferring the larger value on ties
+func nearestInt(values []int, v int) int {
+	best := values[0]
+	for _, value := range values[1:] {
+		d, bestD := abs(value-v), abs(best-v)
+		if d < bestD || (d == bestD && value > best) {
+			best = value
+		}
+	}
+	return best
+}
+
+// containsRatio reports whether ratios contains r
+func containsRatio(ratios []entity.AspectRatio, r entity.AspectRatio) bool {
+	for _, ratio := range ratios {
+		if ratio == r {
+			return true
+		}
+	}
+	return false
+}
+
+// joinInts formats values as a comma-separated list
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// joinRatios formats ratios as a comma-separated list
+func joinRatios(ratios []entity.AspectRatio) string {
+	parts := make([]string, len(ratios))
+	for i, r := range ratios {
+		parts[i] = string(r)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -2,7 +2,12 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"math/rand"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/arabella/ai-studio-backend/internal/domain/entity"
@@ -14,22 +19,58 @@ import (
 type BaseProvider struct {
 	httpClient *http.Client
 	logger     *zap.Logger
-	apiKey     string
+	keys       *KeyPool
 	baseURL    string
 }
 
 // NewBaseProvider creates a new BaseProvider
-func NewBaseProvider(apiKey, baseURL string, timeout time.Duration, logger *zap.Logger) *BaseProvider {
+func NewBaseProvider(keys *KeyPool, baseURL string, timeout time.Duration, logger *zap.Logger) *BaseProvider {
 	return &BaseProvider{
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
 		logger:  logger,
-		apiKey:  apiKey,
+		keys:    keys,
 		baseURL: baseURL,
 	}
 }
 
+// apiKeyFor returns the key for a provider call: the job's key when the context carries one,
+// otherwise the next key from the pool
+func (b *BaseProvider) apiKeyFor(ctx context.Context) (APIKey, error) {
+	if id, ok := service.ProviderKeyIDFromContext(ctx); ok {
+		if key, found := b.keys.Lookup(id); found {
+			return key, nil
+		}
+		b.logger.Warn("Job API key is no longer configured, using the pool",
+			zap.String("key_id", id),
+		)
+	}
+	return b.keys.Acquire()
+}
+
+// rejectKey takes a key the provider rejected for auth or quota reasons out of rotation
+func (b *BaseProvider) rejectKey(key APIKey, reason string) {
+	b.keys.Disable(key.ID)
+	b.logger.Warn("Provider API key disabled",
+		zap.String("key_id", key.ID),
+		zap.String("reason", reason),
+	)
+}
+
+// seedFor returns the seed a job requested, or a fresh random seed so every generation can be reproduced
+func seedFor(params entity.VideoParams) int64 {
+	if params.Advanced != nil && params.Advanced.Seed != nil {
+		return *params.Advanced.Seed
+	}
+	return rand.Int63n(entity.MaxSeed + 1)
+}
+
+// SetTransport replaces the transport used for provider API requests (e.g. a record/replay cassette)
+func (b *BaseProvider) SetTransport(transport http.RoundTripper) {
+	b.httpClient.Transport = transport
+}
+
 // ProviderRegistry manages available AI providers
 type ProviderRegistry struct {
 	providers map[entity.AIProvider]service.VideoProvider
@@ -56,55 +97,125 @@ func (r *ProviderRegistry) Get(name entity.AIProvider) (service.VideoProvider, b
 	return provider, ok
 }
 
-// GetAll returns all registered providers
+// GetAll returns all registered providers ordered by name
 func (r *ProviderRegistry) GetAll() []service.VideoProvider {
 	providers := make([]service.VideoProvider, 0, len(r.providers))
 	for _, p := range r.providers {
 		providers = append(providers, p)
 	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].GetName() < providers[j].GetName()
+	})
 	return providers
 }
 
+const (
+	// defaultHealthHistorySize is the number of health samples kept per provider
+	defaultHealthHistorySize = 120
+
+	// healthCheckTimeout bounds a single provider health check during refresh
+	healthCheckTimeout = 10 * time.Second
+)
+
 // ProviderSelectorImpl implements the ProviderSelector interface
 type ProviderSelectorImpl struct {
-	registry    *ProviderRegistry
-	healthCache map[entity.AIProvider]*service.ProviderHealth
-	logger      *zap.Logger
+	registry          *ProviderRegistry
+	healthCache       map[entity.AIProvider]*service.ProviderHealth
+	healthHistory     map[entity.AIProvider][]service.ProviderHealth
+	healthHistorySize int
+	policy            RoutingPolicy
+	spendTracker      service.SpendTracker
+	rateLimiter       service.ProviderRateLimiter
+	mu                sync.RWMutex
+	logger            *zap.Logger
 }
 
 // NewProviderSelector creates a new ProviderSelector
-func NewProviderSelector(registry *ProviderRegistry, logger *zap.Logger) service.ProviderSelector {
+func NewProviderSelector(
+	registry *ProviderRegistry,
+	policy RoutingPolicy,
+	spendTracker service.SpendTracker,
+	rateLimiter service.ProviderRateLimiter,
+	healthHistorySize int,
+	logger *zap.Logger,
+) service.ProviderSelector {
+	if healthHistorySize <= 0 {
+		healthHistorySize = defaultHealthHistorySize
+	}
 	return &ProviderSelectorImpl{
-		registry:    registry,
-		healthCache: make(map[entity.AIProvider]*service.ProviderHealth),
-		logger:      logger,
-	}
-}
-
-// SelectProvider selects the best provider based on requirements
-func (s *ProviderSelectorImpl) SelectProvider(ctx context.Context, req service.ProviderSelectionRequest) (service.VideoProvider, error) {
-	// If a preferred provider is specified, try to use it
-	if req.PreferredProvider != nil {
-		if provider, ok := s.registry.Get(*req.PreferredProvider); ok {
-			health, err := provider.HealthCheck(ctx)
-			// Log health check for debugging
-			s.logger.Info("Preferred provider health check",
-				zap.String("provider", string(*req.PreferredProvider)),
-				zap.Bool("healthy", health != nil && health.IsHealthy),
-				zap.Error(err),
+		registry:          registry,
+		healthCache:       make(map[entity.AIProvider]*service.ProviderHealth),
+		healthHistory:     make(map[entity.AIProvider][]service.ProviderHealth),
+		healthHistorySize: healthHistorySize,
+		policy:            policy,
+		spendTracker:      spendTracker,
+		rateLimiter:       rateLimiter,
+		logger:            logger,
+	}
+}
+
+// providerPreference is a provider choice that takes precedence over the routing policy
+type providerPreference struct {
+	provider   *entity.AIProvider
+	source     string
+	experiment string
+	arm        ExperimentArm
+}
+
+// SelectProvider selects a provider using, in order of precedence, the request override,
+// an experiment arm, the template preference and the routing policy
+func (s *ProviderSelectorImpl) SelectProvider(ctx context.Context, req service.ProviderSelectionRequest) (*service.ProviderSelection, error) {
+	preferences := []providerPreference{
+		{provider: req.RequestedProvider, source: "request override"},
+	}
+	if experiment, arm, ok := s.policy.ExperimentFor(req); ok {
+		preferences = append(preferences, providerPreference{
+			provider:   &arm.Provider,
+			source:     fmt.Sprintf("experiment %s arm %s", experiment.Name, arm.Name),
+			experiment: experiment.Name,
+			arm:        arm,
+		})
+	}
+	preferences = append(preferences, providerPreference{provider: req.TemplateProvider, source: "template preference"})
+
+	// Notes explain why higher-precedence preferences were skipped
+	var notes []string
+	for _, pref := range preferences {
+		if pref.provider == nil || *pref.provider == "" {
+			continue
+		}
+
+		provider, ok := s.registry.Get(*pref.provider)
+		if !ok {
+			notes = append(notes, fmt.Sprintf("%s %s is not registered", pref.source, *pref.provider))
+			continue
+		}
+
+		if reason := s.unusableReason(ctx, provider, req); reason != "" {
+			s.logger.Warn("Preferred provider unusable, failing over",
+				zap.String("provider", string(*pref.provider)),
+				zap.String("source", pref.source),
+				zap.String("reason", reason),
+			)
+			notes = append(notes, fmt.Sprintf("%s %s %s", pref.source, *pref.provider, reason))
+			continue
+		}
+
+		// Allow provider even if it is unhealthy (for testing)
+		// In production, you might want to require a healthy status
+		if health, known := s.cachedHealth(provider.GetName()); known && !health.IsHealthy {
+			s.logger.Warn("Preferred provider unhealthy, but using it anyway",
+				zap.String("provider", string(*pref.provider)),
 			)
-			// Allow provider even if health check fails (for testing)
-			// In production, you might want to require health check
-			if health != nil && health.IsHealthy {
-				return provider, nil
-			} else if health == nil || err != nil {
-				// If health check fails but provider exists, still use it (for testing)
-				s.logger.Warn("Preferred provider health check failed, but using it anyway",
-					zap.String("provider", string(*req.PreferredProvider)),
-				)
-				return provider, nil
-			}
 		}
+
+		return &service.ProviderSelection{
+			Provider:   provider,
+			Reason:     withNotes(fmt.Sprintf("%s: %s", pref.source, *pref.provider), notes),
+			Experiment: pref.experiment,
+			Arm:        pref.arm.Name,
+			Model:      pref.arm.Model,
+		}, nil
 	}
 
 	// Get all available providers
@@ -115,6 +226,8 @@ func (s *ProviderSelectorImpl) SelectProvider(ctx context.Context, req service.P
 
 	// Filter by user tier
 	var eligible []service.VideoProvider
+	overBudget := 0
+	overQuota := 0
 	for _, provider := range providers {
 		caps := provider.GetCapabilities()
 
@@ -127,68 +240,200 @@ func (s *ProviderSelectorImpl) SelectProvider(ctx context.Context, req service.P
 		// 	}
 		// }
 
-		// Check resolution requirements
-		if !supportsResolution(caps.MaxResolution, req.RequiredResolution) {
+		// Check the provider can animate the user's start image
+		if req.RequiresImageInput && !caps.SupportsImageToVideo {
 			continue
 		}
 
-		// Check duration requirements
-		if caps.MaxDuration < req.RequiredDuration {
+		// Check the provider can synchronise to the user's soundtrack
+		if req.RequiresAudioInput && !caps.SupportsAudio {
 			continue
 		}
 
-		// Check health (more lenient for testing)
-		health, err := provider.HealthCheck(ctx)
-		// Only skip if health check explicitly fails AND we have other options
-		// For testing, we'll allow providers even if health check has issues
-		if err != nil {
-			s.logger.Warn("Provider health check error, but allowing it",
-				zap.String("provider", string(provider.GetName())),
-				zap.Error(err),
-			)
-			// Continue to next provider only if we have other options
-		} else if health != nil && !health.IsHealthy {
+		// Check the provider can honour the requested params without adjustments
+		if _, adjustments := normalizeParams(caps, requestedParams(req)); len(adjustments) > 0 {
+			continue
+		}
+
+		// Check cached health (more lenient for testing)
+		// Providers the monitor has not checked yet are treated as healthy
+		health, known := s.cachedHealth(provider.GetName())
+		if known && !health.IsHealthy {
 			s.logger.Warn("Provider unhealthy, but allowing it for testing",
 				zap.String("provider", string(provider.GetName())),
 			)
-			// For testing, allow unhealthy providers if it's the preferred one
-			if req.PreferredProvider != nil && provider.GetName() == *req.PreferredProvider {
-				// Allow preferred provider even if unhealthy
-			} else if len(providers) > 1 {
-				// Skip only if we have other providers
+			// Skip only if we have other providers
+			if len(providers) > 1 {
 				continue
 			}
 		}
 
+		// Check spend caps
+		if s.overBudget(ctx, provider, req) {
+			s.logger.Warn("Provider over budget, skipping",
+				zap.String("provider", string(provider.GetName())),
+			)
+			overBudget++
+			continue
+		}
+
+		// Check the daily quota so jobs move to another provider instead of failing at submission
+		if s.quotaExhausted(ctx, provider) {
+			s.logger.Warn("Provider daily quota used, skipping",
+				zap.String("provider", string(provider.GetName())),
+			)
+			overQuota++
+			continue
+		}
+
 		eligible = append(eligible, provider)
 	}
 
 	if len(eligible) == 0 {
+		if overBudget > 0 {
+			return nil, entity.ErrProviderBudgetExceeded
+		}
+		if overQuota > 0 {
+			return nil, entity.ErrProviderQuotaExceeded
+		}
 		return nil, entity.ErrProviderUnavailable
 	}
 
-	// Select the best provider based on queue depth and quality
-	best := eligible[0]
-	bestScore := scoreProvider(eligible[0], req.UserTier)
+	// The mock provider is only a last resort when no real provider is eligible
+	if real := withoutMock(eligible); len(real) > 0 {
+		eligible = real
+	}
+
+	// Select the best provider by weighing cost, quality and latency for the user's tier
+	scores := scoreProviders(eligible, req, s.policy.WeightsFor(req.UserTier))
 
+	best := eligible[0]
 	for _, provider := range eligible[1:] {
-		score := scoreProvider(provider, req.UserTier)
-		if score > bestScore {
+		if scores[provider.GetName()] > scores[best.GetName()] {
 			best = provider
-			bestScore = score
 		}
 	}
 
+	s.logger.Info("Provider selected by routing policy",
+		zap.String("provider", string(best.GetName())),
+		zap.Float64("score", scores[best.GetName()]),
+		zap.String("user_tier", string(req.UserTier)),
+	)
+
+	return &service.ProviderSelection{
+		Provider: best,
+		Reason:   withNotes(fmt.Sprintf("routing policy: %s (score %.2f)", best.GetName(), scores[best.GetName()]), notes),
+	}, nil
+}
+
+// IsRegistered reports whether a provider with the given name is registered
+func (s *ProviderSelectorImpl) IsRegistered(name entity.AIProvider) bool {
+	_, ok := s.registry.Get(name)
+	return ok
+}
+
+// NormalizeParams checks params against provider capabilities. When no candidate provider can honour
+// them as requested, the params are fitted to the provider needing the fewest adjustments.
+func (s *ProviderSelectorImpl) NormalizeParams(ctx context.Context, req service.ProviderSelectionRequest, params entity.VideoParams) (*service.ParamNormalization, error) {
+	var candidates []service.VideoProvider
+	if req.RequestedProvider != nil {
+		// An explicit override is only ever served by that provider
+		provider, ok := s.registry.Get(*req.RequestedProvider)
+		if !ok {
+			return nil, entity.ErrUnknownProvider
+		}
+		if req.RequiresImageInput && !provider.GetCapabilities().SupportsImageToVideo {
+			return nil, fmt.Errorf("%w: %s cannot animate a start image", entity.ErrStartImageNotSupported, provider.GetName())
+		}
+		if req.RequiresAudioInput && !provider.GetCapabilities().SupportsAudio {
+			return nil, fmt.Errorf("%w: %s cannot use a soundtrack", entity.ErrAudioNotSupported, provider.GetName())
+		}
+		candidates = []service.VideoProvider{provider}
+	} else {
+		candidates = s.registry.GetAll()
+		if real := withoutMock(candidates); len(real) > 0 {
+			candidates = real
+		}
+		if req.RequiresImageInput {
+			candidates = withImageToVideo(candidates)
+			if len(candidates) == 0 {
+				return nil, fmt.Errorf("%w: no available provider can animate a start image", entity.ErrStartImageNotSupported)
+			}
+		}
+		if req.RequiresAudioInput {
+			candidates = withAudioInput(candidates)
+			if len(candidates) == 0 {
+				return nil, fmt.Errorf("%w: no available provider can use a soundtrack", entity.ErrAudioNotSupported)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, entity.ErrProviderUnavailable
+	}
+
+	var best *service.ParamNormalization
+	for _, provider := range candidates {
+		normalized, adjustments := normalizeParams(provider.GetCapabilities(), params)
+		if len(adjustments) == 0 {
+			return &service.ParamNormalization{Params: params}, nil
+		}
+
+		// Prefer the fewest adjustments, then the template's preferred provider
+		preferred := req.TemplateProvider != nil && provider.GetName() == *req.TemplateProvider
+		if best == nil || len(adjustments) < len(best.Adjustments) ||
+			(len(adjustments) == len(best.Adjustments) && preferred) {
+			best = &service.ParamNormalization{
+				Params:      normalized,
+				Provider:    provider.GetName(),
+				Adjustments: adjustments,
+				Explanation: fmt.Sprintf("%s would change %s", provider.GetName(), describeAdjustments(adjustments)),
+			}
+		}
+	}
+
+	s.logger.Info("Requested params adjusted to fit provider capabilities",
+		zap.String("provider", string(best.Provider)),
+		zap.String("adjustments", best.Explanation),
+	)
+
 	return best, nil
 }
 
+// unusableReason explains why a preferred provider cannot serve a request, or returns "" if it can
+func (s *ProviderSelectorImpl) unusableReason(ctx context.Context, provider service.VideoProvider, req service.ProviderSelectionRequest) string {
+	if req.RequiresImageInput && !provider.GetCapabilities().SupportsImageToVideo {
+		return "does not support image-to-video"
+	}
+	if req.RequiresAudioInput && !provider.GetCapabilities().SupportsAudio {
+		return "does not support audio input"
+	}
+	if _, adjustments := normalizeParams(provider.GetCapabilities(), requestedParams(req)); len(adjustments) > 0 {
+		return "cannot honour " + describeAdjustments(adjustments)
+	}
+	if s.overBudget(ctx, provider, req) {
+		return "is over budget"
+	}
+	if s.quotaExhausted(ctx, provider) {
+		return "has used its daily quota"
+	}
+	return ""
+}
+
+// withNotes appends failover notes to a selection reason
+func withNotes(reason string, notes []string) string {
+	if len(notes) == 0 {
+		return reason
+	}
+	return reason + " (" + strings.Join(notes, "; ") + ")"
+}
+
 // GetAvailableProviders returns all available providers
 func (s *ProviderSelectorImpl) GetAvailableProviders(ctx context.Context) ([]service.VideoProvider, error) {
 	var available []service.VideoProvider
 
 	for _, provider := range s.registry.GetAll() {
-		health, err := provider.HealthCheck(ctx)
-		if err == nil && health.IsHealthy {
+		health, known := s.cachedHealth(provider.GetName())
+		if !known || health.IsHealthy {
 			available = append(available, provider)
 		}
 	}
@@ -198,13 +443,182 @@ func (s *ProviderSelectorImpl) GetAvailableProviders(ctx context.Context) ([]ser
 
 // RefreshHealth refreshes health status for all providers
 func (s *ProviderSelectorImpl) RefreshHealth(ctx context.Context) error {
+	var wg sync.WaitGroup
 	for _, provider := range s.registry.GetAll() {
-		health, _ := provider.HealthCheck(ctx)
-		s.healthCache[provider.GetName()] = health
+		wg.Add(1)
+		go func(provider service.VideoProvider) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			health, err := provider.HealthCheck(checkCtx)
+			if err != nil || health == nil {
+				s.logger.Warn("Provider health check failed",
+					zap.String("provider", string(provider.GetName())),
+					zap.Error(err),
+				)
+				health = &service.ProviderHealth{
+					IsHealthy:    false,
+					ResponseTime: time.Since(start).Milliseconds(),
+					ErrorRate:    1.0,
+					LastChecked:  time.Now().Unix(),
+				}
+			}
+
+			s.recordHealth(provider.GetName(), health)
+		}(provider)
 	}
+	wg.Wait()
+
 	return nil
 }
 
+// GetHealthReport returns cached current and historical health for all providers
+func (s *ProviderSelectorImpl) GetHealthReport(ctx context.Context) ([]service.ProviderHealthReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	providers := s.registry.GetAll()
+	reports := make([]service.ProviderHealthReport, 0, len(providers))
+	for _, provider := range providers {
+		name := provider.GetName()
+		report := service.ProviderHealthReport{
+			Provider: name,
+			History:  append([]service.ProviderHealth{}, s.healthHistory[name]...),
+		}
+		if health, ok := s.healthCache[name]; ok {
+			current := *health
+			report.Current = &current
+		}
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Provider < reports[j].Provider
+	})
+
+	return reports, nil
+}
+
+// RecordSpend records the estimated cost of a submitted generation against the provider budget
+func (s *ProviderSelectorImpl) RecordSpend(ctx context.Context, provider service.VideoProvider, req service.ProviderSelectionRequest) error {
+	if s.spendTracker == nil {
+		return nil
+	}
+
+	cost := EstimateCost(provider.GetCapabilities(), req.RequiredDuration, req.RequiredResolution)
+	if cost <= 0 {
+		return nil
+	}
+
+	return s.spendTracker.AddSpend(ctx, provider.GetName(), cost)
+}
+
+// GetSpendReport returns current spend and caps for all providers
+func (s *ProviderSelectorImpl) GetSpendReport(ctx context.Context) ([]service.ProviderSpendReport, error) {
+	providers := s.registry.GetAll()
+	reports := make([]service.ProviderSpendReport, 0, len(providers))
+	for _, provider := range providers {
+		name := provider.GetName()
+		budget := s.policy.BudgetFor(name)
+		report := service.ProviderSpendReport{
+			Provider:     name,
+			DailyLimit:   budget.DailyLimit,
+			MonthlyLimit: budget.MonthlyLimit,
+		}
+
+		if s.spendTracker != nil {
+			daily, monthly, err := s.spendTracker.GetSpend(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+			report.DailySpend = daily
+			report.MonthlySpend = monthly
+		}
+
+		report.OverBudget = (budget.DailyLimit > 0 && report.DailySpend >= budget.DailyLimit) ||
+			(budget.MonthlyLimit > 0 && report.MonthlySpend >= budget.MonthlyLimit)
+
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Provider < reports[j].Provider
+	})
+
+	return reports, nil
+}
+
+// overBudget checks whether submitting the request would exceed the provider's spend caps
+func (s *ProviderSelectorImpl) overBudget(ctx context.Context, provider service.VideoProvider, req service.ProviderSelectionRequest) bool {
+	budget := s.policy.BudgetFor(provider.GetName())
+	if s.spendTracker == nil || (budget.DailyLimit <= 0 && budget.MonthlyLimit <= 0) {
+		return false
+	}
+
+	daily, monthly, err := s.spendTracker.GetSpend(ctx, provider.GetName())
+	if err != nil {
+		// Don't block generation because spend tracking is unavailable
+		s.logger.Warn("Failed to get provider spend",
+			zap.String("provider", string(provider.GetName())),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	cost := EstimateCost(provider.GetCapabilities(), req.RequiredDuration, req.RequiredResolution)
+	if budget.DailyLimit > 0 && daily+cost > budget.DailyLimit {
+		return true
+	}
+	if budget.MonthlyLimit > 0 && monthly+cost > budget.MonthlyLimit {
+		return true
+	}
+	return false
+}
+
+// quotaExhausted checks whether the provider has used its daily submission quota
+func (s *ProviderSelectorImpl) quotaExhausted(ctx context.Context, provider service.VideoProvider) bool {
+	if s.rateLimiter == nil {
+		return false
+	}
+
+	exhausted, err := s.rateLimiter.QuotaExhausted(ctx, provider.GetName())
+	if err != nil {
+		// Don't block generation because quota tracking is unavailable
+		s.logger.Warn("Failed to get provider quota",
+			zap.String("provider", string(provider.GetName())),
+			zap.Error(err),
+		)
+		return false
+	}
+	return exhausted
+}
+
+// cachedHealth returns the last known health of a provider
+func (s *ProviderSelectorImpl) cachedHealth(name entity.AIProvider) (*service.ProviderHealth, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	health, ok := s.healthCache[name]
+	return health, ok
+}
+
+// recordHealth stores a health sample as current and appends it to the history
+func (s *ProviderSelectorImpl) recordHealth(name entity.AIProvider, health *service.ProviderHealth) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.healthCache[name] = health
+
+	history := append(s.healthHistory[name], *health)
+	if len(history) > s.healthHistorySize {
+		history = history[len(history)-s.healthHistorySize:]
+	}
+	s.healthHistory[name] = history
+}
+
 // supportsResolution checks if a provider supports a required resolution
 func supportsResolution(max, required entity.VideoResolution) bool {
 	resolutionOrder := map[entity.VideoResolution]int{
@@ -216,35 +630,46 @@ func supportsResolution(max, required entity.VideoResolution) bool {
 	return resolutionOrder[max] >= resolutionOrder[required]
 }
 
-// scoreProvider calculates a score for provider selection
-func scoreProvider(provider service.VideoProvider, userTier entity.UserTier) int {
-	caps := provider.GetCapabilities()
-	score := 0
-
-	// Prefer Wan AI (highest priority) - much higher than others
-	if provider.GetName() == entity.ProviderWanAI {
-		score += 1000 // Very high priority
+// requestedParams returns the capability-relevant params of a selection request
+func requestedParams(req service.ProviderSelectionRequest) entity.VideoParams {
+	return entity.VideoParams{
+		Duration:    req.RequiredDuration,
+		Resolution:  req.RequiredResolution,
+		AspectRatio: req.AspectRatio,
+		FPS:         req.RequiredFPS,
+		Advanced:    req.Advanced,
 	}
+}
 
-	// Penalize mock provider (lowest priority)
-	if provider.GetName() == entity.ProviderMock {
-		score -= 500 // Very low priority
+// withImageToVideo returns the providers that can animate a start image
+func withImageToVideo(providers []service.VideoProvider) []service.VideoProvider {
+	var capable []service.VideoProvider
+	for _, provider := range providers {
+		if provider.GetCapabilities().SupportsImageToVideo {
+			capable = append(capable, provider)
+		}
 	}
+	return capable
+}
 
-	// Higher quality = higher score for premium users
-	switch caps.QualityTier {
-	case "premium":
-		if userTier != entity.UserTierFree {
-			score += 30
+// withAudioInput returns the providers that can synchronise a video to a supplied soundtrack
+func withAudioInput(providers []service.VideoProvider) []service.VideoProvider {
+	var capable []service.VideoProvider
+	for _, provider := range providers {
+		if provider.GetCapabilities().SupportsAudio {
+			capable = append(capable, provider)
 		}
-	case "standard":
-		score += 20
-	case "budget":
-		score += 10
 	}
+	return capable
+}
 
-	// Lower estimated time = higher score
-	score += 100 - caps.EstimatedTime
-
-	return score
+// withoutMock returns the providers excluding the mock provider
+func withoutMock(providers []service.VideoProvider) []service.VideoProvider {
+	var real []service.VideoProvider
+	for _, provider := range providers {
+		if provider.GetName() != entity.ProviderMock {
+			real = append(real, provider)
+		}
+	}
+	return real
 }
@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"context"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+	"go.uber.org/zap"
+)
+
+// RateLimitedProvider throttles outbound calls to a provider and tracks its daily quota.
+// Submissions are rejected with a RateLimitError when throttled so the job can be delayed;
+// status calls wait for a token instead.
+type RateLimitedProvider struct {
+	service.VideoProvider
+	limiter service.ProviderRateLimiter
+	logger  *zap.Logger
+}
+
+// NewRateLimitedProvider wraps a provider with outbound rate limiting
+func NewRateLimitedProvider(provider service.VideoProvider, limiter service.ProviderRateLimiter, logger *zap.Logger) *RateLimitedProvider {
+	return &RateLimitedProvider{
+		VideoProvider: provider,
+		limiter:       limiter,
+		logger:        logger,
+	}
+}
+
+// GenerateVideo submits a generation if a submit token and quota are available
+func (p *RateLimitedProvider) GenerateVideo(ctx context.Context, req service.GenerationRequest) (*entity.GenerationResult, error) {
+	name := p.GetName()
+
+	exhausted, err := p.limiter.QuotaExhausted(ctx, name)
+	if err != nil {
+		p.logLimiterError("quota check", err)
+	} else if exhausted {
+		return nil, entity.ErrProviderQuotaExceeded
+	}
+
+	wait, err := p.limiter.Reserve(ctx, name, service.ProviderOperationSubmit)
+	if err != nil {
+		p.logLimiterError("submit reservation", err)
+	} else if wait > 0 {
+		return nil, &service.RateLimitError{
+			Provider:   name,
+			Operation:  service.ProviderOperationSubmit,
+			RetryAfter: wait,
+		}
+	}
+
+	result, err := p.VideoProvider.GenerateVideo(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.limiter.ConsumeQuota(ctx, name); err != nil {
+		p.logLimiterError("quota update", err)
+	}
+
+	return result, nil
+}
+
+// GetProgress retrieves generation progress once a status token is available
+func (p *RateLimitedProvider) GetProgress(ctx context.Context, providerJobID string) (*entity.Progress, error) {
+	if err := p.waitForStatus(ctx); err != nil {
+		return nil, err
+	}
+	return p.VideoProvider.GetProgress(ctx, providerJobID)
+}
+
+// GetResult retrieves the generation result once a status token is available
+func (p *RateLimitedProvider) GetResult(ctx context.Context, providerJobID string) (*entity.VideoResult, error) {
+	if err := p.waitForStatus(ctx); err != nil {
+		return nil, err
+	}
+	return p.VideoProvider.GetResult(ctx, providerJobID)
+}
+
+// CancelGeneration cancels a generation once a status token is available
+func (p *RateLimitedProvider) CancelGeneration(ctx context.Context, providerJobID string) error {
+	if err := p.waitForStatus(ctx); err != nil {
+		return err
+	}
+	return p.VideoProvider.CancelGeneration(ctx, providerJobID)
+}
+
+// waitForStatus blocks until a status token is available or the context is done
+func (p *RateLimitedProvider) waitForStatus(ctx context.Context) error {
+	for {
+		wait, err := p.limiter.Reserve(ctx, p.GetName(), service.ProviderOperationStatus)
+		if err != nil {
+			// Don't block polling because the limiter is unavailable
+			p.logLimiterError("status reservation", err)
+			return nil
+		}
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// logLimiterError logs a limiter failure; calls are allowed through when the limiter is unavailable
+func (p *RateLimitedProvider) logLimiterError(action string, err error) {
+	p.logger.Warn("Provider rate limiter unavailable, allowing call",
+		zap.String("provider", string(p.GetName())),
+		zap.String("action", action),
+		zap.Error(err),
+	)
+}
+
+// ApplyRateLimits wraps every registered provider with outbound rate limiting.
+// It must run after AttachCassettes, which needs the unwrapped providers.
+func ApplyRateLimits(registry *ProviderRegistry, limiter service.ProviderRateLimiter, logger *zap.Logger) {
+	for name, p := range registry.providers {
+		if name == entity.ProviderMock {
+			continue
+		}
+		registry.providers[name] = NewRateLimitedProvider(p, limiter, logger)
+	}
+}
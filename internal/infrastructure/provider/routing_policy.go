@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+// RoutingWeights controls how cost, quality and latency are traded off when scoring providers
+type RoutingWeights struct {
+	Cost    float64
+	Quality float64
+	Latency float64
+}
+
+// ProviderBudget defines spend caps for a provider (zero means unlimited)
+type ProviderBudget struct {
+	DailyLimit   float64
+	MonthlyLimit float64
+}
+
+// RoutingPolicy configures cost-aware provider routing
+type RoutingPolicy struct {
+	TierWeights map[entity.UserTier]RoutingWeights
+	Budgets     map[entity.AIProvider]ProviderBudget
+	Experiments []Experiment // Weighted traffic splits, checked in order
+}
+
+// DefaultRoutingPolicy returns the default routing policy
+func DefaultRoutingPolicy() RoutingPolicy {
+	return RoutingPolicy{
+		TierWeights: map[entity.UserTier]RoutingWeights{
+			entity.UserTierFree:    {Cost: 0.6, Quality: 0.2, Latency: 0.2},
+			entity.UserTierPremium: {Cost: 0.3, Quality: 0.4, Latency: 0.3},
+			entity.UserTierPro:     {Cost: 0.1, Quality: 0.6, Latency: 0.3},
+		},
+		Budgets: map[entity.AIProvider]ProviderBudget{},
+	}
+}
+
+// WeightsFor returns the routing weights for a user tier
+func (p RoutingPolicy) WeightsFor(tier entity.UserTier) RoutingWeights {
+	if weights, ok := p.TierWeights[tier]; ok {
+		return weights
+	}
+	if weights, ok := DefaultRoutingPolicy().TierWeights[tier]; ok {
+		return weights
+	}
+	return RoutingWeights{Cost: 1.0 / 3, Quality: 1.0 / 3, Latency: 1.0 / 3}
+}
+
+// BudgetFor returns the spend caps for a provider
+func (p RoutingPolicy) BudgetFor(provider entity.AIProvider) ProviderBudget {
+	return p.Budgets[provider]
+}
+
+// EstimateCost estimates the cost of a generation in USD
+// (duration × cost per second × resolution multiplier)
+func EstimateCost(caps service.ProviderCapabilities, duration int, resolution entity.VideoResolution) float64 {
+	if duration <= 0 {
+		duration = entity.DefaultVideoParams().Duration
+	}
+	return float64(duration) * caps.CostPerSecond * resolutionCostMultiplier(resolution)
+}
+
+// resolutionCostMultiplier returns the relative cost of rendering at a resolution
+func resolutionCostMultiplier(resolution entity.VideoResolution) float64 {
+	switch resolution {
+	case entity.Resolution1080p:
+		return 1.5
+	case entity.Resolution4K:
+		return 3.0
+	default:
+		return 1.0
+	}
+}
+
+// qualityScore maps a provider quality tier to a 0-1 score
+func qualityScore(tier string) float64 {
+	switch tier {
+	case "premium":
+		return 1.0
+	case "standard":
+		return 0.66
+	case "budget":
+		return 0.33
+	default:
+		return 0.0
+	}
+}
+
+// estimatedLatency estimates generation time in seconds for a request
+func estimatedLatency(caps service.ProviderCapabilities, duration int) float64 {
+	if duration <= 0 {
+		duration = entity.DefaultVideoParams().Duration
+	}
+	return float64(caps.EstimatedTime * duration)
+}
+
+// scoreProviders scores eligible providers against each other using the tier's routing weights.
+// Cost and latency are normalised against the most expensive and slowest candidate.
+func scoreProviders(eligible []service.VideoProvider, req service.ProviderSelectionRequest, weights RoutingWeights) map[entity.AIProvider]float64 {
+	var maxCost, maxLatency float64
+	for _, provider := range eligible {
+		caps := provider.GetCapabilities()
+		if cost := EstimateCost(caps, req.RequiredDuration, req.RequiredResolution); cost > maxCost {
+			maxCost = cost
+		}
+		if latency := estimatedLatency(caps, req.RequiredDuration); latency > maxLatency {
+			maxLatency = latency
+		}
+	}
+
+	scores := make(map[entity.AIProvider]float64, len(eligible))
+	for _, provider := range eligible {
+		caps := provider.GetCapabilities()
+
+		costScore := 1.0
+		if maxCost > 0 {
+			costScore = 1 - EstimateCost(caps, req.RequiredDuration, req.RequiredResolution)/maxCost
+		}
+
+		latencyScore := 1.0
+		if maxLatency > 0 {
+			latencyScore = 1 - estimatedLatency(caps, req.RequiredDuration)/maxLatency
+		}
+
+		scores[provider.GetName()] = weights.Cost*costScore +
+			weights.Quality*qualityScore(caps.QualityTier) +
+			weights.Latency*latencyScore
+	}
+
+	return scores
+}
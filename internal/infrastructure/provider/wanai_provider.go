@@ -3,15 +3,10 @@ package provider
 import (
 	"bytes"
 	"context"
-	"crypto/md5"
-	"crypto/tls"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -24,6 +19,9 @@ import (
 // For Beijing region, use: https://dashscope.aliyuncs.com/compatible-mode/v1
 const (
 	defaultWanaiBaseURL = "https://dashscope-intl.aliyuncs.com/compatible-mode/v1"
+
+	// dashScopeResultTTL is how long DashScope keeps generated videos available
+	dashScopeResultTTL = 24 * time.Hour
 )
 
 // WanAIProvider implements the Wan AI video generation provider
@@ -31,6 +29,12 @@ type WanAIProvider struct {
 	*BaseProvider
 	version       string
 	serverBaseURL string // Base URL for the API server (for proxy endpoint)
+	images        ImageCache
+}
+
+// ImageCache downloads external images into the local cache served under /temp-images
+type ImageCache interface {
+	CacheImage(ctx context.Context, rawURL string) (name string, err error)
 }
 
 // DashScopeGenerateRequest represents a DashScope video generation request
@@ -59,7 +63,7 @@ type DashScopeGenerationParams struct {
 	Watermark    bool     `json:"watermark,omitempty"`
 	Audio        bool     `json:"audio,omitempty"`
 	AudioURL     string   `json:"audio_url,omitempty"` // Custom audio URL
-	Seed         int64    `json:"seed,omitempty"`
+	Seed         *int64   `json:"seed,omitempty"`
 	N            int      `json:"n,omitempty"`         // Number of videos (default: 1)
 	ShotType     string   `json:"shot_type,omitempty"` // "single" or "multi" (for wan2.6-i2v)
 	ObjOrBg      []string `json:"obj_or_bg,omitempty"` // For multi-image reference
@@ -86,7 +90,9 @@ type DashScopeOutput struct {
 
 // DashScopeUsage represents API usage information
 type DashScopeUsage struct {
-	TotalTokens int `json:"total_tokens,omitempty"`
+	TotalTokens   int    `json:"total_tokens,omitempty"`
+	VideoDuration int    `json:"video_duration,omitempty"` // Generated video duration in seconds
+	VideoRatio    string `json:"video_ratio,omitempty"`    // Generated video size, e.g. "1280*720"
 }
 
 // DashScopeTaskResponse represents a task status check response
@@ -98,8 +104,51 @@ type DashScopeTaskResponse struct {
 	Message   string          `json:"message,omitempty"`
 }
 
+// applyWanAdvanced copies the advanced features a job requested onto a DashScope request.
+// Params have already been normalised, so every feature present is supported.
+func applyWanAdvanced(input *DashScopeInput, params *DashScopeGenerationParams, advanced *entity.AdvancedParams) {
+	if advanced == nil {
+		return
+	}
+	if advanced.Audio != nil {
+		params.Audio = *advanced.Audio
+	}
+	if advanced.AudioURL != "" {
+		input.AudioURL = advanced.AudioURL
+		params.Audio = true
+	}
+	if advanced.ShotType != "" {
+		params.ShotType = string(advanced.ShotType)
+	}
+	for _, ref := range advanced.ReferenceImages {
+		role := ref.Role
+		if role == "" {
+			role = entity.ReferenceImageObject
+		}
+		input.RefImagesURL = append(input.RefImagesURL, ref.URL)
+		params.ObjOrBg = append(params.ObjOrBg, string(role))
+	}
+}
+
+// wanModelVersions maps short Wan versions to their image-to-video model names
+var wanModelVersions = map[string]string{
+	"2.5": "wan2.5-i2v-preview",
+	"2.6": "wan2.6-i2v",
+}
+
+// wanModelName resolves a requested model (a short version or a full model name) to a DashScope model
+func wanModelName(requested string) string {
+	if requested == "" {
+		return "wan2.6-i2v"
+	}
+	if name, ok := wanModelVersions[requested]; ok {
+		return name
+	}
+	return requested
+}
+
 // NewWanAIProvider creates a new Wan AI provider
-func NewWanAIProvider(apiKey string, version string, baseURL string, serverBaseURL string, logger *zap.Logger) service.VideoProvider {
+func NewWanAIProvider(keys *KeyPool, version string, baseURL string, serverBaseURL string, images ImageCache, logger *zap.Logger) service.VideoProvider {
 	if version == "" {
 		version = "2.5" // Default to 2.5 (wan2.6 model not available yet)
 	}
@@ -107,9 +156,10 @@ func NewWanAIProvider(apiKey string, version string, baseURL string, serverBaseU
 		baseURL = defaultWanaiBaseURL
 	}
 	return &WanAIProvider{
-		BaseProvider:  NewBaseProvider(apiKey, baseURL, 10*time.Minute, logger),
+		BaseProvider:  NewBaseProvider(keys, baseURL, 10*time.Minute, logger),
 		version:       version,
 		serverBaseURL: serverBaseURL,
+		images:        images,
 	}
 }
 
@@ -123,30 +173,23 @@ func (p *WanAIProvider) GenerateVideo(ctx context.Context, req service.Generatio
 	// Build the request according to DashScope API format
 	// Use image-to-video for better results (always use i2v model)
 
-	// Force 5 seconds for faster generation (override any template defaults)
-	duration := 5 // Always use 5 seconds for speed
-	// Only allow user to override if they explicitly request longer
-	if req.Params.Duration > 0 && req.Params.Duration > 5 {
-		// User wants longer, but cap at 15 seconds
-		if req.Params.Duration > 15 {
-			duration = 15
-		} else {
-			// Round to nearest valid value (5, 10, or 15)
-			if req.Params.Duration <= 7 {
-				duration = 5
-			} else if req.Params.Duration <= 12 {
-				duration = 10
-			} else {
-				duration = 15
-			}
-		}
+	// Params have already been normalised against GetCapabilities, so the duration is one of 5, 10 or 15
+	duration := req.Params.Duration
+	if duration <= 0 {
+		duration = entity.DefaultVideoParams().Duration
 	}
 
 	// Get image URL from template thumbnail, or use default test image
 	imgURL := req.ThumbnailURL
 
 	// Handle image URL - proxy ALL external images through our backend to avoid access issues
-	if imgURL != "" {
+	if req.StartImageURL != "" {
+		// The user's start image is already served through a signed URL DashScope can reach
+		imgURL = req.StartImageURL
+		p.logger.Info("Using user start image for image-to-video",
+			zap.String("job_id", req.JobID),
+		)
+	} else if imgURL != "" {
 		// Check if it's an external URL (not already proxied)
 		if strings.HasPrefix(imgURL, "http://") || strings.HasPrefix(imgURL, "https://") {
 			// Check if it's already a local/proxied URL
@@ -155,20 +198,15 @@ func (p *WanAIProvider) GenerateVideo(ctx context.Context, req service.Generatio
 				// This ensures DashScope can access it
 				cachedURL, err := p.downloadAndCacheImage(ctx, imgURL, req.TemplateID)
 				if err != nil {
-					p.logger.Warn("Failed to cache external image, using default",
-						zap.String("template_id", req.TemplateID),
-						zap.String("thumbnail_url", imgURL),
-						zap.Error(err),
-					)
-					imgURL = "https://cdn.translate.alibaba.com/r/wanx-demo-1.png"
-				} else {
-					imgURL = cachedURL
-					p.logger.Info("Using cached external image",
-						zap.String("template_id", req.TemplateID),
-						zap.String("original_url", req.ThumbnailURL),
-						zap.String("cached_url", imgURL),
-					)
+					// Generating from some other image would hand the user a video unrelated to their template
+					return nil, fmt.Errorf("failed to fetch template image %s: %w", imgURL, err)
 				}
+				imgURL = cachedURL
+				p.logger.Info("Using cached external image",
+					zap.String("template_id", req.TemplateID),
+					zap.String("original_url", req.ThumbnailURL),
+					zap.String("cached_url", imgURL),
+				)
 			} else {
 				// Already proxied or local URL
 				p.logger.Info("Using template thumbnail for image-to-video",
@@ -191,24 +229,16 @@ func (p *WanAIProvider) GenerateVideo(ctx context.Context, req service.Generatio
 		)
 	}
 
-	// Use wan2.6-i2v for image-to-video (better quality)
-	modelName := "wan2.6-i2v" // Image-to-video model
-
-	// Force 720P for faster generation (override any template defaults)
-	// Default to 720P for speed (can use 480P for even faster, 1080P for quality)
-	resolution := "720P" // Always default to 720P for speed
-	if req.Params.Resolution != "" {
-		res := string(req.Params.Resolution)
-		switch res {
-		case "480p", "480P":
-			resolution = "480P" // Fastest option (2-3 minutes)
-		case "720p", "720P":
-			resolution = "720P" // Fast option (3-5 minutes) - DEFAULT
-		case "1080p", "1080P":
-			resolution = "1080P" // Slower but highest quality (5-10 minutes)
-		default:
-			resolution = "720P" // Always default to 720P
-		}
+	// Use wan2.6-i2v for image-to-video (better quality) unless the request pins a model
+	modelName := wanModelName(req.Model)
+
+	// Map the requested resolution to DashScope's format (default 720P for speed)
+	resolution := "720P"
+	switch req.Params.Resolution {
+	case entity.Resolution1080p:
+		resolution = "1080P" // Slower but highest quality (5-10 minutes)
+	case entity.Resolution720p:
+		resolution = "720P" // Fast option (3-5 minutes)
 	}
 
 	// Use only the user's prompt (template base prompt is ignored)
@@ -220,13 +250,21 @@ func (p *WanAIProvider) GenerateVideo(ctx context.Context, req service.Generatio
 	}
 
 	dashScopeParams := DashScopeGenerationParams{
-		Resolution:   resolution, // Use resolution for i2v models (default 720P for speed)
-		Duration:     duration,   // Default 5 seconds for faster generation
-		PromptExtend: false,      // Disable auto-extension to use exact user prompt
+		Resolution:   resolution, // Use resolution for i2v models
+		Duration:     duration,
+		PromptExtend: false, // Disable auto-extension to use exact user prompt
 		Watermark:    false,
 		Audio:        true,     // Enable audio for i2v
 		ShotType:     "single", // Default to single shot (faster than multi-shot)
 	}
+	applyWanAdvanced(&dashScopeInput, &dashScopeParams, req.Params.Advanced)
+	// An uploaded soundtrack takes precedence over an audio URL in the advanced params
+	if req.AudioURL != "" {
+		dashScopeInput.AudioURL = req.AudioURL
+		dashScopeParams.Audio = true
+	}
+	seed := seedFor(req.Params)
+	dashScopeParams.Seed = &seed
 
 	// Build DashScope request
 	dashScopeReq := DashScopeGenerateRequest{
@@ -252,13 +290,18 @@ func (p *WanAIProvider) GenerateVideo(ctx context.Context, req service.Generatio
 		url = "https://dashscope.aliyuncs.com/api/v1/services/aigc/video-generation/video-synthesis"
 	}
 
+	key, err := p.apiKeyFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.apiKey))
+	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key.Secret))
 	httpReq.Header.Set("X-DashScope-Async", "enable") // Enable async mode
 
 	p.logger.Info("DashScope (Wan AI) API request - Image-to-Video",
@@ -281,7 +324,24 @@ func (p *WanAIProvider) GenerateVideo(ctx context.Context, req service.Generatio
 
 	// Try to parse response even if status code is not OK
 	var dashScopeResp DashScopeGenerateResponse
-	if err := json.Unmarshal(bodyBytes, &dashScopeResp); err != nil {
+	jsonErr := json.Unmarshal(bodyBytes, &dashScopeResp)
+
+	// A revoked or exhausted key is rotated out; the job is retried later with another key
+	if isDashScopeKeyError(resp.StatusCode, dashScopeResp.Code) {
+		p.rejectKey(key, fmt.Sprintf("DashScope %d %s", resp.StatusCode, dashScopeResp.Code))
+		return nil, fmt.Errorf("%w: DashScope API key %s rejected: %d %s", entity.ErrProviderRateLimited, key.ID, resp.StatusCode, dashScopeResp.Code)
+	}
+
+	// Throttled submissions are retried later rather than failing the job
+	if isDashScopeThrottled(resp.StatusCode, dashScopeResp.Code) {
+		p.logger.Warn("DashScope submission throttled",
+			zap.Int("status", resp.StatusCode),
+			zap.String("code", dashScopeResp.Code),
+		)
+		return nil, fmt.Errorf("%w: DashScope %d %s", entity.ErrProviderRateLimited, resp.StatusCode, dashScopeResp.Code)
+	}
+
+	if err := jsonErr; err != nil {
 		// If we can't parse JSON, return generic error
 		if resp.StatusCode != http.StatusOK {
 			p.logger.Error("DashScope API error (unparseable response)",
@@ -349,9 +409,11 @@ func (p *WanAIProvider) GenerateVideo(ctx context.Context, req service.Generatio
 
 	return &entity.GenerationResult{
 		ProviderJobID: dashScopeResp.Output.TaskID,
+		ProviderKeyID: key.ID,
 		VideoURL:      dashScopeResp.Output.VideoURL,
 		ThumbnailURL:  "",
 		Duration:      duration,
+		Seed:          &seed,
 	}, nil
 }
 
@@ -367,12 +429,17 @@ func (p *WanAIProvider) GetProgress(ctx context.Context, providerJobID string) (
 		url = fmt.Sprintf("https://dashscope.aliyuncs.com/api/v1/tasks/%s", providerJobID)
 	}
 
+	key, err := p.apiKeyFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.apiKey))
+	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key.Secret))
 
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
@@ -382,6 +449,10 @@ func (p *WanAIProvider) GetProgress(ctx context.Context, providerJobID string) (
 
 	bodyBytes, _ := io.ReadAll(resp.Body)
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%w: DashScope status check %d", entity.ErrProviderRateLimited, resp.StatusCode)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		p.logger.Error("DashScope status check error",
 			zap.Int("status", resp.StatusCode),
@@ -579,9 +650,9 @@ func (p *WanAIProvider) GetProgress(ctx context.Context, providerJobID string) (
 	return progressResult, nil
 }
 
-// GetVideoURL retrieves the video URL from a completed DashScope task
-func (p *WanAIProvider) GetVideoURL(ctx context.Context, providerJobID string) (string, error) {
-	// Fetch task status to get video URL
+// GetResult retrieves the result of a completed DashScope task
+func (p *WanAIProvider) GetResult(ctx context.Context, providerJobID string) (*entity.VideoResult, error) {
+	// Fetch task status to get the result
 	baseURL := p.baseURL
 	url := fmt.Sprintf("%s/tasks/%s", baseURL, providerJobID)
 	// Replace /compatible-mode/v1 with /api/v1 if needed
@@ -591,32 +662,41 @@ func (p *WanAIProvider) GetVideoURL(ctx context.Context, providerJobID string) (
 		url = fmt.Sprintf("https://dashscope.aliyuncs.com/api/v1/tasks/%s", providerJobID)
 	}
 
+	key, err := p.apiKeyFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.apiKey))
+	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key.Secret))
 
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
+		return nil, fmt.Errorf("failed to make request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	bodyBytes, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("DashScope task status error: %d", resp.StatusCode)
+		return nil, fmt.Errorf("DashScope task status error: %d", resp.StatusCode)
 	}
 
 	var taskResp DashScopeTaskResponse
 	if err := json.Unmarshal(bodyBytes, &taskResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if taskResp.Code != "" && taskResp.Code != "Success" {
-		return "", fmt.Errorf("DashScope error: %s - %s", taskResp.Code, taskResp.Message)
+		return nil, fmt.Errorf("DashScope error: %s - %s", taskResp.Code, taskResp.Message)
+	}
+
+	if status := strings.ToUpper(taskResp.Output.TaskStatus); status != "SUCCEEDED" {
+		return nil, fmt.Errorf("DashScope task not completed: %s", taskResp.Output.TaskStatus)
 	}
 
 	// Get video URL from response
@@ -624,8 +704,41 @@ func (p *WanAIProvider) GetVideoURL(ctx context.Context, providerJobID string) (
 	if videoURL == "" {
 		videoURL = taskResp.Output.Video
 	}
+	if videoURL == "" {
+		return nil, fmt.Errorf("DashScope task succeeded but returned no video URL")
+	}
+
+	// DashScope result URLs are only valid for 24 hours after the task finishes
+	expiresAt := time.Now().Add(dashScopeResultTTL)
+
+	return &entity.VideoResult{
+		VideoURL:   videoURL,
+		Duration:   taskResp.Usage.VideoDuration,
+		Resolution: dashScopeResolution(taskResp.Usage.VideoRatio),
+		ExpiresAt:  &expiresAt,
+	}, nil
+}
 
-	return videoURL, nil
+// dashScopeResolution maps a DashScope video size such as "1280*720" to a resolution
+func dashScopeResolution(size string) entity.VideoResolution {
+	var width, height int
+	if _, err := fmt.Sscanf(size, "%d*%d", &width, &height); err != nil {
+		return ""
+	}
+	shortSide := width
+	if height < shortSide {
+		shortSide = height
+	}
+	switch {
+	case shortSide >= 2160:
+		return entity.Resolution4K
+	case shortSide >= 1080:
+		return entity.Resolution1080p
+	case shortSide >= 720:
+		return entity.Resolution720p
+	default:
+		return ""
+	}
 }
 
 // CancelGeneration cancels an ongoing generation
@@ -642,14 +755,22 @@ func (p *WanAIProvider) CancelGeneration(ctx context.Context, providerJobID stri
 // GetCapabilities returns provider capabilities
 func (p *WanAIProvider) GetCapabilities() service.ProviderCapabilities {
 	return service.ProviderCapabilities{
-		Name:            "Wan AI",
-		MaxDuration:     60,
-		MaxResolution:   entity.Resolution4K,
-		SupportedRatios: []entity.AspectRatio{entity.AspectRatio16x9, entity.AspectRatio9x16, entity.AspectRatio1x1},
-		EstimatedTime:   20,
-		QualityTier:     "premium",
-		SupportsStyles:  true,
-		CostPerSecond:   0.03,
+		Name:               "Wan AI",
+		MaxDuration:        15,
+		SupportedDurations: []int{5, 10, 15},
+		MaxResolution:      entity.Resolution1080p,
+		SupportedRatios:    []entity.AspectRatio{entity.AspectRatio16x9, entity.AspectRatio9x16, entity.AspectRatio1x1},
+		SupportedFPS:       []int{30},
+		EstimatedTime:      20,
+		QualityTier:        "premium",
+		SupportsStyles:     true,
+		CostPerSecond:      0.03,
+
+		SupportsImageToVideo: true,
+		SupportsSeed:         true,
+		SupportsAudio:        true,
+		SupportsShotType:     true,
+		MaxReferenceImages:   3,
 	}
 }
 
@@ -666,12 +787,22 @@ func (p *WanAIProvider) HealthCheck(ctx context.Context) (*service.ProviderHealt
 		url = "https://dashscope.aliyuncs.com/api/v1/models"
 	}
 
+	// Every key is cooling down
+	key, ok := p.keys.Any()
+	if !ok {
+		return &service.ProviderHealth{
+			IsHealthy:   false,
+			ErrorRate:   1.0,
+			LastChecked: time.Now().Unix(),
+		}, nil
+	}
+
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", p.apiKey))
+	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", key.Secret))
 
 	start := time.Now()
 	resp, err := p.httpClient.Do(httpReq)
@@ -699,98 +830,12 @@ func (p *WanAIProvider) HealthCheck(ctx context.Context) (*service.ProviderHealt
 	}, nil
 }
 
-// downloadAndCacheImage downloads an image from a URL and caches it locally
+// downloadAndCacheImage downloads an image through the shared image proxy cache
 // Returns the URL to access the cached image via the static endpoint
 func (p *WanAIProvider) downloadAndCacheImage(ctx context.Context, imageURL string, templateID string) (string, error) {
-	// Create cache directory if it doesn't exist
-	cacheDir := "./static/temp-images"
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create cache directory: %w", err)
-	}
-
-	// Generate cache filename from URL hash
-	hash := md5.Sum([]byte(imageURL))
-	filename := hex.EncodeToString(hash[:]) + ".png"
-	cachePath := filepath.Join(cacheDir, filename)
-
-	// Check if already cached
-	if _, err := os.Stat(cachePath); err == nil {
-		// File exists, return cached URL
-		// Use HTTP instead of HTTPS to avoid SSL certificate verification issues with DashScope
-		if p.serverBaseURL != "" {
-			// Convert HTTPS to HTTP for DashScope compatibility
-			baseURL := strings.Replace(p.serverBaseURL, "https://", "http://", 1)
-			cachedURL := fmt.Sprintf("%s/temp-images/%s", baseURL, filename)
-			p.logger.Info("Returning cached image URL (HTTP)",
-				zap.String("original_base", p.serverBaseURL),
-				zap.String("converted_base", baseURL),
-				zap.String("cached_url", cachedURL),
-			)
-			return cachedURL, nil
-		}
-		return fmt.Sprintf("/temp-images/%s", filename), nil
-	}
-
-	// Download the image with retry logic and better error handling
-	client := &http.Client{
-		Timeout: 60 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // Bypass SSL for problematic domains
-		},
-	}
-
-	// Try HTTPS first, fallback to HTTP if needed
-	var resp *http.Response
-	var err error
-	maxRetries := 3
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		req, err := http.NewRequestWithContext(ctx, "GET", imageURL, nil)
-		if err != nil {
-			return "", fmt.Errorf("failed to create request: %w", err)
-		}
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
-		req.Header.Set("Accept", "image/*,*/*")
-		req.Header.Set("Referer", imageURL)
-
-		resp, err = client.Do(req)
-		if err == nil && resp.StatusCode == http.StatusOK {
-			break
-		}
-
-		// If HTTPS failed and we haven't tried HTTP yet, try HTTP
-		if attempt == 0 && strings.HasPrefix(imageURL, "https://") {
-			imageURL = strings.Replace(imageURL, "https://", "http://", 1)
-			continue
-		}
-
-		if resp != nil {
-			resp.Body.Close()
-		}
-
-		if attempt < maxRetries-1 {
-			time.Sleep(time.Duration(attempt+1) * time.Second)
-		}
-	}
-
-	if err != nil {
-		return "", fmt.Errorf("failed to download image after %d attempts: %w", maxRetries, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to download image: status %d", resp.StatusCode)
-	}
-
-	// Save to cache
-	file, err := os.Create(cachePath)
+	filename, err := p.images.CacheImage(ctx, imageURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to create cache file: %w", err)
-	}
-	defer file.Close()
-
-	if _, err := io.Copy(file, resp.Body); err != nil {
-		os.Remove(cachePath) // Clean up on error
-		return "", fmt.Errorf("failed to save image: %w", err)
+		return "", err
 	}
 
 	// Return URL to cached image (use HTTP to avoid SSL certificate verification issues with DashScope)
@@ -798,9 +843,8 @@ func (p *WanAIProvider) downloadAndCacheImage(ctx context.Context, imageURL stri
 		// Convert HTTPS to HTTP for DashScope compatibility
 		baseURL := strings.Replace(p.serverBaseURL, "https://", "http://", 1)
 		cachedURL := fmt.Sprintf("%s/temp-images/%s", baseURL, filename)
-		p.logger.Info("Returning newly cached image URL (HTTP)",
-			zap.String("original_base", p.serverBaseURL),
-			zap.String("converted_base", baseURL),
+		p.logger.Info("Returning cached image URL (HTTP)",
+			zap.String("template_id", templateID),
 			zap.String("cached_url", cachedURL),
 		)
 		return cachedURL, nil
@@ -808,3 +852,21 @@ func (p *WanAIProvider) downloadAndCacheImage(ctx context.Context, imageURL stri
 	return fmt.Sprintf("/temp-images/%s", filename), nil
 }
 
+// isDashScopeThrottled reports whether a DashScope response means the request was rate limited
+func isDashScopeThrottled(statusCode int, code string) bool {
+	return statusCode == http.StatusTooManyRequests || strings.HasPrefix(code, "Throttling")
+}
+
+// dashScopeKeyErrorCodes are DashScope error codes that mean the API key itself is unusable
+var dashScopeKeyErrorCodes = map[string]bool{
+	"InvalidApiKey":              true,
+	"Arrearage":                  true,
+	"AccessDenied.Unpurchased":   true,
+	"Throttling.AllocationQuota": true,
+	"Throttling.FreeTierOnly":    true,
+}
+
+// isDashScopeKeyError reports whether a DashScope response means the key is revoked, unpaid or out of quota
+func isDashScopeKeyError(statusCode int, code string) bool {
+	return statusCode == http.StatusUnauthorized || dashScopeKeyErrorCodes[code]
+}
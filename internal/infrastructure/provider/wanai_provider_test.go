@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+	"go.uber.org/zap"
+)
+
+// newReplayedWanAI returns a Wan AI provider whose DashScope traffic is served from a cassette in testdata
+func newReplayedWanAI(t *testing.T, cassette string, secrets ...string) (*WanAIProvider, *KeyPool) {
+	t.Helper()
+
+	replay, err := NewCassette(filepath.Join("testdata", "cassettes", cassette), CassetteModeReplay, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewCassette: %v", err)
+	}
+
+	keys := NewKeyPool(entity.ProviderWanAI, secrets, KeySelectionRoundRobin, time.Minute)
+	p := NewWanAIProvider(keys, "2.6", "", "https://api.arabella.uz", nil, zap.NewNop()).(*WanAIProvider)
+	p.SetTransport(replay)
+	return p, keys
+}
+
+func TestWanAIGenerateVideoReplay(t *testing.T) {
+	p, keys := newReplayedWanAI(t, "wanai_submit.json", "sk-test")
+
+	seed := int64(421337)
+	result, err := p.GenerateVideo(context.Background(), service.GenerationRequest{
+		JobID:  "job-1",
+		Prompt: "A paper boat drifting down a rainy street",
+		Params: entity.VideoParams{
+			Duration:   5,
+			Resolution: entity.Resolution720p,
+			Advanced:   &entity.AdvancedParams{Seed: &seed},
+		},
+		StartImageURL: "https://api.arabella.uz/api/v1/assets/6f1c1e52-3c1b-4d8e-9a55-2f0f7f3d9b11/content",
+	})
+	if err != nil {
+		t.Fatalf("GenerateVideo: %v", err)
+	}
+
+	if result.ProviderJobID != "0385dc79-5ff8-4d82-bcb6-5f7b1c2a9e01" {
+		t.Errorf("ProviderJobID = %q", result.ProviderJobID)
+	}
+	if key, _ := keys.Any(); result.ProviderKeyID != key.ID {
+		t.Errorf("ProviderKeyID = %q, want %q", result.ProviderKeyID, key.ID)
+	}
+	if result.Seed == nil || *result.Seed != seed {
+		t.Errorf("Seed = %v, want %d", result.Seed, seed)
+	}
+	if result.Duration != 5 {
+		t.Errorf("Duration = %d, want 5", result.Duration)
+	}
+	if result.VideoURL != "" {
+		t.Errorf("VideoURL = %q, want empty for an async task", result.VideoURL)
+	}
+}
+
+func TestWanAIPollSuccessReplay(t *testing.T) {
+	p, _ := newReplayedWanAI(t, "wanai_poll_success.json", "sk-test")
+	ctx := context.Background()
+	taskID := "0385dc79-5ff8-4d82-bcb6-5f7b1c2a9e01"
+
+	// The cassette serves PENDING, RUNNING and then SUCCEEDED for repeated polls
+	for _, want := range []struct {
+		percent int
+		stage   string
+	}{
+		{10, "PENDING"},
+		{50, "PROCESSING"},
+		{100, "COMPLETED"},
+	} {
+		progress, err := p.GetProgress(ctx, taskID)
+		if err != nil {
+			t.Fatalf("GetProgress: %v", err)
+		}
+		if progress.Percent != want.percent || progress.Stage != want.stage {
+			t.Errorf("progress = %d%% %s, want %d%% %s", progress.Percent, progress.Stage, want.percent, want.stage)
+		}
+	}
+
+	result, err := p.GetResult(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetResult: %v", err)
+	}
+	if !strings.HasPrefix(result.VideoURL, "https://dashscope-result-sgp.oss-ap-southeast-1.aliyuncs.com/") {
+		t.Errorf("VideoURL = %q", result.VideoURL)
+	}
+	if strings.Contains(result.VideoURL, "Signature=") && !strings.Contains(result.VideoURL, "Signature=REDACTED") {
+		t.Errorf("cassette leaks a signed URL: %q", result.VideoURL)
+	}
+	if result.Duration != 5 {
+		t.Errorf("Duration = %d, want 5", result.Duration)
+	}
+	if result.Resolution != entity.Resolution720p {
+		t.Errorf("Resolution = %q, want %q", result.Resolution, entity.Resolution720p)
+	}
+	if result.ExpiresAt == nil || time.Until(*result.ExpiresAt) <= 0 {
+		t.Errorf("ExpiresAt = %v, want a time in the future", result.ExpiresAt)
+	}
+}
+
+func TestWanAIPollFailureReplay(t *testing.T) {
+	p, _ := newReplayedWanAI(t, "wanai_poll_failure.json", "sk-test")
+	ctx := context.Background()
+	taskID := "7a2e9f14-c3d8-4b6a-91e0-d5f8a7b3c2e9"
+
+	progress, err := p.GetProgress(ctx, taskID)
+	if err != nil {
+		t.Fatalf("GetProgress: %v", err)
+	}
+	if progress.Stage != "FAILED" {
+		t.Errorf("Stage = %q, want FAILED", progress.Stage)
+	}
+	if !strings.Contains(progress.Message, "Unable to download the media resource") {
+		t.Errorf("Message = %q, want DashScope's failure reason", progress.Message)
+	}
+
+	if _, err := p.GetResult(ctx, taskID); err == nil || !strings.Contains(err.Error(), "not completed: FAILED") {
+		t.Errorf("GetResult error = %v, want task not completed", err)
+	}
+}
+
+func TestWanAIHTTPErrorsReplay(t *testing.T) {
+	p, keys := newReplayedWanAI(t, "wanai_http_errors.json", "sk-first", "sk-second")
+	ctx := context.Background()
+	req := service.GenerationRequest{
+		JobID:         "job-2",
+		Prompt:        "A lighthouse in a storm",
+		Params:        entity.VideoParams{Duration: 5, Resolution: entity.Resolution720p},
+		StartImageURL: "https://api.arabella.uz/api/v1/assets/0b7f/content",
+	}
+
+	// 429 Throttling.RateQuota: deferred, the key stays in rotation
+	if _, err := p.GenerateVideo(ctx, req); !errors.Is(err, entity.ErrProviderRateLimited) {
+		t.Fatalf("throttled submit error = %v, want ErrProviderRateLimited", err)
+	}
+
+	// 401 InvalidApiKey: deferred, and the second key is taken out of rotation
+	if _, err := p.GenerateVideo(ctx, req); !errors.Is(err, entity.ErrProviderRateLimited) {
+		t.Fatalf("rejected key submit error = %v, want ErrProviderRateLimited", err)
+	}
+	first, _ := keys.Acquire()
+	second, _ := keys.Acquire()
+	if first.ID != second.ID || first.Secret != "sk-first" {
+		t.Errorf("pool handed out %s and %s, want only the first key after the second was rejected", first.Secret, second.Secret)
+	}
+
+	// 400 InvalidParameter: a permanent failure carrying DashScope's message
+	_, err := p.GenerateVideo(ctx, req)
+	if err == nil || errors.Is(err, entity.ErrProviderRateLimited) {
+		t.Fatalf("invalid submit error = %v, want a permanent failure", err)
+	}
+	if !strings.Contains(err.Error(), "InvalidParameter") || !strings.Contains(err.Error(), "duration must be one of") {
+		t.Errorf("invalid submit error = %v, want DashScope's code and message", err)
+	}
+
+	// 500 with an HTML body on a status check
+	if _, err := p.GetProgress(ctx, "b41d7e2a-90c5-4f3b-8e16-a2d9c7f05b38"); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("status check error = %v, want a 500 error", err)
+	}
+
+	// 429 on a status check is retried by the worker rather than counted as a poll error
+	if _, err := p.GetProgress(ctx, "c7f0a3e9-1b5d-4a28-9d64-e8b2f6c1a057"); !errors.Is(err, entity.ErrProviderRateLimited) {
+		t.Errorf("throttled status check error = %v, want ErrProviderRateLimited", err)
+	}
+}
+
+func TestCassetteReplayUnknownRequest(t *testing.T) {
+	p, _ := newReplayedWanAI(t, "wanai_submit.json", "sk-test")
+
+	_, err := p.GetProgress(context.Background(), "not-recorded")
+	if err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("error = %v, want no recorded response", err)
+	}
+}
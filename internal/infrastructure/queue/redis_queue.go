@@ -18,6 +18,13 @@ const (
 	jobStatusPrefix = "arabella:jobs:status:"
 )
 
+// queuedJob is the copy of a job kept in the queue. It also carries the mock scenario, which the
+// job's own JSON leaves out so it never reaches the API or the database.
+type queuedJob struct {
+	*entity.VideoJob
+	MockScenario string `json:"mock_scenario,omitempty"`
+}
+
 // RedisQueue implements job queue using Redis
 type RedisQueue struct {
 	client *redis.Client
@@ -35,7 +42,7 @@ func NewRedisQueue(client *redis.Client, logger *zap.Logger) *RedisQueue {
 // Enqueue adds a job to the queue
 func (q *RedisQueue) Enqueue(ctx context.Context, job *entity.VideoJob) error {
 	// Store job data
-	jobData, err := json.Marshal(job)
+	jobData, err := json.Marshal(queuedJob{VideoJob: job, MockScenario: job.MockScenario})
 	if err != nil {
 		return fmt.Errorf("failed to marshal job: %w", err)
 	}
@@ -63,19 +70,62 @@ func (q *RedisQueue) Enqueue(ctx context.Context, job *entity.VideoJob) error {
 	return nil
 }
 
-// Dequeue retrieves and removes the next job from the queue
+// Requeue returns a job to the queue so it is not dequeued again until the delay has passed
+func (q *RedisQueue) Requeue(ctx context.Context, job *entity.VideoJob, delay time.Duration) error {
+	jobData, err := json.Marshal(queuedJob{VideoJob: job, MockScenario: job.MockScenario})
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+
+	dataKey := jobDataPrefix + job.ID.String()
+	if err := q.client.Set(ctx, dataKey, jobData, 24*time.Hour).Err(); err != nil {
+		return fmt.Errorf("failed to store job data: %w", err)
+	}
+
+	// Scores are enqueue times, so a future score keeps the job hidden from Dequeue until then
+	score := float64(time.Now().Add(delay).UnixNano())
+
+	if err := q.client.ZAdd(ctx, jobQueueKey, redis.Z{
+		Score:  score,
+		Member: job.ID.String(),
+	}).Err(); err != nil {
+		return fmt.Errorf("failed to add to queue: %w", err)
+	}
+
+	q.logger.Info("Job requeued",
+		zap.String("job_id", job.ID.String()),
+		zap.Duration("delay", delay),
+	)
+
+	return nil
+}
+
+// Dequeue retrieves and removes the next job that is due from the queue
 func (q *RedisQueue) Dequeue(ctx context.Context) (*entity.VideoJob, error) {
-	// Get the first job from the queue
-	result, err := q.client.ZPopMin(ctx, jobQueueKey, 1).Result()
+	// Get the first due job; requeued jobs are scored in the future
+	result, err := q.client.ZRangeByScore(ctx, jobQueueKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   fmt.Sprintf("%d", time.Now().UnixNano()),
+		Count: 1,
+	}).Result()
 	if err != nil {
 		return nil, fmt.Errorf("failed to dequeue: %w", err)
 	}
 
 	if len(result) == 0 {
-		return nil, nil // Queue is empty
+		return nil, nil // No job is due
+	}
+
+	// Claim the job; if another instance removed it first, leave it to that instance
+	removed, err := q.client.ZRem(ctx, jobQueueKey, result[0]).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to dequeue: %w", err)
+	}
+	if removed == 0 {
+		return nil, nil
 	}
 
-	jobID := result[0].Member
+	jobID := result[0]
 
 	// Get job data
 	dataKey := jobDataPrefix + jobID
@@ -87,12 +137,13 @@ func (q *RedisQueue) Dequeue(ctx context.Context) (*entity.VideoJob, error) {
 		return nil, fmt.Errorf("failed to get job data: %w", err)
 	}
 
-	var job entity.VideoJob
-	if err := json.Unmarshal([]byte(jobData), &job); err != nil {
+	queued := queuedJob{VideoJob: &entity.VideoJob{}}
+	if err := json.Unmarshal([]byte(jobData), &queued); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal job: %w", err)
 	}
+	queued.VideoJob.MockScenario = queued.MockScenario
 
-	return &job, nil
+	return queued.VideoJob, nil
 }
 
 // GetQueuePosition returns the position of a job in the queue
@@ -310,6 +310,34 @@ func (r *TemplateRepositoryPostgres) GetCategories(ctx context.Context) ([]entit
 	return categories, nil
 }
 
+// ListMediaReferences returns the media URLs and default parameters of every template, active or not,
+// since deactivated templates can be restored
+func (r *TemplateRepositoryPostgres) ListMediaReferences(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT ref
+		FROM templates,
+		     unnest(ARRAY[thumbnail_url, preview_video_url, default_params::text]) AS ref
+		WHERE ref IS NOT NULL AND ref <> ''
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refs []string
+	for rows.Next() {
+		var ref string
+		if err := rows.Scan(&ref); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, rows.Err()
+}
+
 // scanTemplates scans rows into templates
 func (r *TemplateRepositoryPostgres) scanTemplates(rows pgx.Rows) ([]*entity.Template, int64, error) {
 	var templates []*entity.Template
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/repository"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UserAssetRepositoryPostgres implements UserAssetRepository for PostgreSQL
+type UserAssetRepositoryPostgres struct {
+	pool *pgxpool.Pool
+}
+
+// NewUserAssetRepositoryPostgres creates a new UserAssetRepositoryPostgres
+func NewUserAssetRepositoryPostgres(pool *pgxpool.Pool) repository.UserAssetRepository {
+	return &UserAssetRepositoryPostgres{pool: pool}
+}
+
+// Create creates a new asset record
+func (r *UserAssetRepositoryPostgres) Create(ctx context.Context, asset *entity.UserAsset) error {
+	query := `
+		INSERT INTO user_assets (id, user_id, kind, storage_key, content_type, size_bytes, width, height, duration_seconds, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		asset.ID,
+		asset.UserID,
+		asset.Kind,
+		asset.StorageKey,
+		asset.ContentType,
+		asset.SizeBytes,
+		asset.Width,
+		asset.Height,
+		asset.Duration,
+		asset.CreatedAt,
+	)
+
+	return err
+}
+
+// GetByID retrieves an asset by ID
+func (r *UserAssetRepositoryPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.UserAsset, error) {
+	query := `
+		SELECT id, user_id, kind, storage_key, content_type, size_bytes, width, height, duration_seconds, created_at
+		FROM user_assets
+		WHERE id = $1
+	`
+
+	asset := &entity.UserAsset{}
+	err := r.pool.QueryRow(ctx, query, id).Scan(
+		&asset.ID,
+		&asset.UserID,
+		&asset.Kind,
+		&asset.StorageKey,
+		&asset.ContentType,
+		&asset.SizeBytes,
+		&asset.Width,
+		&asset.Height,
+		&asset.Duration,
+		&asset.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, entity.ErrAssetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return asset, nil
+}
+
+// Delete deletes an asset record
+func (r *UserAssetRepositoryPostgres) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM user_assets WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return entity.ErrAssetNotFound
+	}
+
+	return nil
+}
+
+// ListStorageKeys returns the storage key of every asset
+func (r *UserAssetRepositoryPostgres) ListStorageKeys(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT storage_key FROM user_assets`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
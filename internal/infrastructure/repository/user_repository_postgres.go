@@ -52,7 +52,7 @@ func (r *UserRepositoryPostgres) Create(ctx context.Context, user *entity.User)
 // GetByID retrieves a user by ID
 func (r *UserRepositoryPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
 	query := `
-		SELECT id, email, google_id, name, avatar_url, credits, tier, subscription_expires_at, created_at, updated_at
+		SELECT id, email, google_id, name, avatar_url, credits, tier, subscription_expires_at, created_at, updated_at, is_admin
 		FROM users
 		WHERE id = $1 AND deleted_at IS NULL
 	`
@@ -69,6 +69,7 @@ func (r *UserRepositoryPostgres) GetByID(ctx context.Context, id uuid.UUID) (*en
 		&user.SubscriptionExpiresAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.IsAdmin,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -84,7 +85,7 @@ func (r *UserRepositoryPostgres) GetByID(ctx context.Context, id uuid.UUID) (*en
 // GetByEmail retrieves a user by email
 func (r *UserRepositoryPostgres) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	query := `
-		SELECT id, email, google_id, name, avatar_url, credits, tier, subscription_expires_at, created_at, updated_at
+		SELECT id, email, google_id, name, avatar_url, credits, tier, subscription_expires_at, created_at, updated_at, is_admin
 		FROM users
 		WHERE email = $1 AND deleted_at IS NULL
 	`
@@ -101,6 +102,7 @@ func (r *UserRepositoryPostgres) GetByEmail(ctx context.Context, email string) (
 		&user.SubscriptionExpiresAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.IsAdmin,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -116,7 +118,7 @@ func (r *UserRepositoryPostgres) GetByEmail(ctx context.Context, email string) (
 // GetByGoogleID retrieves a user by Google ID
 func (r *UserRepositoryPostgres) GetByGoogleID(ctx context.Context, googleID string) (*entity.User, error) {
 	query := `
-		SELECT id, email, google_id, name, avatar_url, credits, tier, subscription_expires_at, created_at, updated_at
+		SELECT id, email, google_id, name, avatar_url, credits, tier, subscription_expires_at, created_at, updated_at, is_admin
 		FROM users
 		WHERE google_id = $1 AND deleted_at IS NULL
 	`
@@ -133,6 +135,7 @@ func (r *UserRepositoryPostgres) GetByGoogleID(ctx context.Context, googleID str
 		&user.SubscriptionExpiresAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.IsAdmin,
 	)
 
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -238,7 +241,7 @@ func (r *UserRepositoryPostgres) List(ctx context.Context, offset, limit int) ([
 
 	// Get users
 	query := `
-		SELECT id, email, google_id, name, avatar_url, credits, tier, subscription_expires_at, created_at, updated_at
+		SELECT id, email, google_id, name, avatar_url, credits, tier, subscription_expires_at, created_at, updated_at, is_admin
 		FROM users
 		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -265,6 +268,7 @@ func (r *UserRepositoryPostgres) List(ctx context.Context, offset, limit int) ([
 			&user.SubscriptionExpiresAt,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.IsAdmin,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -286,7 +290,7 @@ func (r *UserRepositoryPostgres) GetByTier(ctx context.Context, tier entity.User
 
 	// Get users
 	query := `
-		SELECT id, email, google_id, name, avatar_url, credits, tier, subscription_expires_at, created_at, updated_at
+		SELECT id, email, google_id, name, avatar_url, credits, tier, subscription_expires_at, created_at, updated_at, is_admin
 		FROM users
 		WHERE tier = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
@@ -313,6 +317,7 @@ func (r *UserRepositoryPostgres) GetByTier(ctx context.Context, tier entity.User
 			&user.SubscriptionExpiresAt,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&user.IsAdmin,
 		)
 		if err != nil {
 			return nil, 0, err
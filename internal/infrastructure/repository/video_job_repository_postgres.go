@@ -13,6 +13,17 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// videoJobColumns is the column list shared by every query that scans a full video job
+const videoJobColumns = `id, user_id, template_id, prompt, params, status, progress,
+		       provider, requested_provider, provider_reason, param_adjustments, provider_job_id,
+		       provider_key_id, video_url, thumbnail_url, duration_seconds, output_resolution,
+		       video_expires_at, credits_charged, error_message, created_at, started_at, completed_at,
+		       experiment, experiment_arm, user_rating, seed, parent_job_id,
+		       start_image_id, audio_id, video_storage_key, preview_url, hls_playlist_url,
+		       thumbnail_storage_key, preview_storage_key, hls_storage_key, share_token,
+		       source_storage_key, watermarked, video_width, video_height, video_fps, video_codec,
+		       video_size_bytes, measured_duration, metadata_mismatches, watermark_removal`
+
 // VideoJobRepositoryPostgres implements VideoJobRepository for PostgreSQL
 type VideoJobRepositoryPostgres struct {
 	pool *pgxpool.Pool
@@ -26,10 +37,8 @@ func NewVideoJobRepositoryPostgres(pool *pgxpool.Pool) repository.VideoJobReposi
 // Create creates a new video job
 func (r *VideoJobRepositoryPostgres) Create(ctx context.Context, job *entity.VideoJob) error {
 	query := `
-		INSERT INTO video_jobs (id, user_id, template_id, prompt, params, status, progress,
-		                        provider, provider_job_id, video_url, thumbnail_url, duration_seconds,
-		                        credits_charged, error_message, created_at, started_at, completed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		INSERT INTO video_jobs (` + videoJobColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40, $41, $42, $43, $44, $45, $46, $47)
 	`
 
 	paramsJSON, err := json.Marshal(job.Params)
@@ -37,6 +46,19 @@ func (r *VideoJobRepositoryPostgres) Create(ctx context.Context, job *entity.Vid
 		return err
 	}
 
+	var adjustmentsJSON []byte
+	if len(job.ParamAdjustments) > 0 {
+		adjustmentsJSON, err = json.Marshal(job.ParamAdjustments)
+		if err != nil {
+			return err
+		}
+	}
+
+	metadata, err := newVideoMetadataRow(job.Metadata)
+	if err != nil {
+		return err
+	}
+
 	_, err = r.pool.Exec(ctx, query,
 		job.ID,
 		job.UserID,
@@ -46,15 +68,45 @@ func (r *VideoJobRepositoryPostgres) Create(ctx context.Context, job *entity.Vid
 		job.Status,
 		job.Progress,
 		job.Provider,
+		job.RequestedProvider,
+		job.ProviderReason,
+		adjustmentsJSON,
 		job.ProviderJobID,
+		job.ProviderKeyID,
 		job.VideoURL,
 		job.ThumbnailURL,
 		job.DurationSeconds,
+		job.OutputResolution,
+		job.VideoExpiresAt,
 		job.CreditsCharged,
 		job.ErrorMessage,
 		job.CreatedAt,
 		job.StartedAt,
 		job.CompletedAt,
+		job.Experiment,
+		job.ExperimentArm,
+		job.UserRating,
+		job.Seed,
+		job.ParentJobID,
+		job.StartImageID,
+		job.AudioID,
+		job.VideoStorageKey,
+		job.PreviewURL,
+		job.HLSPlaylistURL,
+		job.ThumbnailKey,
+		job.PreviewKey,
+		job.HLSStorageKey,
+		job.ShareToken,
+		job.SourceKey,
+		job.Watermarked,
+		metadata.Width,
+		metadata.Height,
+		metadata.FPS,
+		metadata.Codec,
+		metadata.SizeBytes,
+		metadata.Duration,
+		metadata.Mismatches,
+		job.WatermarkRemoval,
 	)
 
 	return err
@@ -63,36 +115,12 @@ func (r *VideoJobRepositoryPostgres) Create(ctx context.Context, job *entity.Vid
 // GetByID retrieves a video job by ID
 func (r *VideoJobRepositoryPostgres) GetByID(ctx context.Context, id uuid.UUID) (*entity.VideoJob, error) {
 	query := `
-		SELECT id, user_id, template_id, prompt, params, status, progress,
-		       provider, provider_job_id, video_url, thumbnail_url, duration_seconds,
-		       credits_charged, error_message, created_at, started_at, completed_at
+		SELECT ` + videoJobColumns + `
 		FROM video_jobs
 		WHERE id = $1
 	`
 
-	job := &entity.VideoJob{}
-	var paramsJSON []byte
-
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&job.ID,
-		&job.UserID,
-		&job.TemplateID,
-		&job.Prompt,
-		&paramsJSON,
-		&job.Status,
-		&job.Progress,
-		&job.Provider,
-		&job.ProviderJobID,
-		&job.VideoURL,
-		&job.ThumbnailURL,
-		&job.DurationSeconds,
-		&job.CreditsCharged,
-		&job.ErrorMessage,
-		&job.CreatedAt,
-		&job.StartedAt,
-		&job.CompletedAt,
-	)
-
+	job, err := scanJob(r.pool.QueryRow(ctx, query, id))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, entity.ErrJobNotFound
 	}
@@ -100,10 +128,6 @@ func (r *VideoJobRepositoryPostgres) GetByID(ctx context.Context, id uuid.UUID)
 		return nil, err
 	}
 
-	if err := json.Unmarshal(paramsJSON, &job.Params); err != nil {
-		return nil, err
-	}
-
 	return job, nil
 }
 
@@ -111,24 +135,60 @@ func (r *VideoJobRepositoryPostgres) GetByID(ctx context.Context, id uuid.UUID)
 func (r *VideoJobRepositoryPostgres) Update(ctx context.Context, job *entity.VideoJob) error {
 	query := `
 		UPDATE video_jobs
-		SET status = $2, progress = $3, provider = $4, provider_job_id = $5,
-		    video_url = $6, thumbnail_url = $7, duration_seconds = $8,
-		    error_message = $9, started_at = $10, completed_at = $11
+		SET status = $2, progress = $3, provider = $4, provider_reason = $5, provider_job_id = $6,
+		    video_url = $7, thumbnail_url = $8, duration_seconds = $9, output_resolution = $10,
+		    video_expires_at = $11, error_message = $12, started_at = $13, completed_at = $14,
+		    provider_key_id = $15, experiment = $16, experiment_arm = $17, user_rating = $18,
+		    seed = $19, video_storage_key = $20, preview_url = $21,
+		    hls_playlist_url = $22, thumbnail_storage_key = $23, preview_storage_key = $24,
+		    hls_storage_key = $25, share_token = $26,
+		    source_storage_key = $27, watermarked = $28,
+		    video_width = $29, video_height = $30, video_fps = $31, video_codec = $32,
+		    video_size_bytes = $33, measured_duration = $34, metadata_mismatches = $35
 		WHERE id = $1
 	`
 
+	metadata, err := newVideoMetadataRow(job.Metadata)
+	if err != nil {
+		return err
+	}
+
 	result, err := r.pool.Exec(ctx, query,
 		job.ID,
 		job.Status,
 		job.Progress,
 		job.Provider,
+		job.ProviderReason,
 		job.ProviderJobID,
 		job.VideoURL,
 		job.ThumbnailURL,
 		job.DurationSeconds,
+		job.OutputResolution,
+		job.VideoExpiresAt,
 		job.ErrorMessage,
 		job.StartedAt,
 		job.CompletedAt,
+		job.ProviderKeyID,
+		job.Experiment,
+		job.ExperimentArm,
+		job.UserRating,
+		job.Seed,
+		job.VideoStorageKey,
+		job.PreviewURL,
+		job.HLSPlaylistURL,
+		job.ThumbnailKey,
+		job.PreviewKey,
+		job.HLSStorageKey,
+		job.ShareToken,
+		job.SourceKey,
+		job.Watermarked,
+		metadata.Width,
+		metadata.Height,
+		metadata.FPS,
+		metadata.Codec,
+		metadata.SizeBytes,
+		metadata.Duration,
+		metadata.Mismatches,
 	)
 
 	if err != nil {
@@ -142,6 +202,94 @@ func (r *VideoJobRepositoryPostgres) Update(ctx context.Context, job *entity.Vid
 	return nil
 }
 
+// RequestWatermarkRemoval queues a watermark removal unless the job has no watermark or one is already queued or running
+func (r *VideoJobRepositoryPostgres) RequestWatermarkRemoval(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE video_jobs
+		SET watermark_removal = $2, watermark_removal_at = NOW()
+		WHERE id = $1 AND watermarked AND source_storage_key IS NOT NULL
+		  AND (watermark_removal IS NULL OR watermark_removal = $3)
+	`
+
+	result, err := r.pool.Exec(ctx, query, id, entity.WatermarkRemovalPending, entity.WatermarkRemovalFailed)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// ClaimWatermarkRemoval marks the oldest queued watermark removal as running and returns its job
+func (r *VideoJobRepositoryPostgres) ClaimWatermarkRemoval(ctx context.Context, staleAfter time.Duration) (*entity.VideoJob, error) {
+	query := `
+		UPDATE video_jobs
+		SET watermark_removal = $1, watermark_removal_at = NOW()
+		WHERE id = (
+			SELECT id FROM video_jobs
+			WHERE watermark_removal = $2
+			   OR (watermark_removal = $1 AND watermark_removal_at < $3)
+			ORDER BY watermark_removal_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + videoJobColumns
+
+	job, err := scanJob(r.pool.QueryRow(ctx, query,
+		entity.WatermarkRemovalProcessing,
+		entity.WatermarkRemovalPending,
+		time.Now().Add(-staleAfter),
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// CompleteWatermarkRemoval stores the unwatermarked video and HLS renditions of a job whose removal is still running
+func (r *VideoJobRepositoryPostgres) CompleteWatermarkRemoval(ctx context.Context, job *entity.VideoJob) (bool, error) {
+	query := `
+		UPDATE video_jobs
+		SET video_url = $2, video_storage_key = $3, video_expires_at = $4,
+		    source_storage_key = $5, watermarked = $6,
+		    hls_playlist_url = $7, hls_storage_key = $8,
+		    watermark_removal = NULL, watermark_removal_at = NOW()
+		WHERE id = $1 AND watermarked AND watermark_removal = $9
+	`
+
+	result, err := r.pool.Exec(ctx, query,
+		job.ID,
+		job.VideoURL,
+		job.VideoStorageKey,
+		job.VideoExpiresAt,
+		job.SourceKey,
+		job.Watermarked,
+		job.HLSPlaylistURL,
+		job.HLSStorageKey,
+		entity.WatermarkRemovalProcessing,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowsAffected() == 1, nil
+}
+
+// FailWatermarkRemoval marks a running watermark removal as failed
+func (r *VideoJobRepositoryPostgres) FailWatermarkRemoval(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE video_jobs
+		SET watermark_removal = $2, watermark_removal_at = NOW()
+		WHERE id = $1 AND watermark_removal = $3
+	`
+
+	_, err := r.pool.Exec(ctx, query, id, entity.WatermarkRemovalFailed, entity.WatermarkRemovalProcessing)
+	return err
+}
+
 // UpdateStatus updates the status and progress of a job
 func (r *VideoJobRepositoryPostgres) UpdateStatus(ctx context.Context, id uuid.UUID, status entity.JobStatus, progress int) error {
 	query := `UPDATE video_jobs SET status = $2, progress = $3 WHERE id = $1`
@@ -244,9 +392,7 @@ func (r *VideoJobRepositoryPostgres) List(ctx context.Context, filter repository
 
 	// Get jobs
 	query := `
-		SELECT id, user_id, template_id, prompt, params, status, progress,
-		       provider, provider_job_id, video_url, thumbnail_url, duration_seconds,
-		       credits_charged, error_message, created_at, started_at, completed_at
+		SELECT ` + videoJobColumns + `
 		FROM video_jobs
 		` + whereClause + `
 		ORDER BY created_at DESC
@@ -277,9 +423,7 @@ func (r *VideoJobRepositoryPostgres) GetByUserID(ctx context.Context, userID uui
 // GetPendingJobs retrieves pending jobs for processing
 func (r *VideoJobRepositoryPostgres) GetPendingJobs(ctx context.Context, limit int) ([]*entity.VideoJob, error) {
 	query := `
-		SELECT id, user_id, template_id, prompt, params, status, progress,
-		       provider, provider_job_id, video_url, thumbnail_url, duration_seconds,
-		       credits_charged, error_message, created_at, started_at, completed_at
+		SELECT ` + videoJobColumns + `
 		FROM video_jobs
 		WHERE status = $1
 		ORDER BY created_at ASC
@@ -317,9 +461,7 @@ func (r *VideoJobRepositoryPostgres) GetActiveJobsCount(ctx context.Context, use
 // GetRecentByUser retrieves recent jobs for a user
 func (r *VideoJobRepositoryPostgres) GetRecentByUser(ctx context.Context, userID uuid.UUID, limit int) ([]*entity.VideoJob, error) {
 	query := `
-		SELECT id, user_id, template_id, prompt, params, status, progress,
-		       provider, provider_job_id, video_url, thumbnail_url, duration_seconds,
-		       credits_charged, error_message, created_at, started_at, completed_at
+		SELECT ` + videoJobColumns + `
 		FROM video_jobs
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -345,43 +487,231 @@ func (r *VideoJobRepositoryPostgres) CountByStatus(ctx context.Context, status e
 	return count, err
 }
 
+// ListStorageKeys returns the storage key of every stored video, source, thumbnail, preview and HLS playlist
+func (r *VideoJobRepositoryPostgres) ListStorageKeys(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT key
+		FROM video_jobs,
+		     unnest(ARRAY[video_storage_key, source_storage_key, thumbnail_storage_key,
+		                  preview_storage_key, hls_storage_key]) AS key
+		WHERE key IS NOT NULL
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// GetExperimentStats returns per-arm outcomes, optionally for a single experiment
+func (r *VideoJobRepositoryPostgres) GetExperimentStats(ctx context.Context, experiment *string) ([]*repository.ExperimentArmStats, error) {
+	query := `
+		SELECT experiment, experiment_arm, provider,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE status = 'completed'),
+		       COUNT(*) FILTER (WHERE status = 'failed'),
+		       COALESCE(AVG(EXTRACT(EPOCH FROM completed_at - created_at)) FILTER (WHERE status = 'completed'), 0),
+		       COALESCE(AVG(user_rating), 0),
+		       COUNT(user_rating),
+		       COUNT(*) FILTER (WHERE metadata_mismatches IS NOT NULL)
+		FROM video_jobs
+		WHERE experiment IS NOT NULL AND ($1::text IS NULL OR experiment = $1)
+		GROUP BY experiment, experiment_arm, provider
+		ORDER BY experiment, experiment_arm, provider
+	`
+
+	rows, err := r.pool.Query(ctx, query, experiment)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*repository.ExperimentArmStats
+	for rows.Next() {
+		s := &repository.ExperimentArmStats{}
+		if err := rows.Scan(
+			&s.Experiment,
+			&s.Arm,
+			&s.Provider,
+			&s.TotalJobs,
+			&s.CompletedJobs,
+			&s.FailedJobs,
+			&s.AvgLatencySeconds,
+			&s.AvgRating,
+			&s.RatingCount,
+			&s.MismatchedJobs,
+		); err != nil {
+			return nil, err
+		}
+		if s.TotalJobs > 0 {
+			s.CompletionRate = float64(s.CompletedJobs) / float64(s.TotalJobs)
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
 // scanJobs scans rows into video jobs
 func (r *VideoJobRepositoryPostgres) scanJobs(rows pgx.Rows) ([]*entity.VideoJob, error) {
 	var jobs []*entity.VideoJob
 	for rows.Next() {
-		job := &entity.VideoJob{}
-		var paramsJSON []byte
-
-		err := rows.Scan(
-			&job.ID,
-			&job.UserID,
-			&job.TemplateID,
-			&job.Prompt,
-			&paramsJSON,
-			&job.Status,
-			&job.Progress,
-			&job.Provider,
-			&job.ProviderJobID,
-			&job.VideoURL,
-			&job.ThumbnailURL,
-			&job.DurationSeconds,
-			&job.CreditsCharged,
-			&job.ErrorMessage,
-			&job.CreatedAt,
-			&job.StartedAt,
-			&job.CompletedAt,
-		)
+		job, err := scanJob(rows)
 		if err != nil {
 			return nil, err
 		}
 
-		if err := json.Unmarshal(paramsJSON, &job.Params); err != nil {
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// scanJob scans a single row selected with videoJobColumns into a video job
+func scanJob(row pgx.Row) (*entity.VideoJob, error) {
+	job := &entity.VideoJob{}
+	var paramsJSON, adjustmentsJSON []byte
+	var metadata videoMetadataRow
+
+	err := row.Scan(
+		&job.ID,
+		&job.UserID,
+		&job.TemplateID,
+		&job.Prompt,
+		&paramsJSON,
+		&job.Status,
+		&job.Progress,
+		&job.Provider,
+		&job.RequestedProvider,
+		&job.ProviderReason,
+		&adjustmentsJSON,
+		&job.ProviderJobID,
+		&job.ProviderKeyID,
+		&job.VideoURL,
+		&job.ThumbnailURL,
+		&job.DurationSeconds,
+		&job.OutputResolution,
+		&job.VideoExpiresAt,
+		&job.CreditsCharged,
+		&job.ErrorMessage,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.CompletedAt,
+		&job.Experiment,
+		&job.ExperimentArm,
+		&job.UserRating,
+		&job.Seed,
+		&job.ParentJobID,
+		&job.StartImageID,
+		&job.AudioID,
+		&job.VideoStorageKey,
+		&job.PreviewURL,
+		&job.HLSPlaylistURL,
+		&job.ThumbnailKey,
+		&job.PreviewKey,
+		&job.HLSStorageKey,
+		&job.ShareToken,
+		&job.SourceKey,
+		&job.Watermarked,
+		&metadata.Width,
+		&metadata.Height,
+		&metadata.FPS,
+		&metadata.Codec,
+		&metadata.SizeBytes,
+		&metadata.Duration,
+		&metadata.Mismatches,
+		&job.WatermarkRemoval,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(paramsJSON, &job.Params); err != nil {
+		return nil, err
+	}
+
+	if len(adjustmentsJSON) > 0 {
+		if err := json.Unmarshal(adjustmentsJSON, &job.ParamAdjustments); err != nil {
 			return nil, err
 		}
+	}
 
-		jobs = append(jobs, job)
+	if job.Metadata, err = metadata.metadata(); err != nil {
+		return nil, err
 	}
 
-	return jobs, nil
+	return job, nil
 }
 
+// videoMetadataRow holds the nullable columns a job's measured video metadata is stored in.
+// Mismatches are NULL unless there are any, so flagged jobs can be found by index.
+type videoMetadataRow struct {
+	Width      *int
+	Height     *int
+	FPS        *float64
+	Codec      *string
+	SizeBytes  *int64
+	Duration   *float64
+	Mismatches []byte
+}
+
+// newVideoMetadataRow converts measured metadata to its columns; nil metadata stores NULLs
+func newVideoMetadataRow(metadata *entity.VideoMetadata) (videoMetadataRow, error) {
+	if metadata == nil {
+		return videoMetadataRow{}, nil
+	}
+	row := videoMetadataRow{
+		Width:     &metadata.Width,
+		Height:    &metadata.Height,
+		FPS:       &metadata.FPS,
+		Codec:     &metadata.Codec,
+		SizeBytes: &metadata.SizeBytes,
+		Duration:  &metadata.DurationSeconds,
+	}
+	if len(metadata.Mismatches) > 0 {
+		mismatches, err := json.Marshal(metadata.Mismatches)
+		if err != nil {
+			return videoMetadataRow{}, err
+		}
+		row.Mismatches = mismatches
+	}
+	return row, nil
+}
+
+// metadata converts the columns back to measured metadata, or nil if the video was never probed
+func (r videoMetadataRow) metadata() (*entity.VideoMetadata, error) {
+	if r.Width == nil || r.Height == nil {
+		return nil, nil
+	}
+	metadata := &entity.VideoMetadata{Width: *r.Width, Height: *r.Height}
+	if r.FPS != nil {
+		metadata.FPS = *r.FPS
+	}
+	if r.Codec != nil {
+		metadata.Codec = *r.Codec
+	}
+	if r.SizeBytes != nil {
+		metadata.SizeBytes = *r.SizeBytes
+	}
+	if r.Duration != nil {
+		metadata.DurationSeconds = *r.Duration
+	}
+	if len(r.Mismatches) > 0 {
+		if err := json.Unmarshal(r.Mismatches, &metadata.Mismatches); err != nil {
+			return nil, err
+		}
+	}
+	return metadata, nil
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// defaultAssetURLTTL is how long a signed asset URL stays valid when no TTL is configured
+const defaultAssetURLTTL = time.Hour
+
+// AssetURLSigner issues and verifies short-lived HMAC-signed URLs for private user assets
+type AssetURLSigner struct {
+	secret  []byte
+	baseURL string
+	ttl     time.Duration
+}
+
+// NewAssetURLSigner creates a signer for URLs under baseURL (the public API base URL)
+func NewAssetURLSigner(secret, baseURL string, ttl time.Duration) *AssetURLSigner {
+	if ttl <= 0 {
+		ttl = defaultAssetURLTTL
+	}
+	return &AssetURLSigner{
+		secret:  []byte(secret),
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		ttl:     ttl,
+	}
+}
+
+// SignAssetURL returns a URL that serves the asset until the signer's TTL elapses
+func (s *AssetURLSigner) SignAssetURL(assetID uuid.UUID) string {
+	expires := strconv.FormatInt(time.Now().Add(s.ttl).Unix(), 10)
+	return fmt.Sprintf("%s/api/v1/assets/%s/content?expires=%s&signature=%s",
+		s.baseURL, assetID, expires, s.signature(assetID, expires))
+}
+
+// VerifyAssetURL checks the expiry and signature from a signed asset URL
+func (s *AssetURLSigner) VerifyAssetURL(assetID uuid.UUID, expires, signature string) error {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return entity.ErrInvalidAssetURL
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.signature(assetID, expires))) {
+		return entity.ErrInvalidAssetURL
+	}
+	return nil
+}
+
+// signature computes the hex HMAC-SHA256 of the asset ID and expiry
+func (s *AssetURLSigner) signature(assetID uuid.UUID, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(assetID.String() + ":" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
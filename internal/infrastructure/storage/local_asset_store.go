@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+// LocalAssetStore keeps private user assets on the local filesystem, outside any static route
+type LocalAssetStore struct {
+	root string
+}
+
+// NewLocalAssetStore creates an asset store rooted at dir
+func NewLocalAssetStore(dir string) *LocalAssetStore {
+	return &LocalAssetStore{root: dir}
+}
+
+// Save writes data under key, replacing any existing file
+func (s *LocalAssetStore) Save(ctx context.Context, key string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create asset directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial asset
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return fmt.Errorf("failed to write asset: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write asset: %w", err)
+	}
+	return nil
+}
+
+// Open opens the asset stored under key
+func (s *LocalAssetStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Delete removes the asset stored under key; deleting a missing asset is not an error
+func (s *LocalAssetStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Walk calls fn for every asset whose key starts with prefix
+func (s *LocalAssetStore) Walk(ctx context.Context, prefix string, fn func(key string, info service.MediaObjectInfo) error) error {
+	return walkRoot(ctx, s.root, prefix, fn)
+}
+
+// path resolves a key inside the store root, rejecting keys that escape it
+func (s *LocalAssetStore) path(key string) (string, error) {
+	return resolveKey(s.root, key)
+}
+
+// resolveKey maps a storage key to a path inside root, rejecting keys that escape it
+func resolveKey(root, key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(root, clean), nil
+}
@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+// LocalMediaStorage stores generated media on the local filesystem, for development and single-node deployments
+type LocalMediaStorage struct {
+	root    string
+	baseURL string
+}
+
+// NewLocalMediaStorage creates a media store rooted at dir whose files are served under baseURL
+func NewLocalMediaStorage(dir, baseURL string) *LocalMediaStorage {
+	return &LocalMediaStorage{
+		root:    dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Put streams body to a file under key, replacing any existing file
+func (s *LocalMediaStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path, err := resolveKey(s.root, key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create media directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create media file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write media: %w", err)
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("failed to write media: wrote %d of %d bytes", written, size)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o640); err != nil {
+		return fmt.Errorf("failed to write media: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write media: %w", err)
+	}
+	return nil
+}
+
+// Open opens the file stored under key
+func (s *LocalMediaStorage) Open(ctx context.Context, key string) (io.ReadSeekCloser, service.MediaObjectInfo, error) {
+	path, err := resolveKey(s.root, key)
+	if err != nil {
+		return nil, service.MediaObjectInfo{}, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, service.MediaObjectInfo{}, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, service.MediaObjectInfo{}, err
+	}
+	if stat.IsDir() {
+		file.Close()
+		return nil, service.MediaObjectInfo{}, fmt.Errorf("media %q: %w", key, os.ErrNotExist)
+	}
+
+	return file, service.MediaObjectInfo{
+		Size:    stat.Size(),
+		ModTime: stat.ModTime(),
+		ETag:    fmt.Sprintf(`"%x-%x"`, stat.ModTime().UnixNano(), stat.Size()),
+	}, nil
+}
+
+// Delete removes the file stored under key; deleting a missing file is not an error
+func (s *LocalMediaStorage) Delete(ctx context.Context, key string) error {
+	path, err := resolveKey(s.root, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// URL returns the URL the file under key is served from
+func (s *LocalMediaStorage) URL(key string) string {
+	return s.baseURL + "/" + escapeKey(key)
+}
+
+// Walk calls fn for every file whose key starts with prefix
+func (s *LocalMediaStorage) Walk(ctx context.Context, prefix string, fn func(key string, info service.MediaObjectInfo) error) error {
+	return walkRoot(ctx, s.root, prefix, fn)
+}
+
+// walkRoot calls fn for every regular file under root whose slash-separated key starts with prefix.
+// A missing root, or a file removed while walking, is skipped rather than reported.
+func walkRoot(ctx context.Context, root, prefix string, fn func(key string, info service.MediaObjectInfo) error) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if entry.IsDir() {
+			// Only descend into directories that can hold keys under prefix
+			if key != "." && !strings.HasPrefix(key+"/", prefix) && !strings.HasPrefix(prefix, key+"/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		stat, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return fn(key, service.MediaObjectInfo{
+			Size:    stat.Size(),
+			ModTime: stat.ModTime(),
+			ETag:    fmt.Sprintf(`"%x-%x"`, stat.ModTime().UnixNano(), stat.Size()),
+		})
+	})
+}
+
+// escapeKey percent-encodes each segment of a storage key for use in a URL path
+func escapeKey(key string) string {
+	segments := strings.Split(strings.TrimPrefix(key, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+)
+
+// defaultMediaURLTTL is how long a signed media URL stays valid when no TTL is configured
+const defaultMediaURLTTL = 2 * time.Hour
+
+// SigningKey is an HMAC key identified by an ID embedded in the URLs it signs
+type SigningKey struct {
+	ID     string
+	Secret string
+}
+
+// MediaURLSigner issues and verifies expiring HMAC-signed URLs for stored media.
+//
+// The signature travels in the path (/api/v1/media/{kid}.{expires}.{signature}/{key}) rather than the
+// query string, so relative references inside an HLS playlist resolve to URLs that carry it too.
+// A signature covers either one key or a directory prefix ending in "/".
+//
+// The first key signs; every key verifies. To rotate, put the new key first and keep the old one
+// until links it signed have expired.
+type MediaURLSigner struct {
+	keys    []SigningKey
+	baseURL string
+	ttl     time.Duration
+}
+
+// NewMediaURLSigner creates a signer for URLs under baseURL (the public API base URL)
+func NewMediaURLSigner(keys []SigningKey, baseURL string, ttl time.Duration) (*MediaURLSigner, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one media URL signing key is required")
+	}
+	for _, key := range keys {
+		if key.ID == "" || strings.ContainsAny(key.ID, "./") || key.Secret == "" {
+			return nil, fmt.Errorf("invalid media URL signing key %q", key.ID)
+		}
+	}
+	if ttl <= 0 {
+		ttl = defaultMediaURLTTL
+	}
+	return &MediaURLSigner{
+		keys:    keys,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		ttl:     ttl,
+	}, nil
+}
+
+// SignMediaURL returns a URL that serves the object under key until the signer's TTL elapses
+func (s *MediaURLSigner) SignMediaURL(key string) string {
+	return s.signedURL(strings.TrimPrefix(key, "/"), key)
+}
+
+// SignMediaDirURL returns a URL for key whose signature also covers every object in key's
+// directory, such as the renditions and segments an HLS playlist references
+func (s *MediaURLSigner) SignMediaDirURL(key string) string {
+	return s.signedURL(path.Dir(strings.TrimPrefix(key, "/"))+"/", key)
+}
+
+// VerifyMediaURL checks a token from a signed media URL against the requested key
+func (s *MediaURLSigner) VerifyMediaURL(token, key string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return entity.ErrInvalidMediaURL
+	}
+	keyID, expires, signature := parts[0], parts[1], parts[2]
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return entity.ErrInvalidMediaURL
+	}
+
+	secret, ok := s.secret(keyID)
+	if !ok {
+		return entity.ErrInvalidMediaURL
+	}
+
+	// The signature may cover the key itself or any directory containing it, so a key must not
+	// be able to climb out of a signed directory
+	key = strings.TrimPrefix(key, "/")
+	if slices.Contains(strings.Split(key, "/"), "..") {
+		return entity.ErrInvalidMediaURL
+	}
+	scopes := []string{key}
+	for i, c := range key {
+		if c == '/' {
+			scopes = append(scopes, key[:i+1])
+		}
+	}
+	for _, scope := range scopes {
+		if hmac.Equal([]byte(signature), []byte(mediaSignature(secret, scope, expires))) {
+			return nil
+		}
+	}
+	return entity.ErrInvalidMediaURL
+}
+
+// signedURL builds a URL for key carrying a signature over scope
+func (s *MediaURLSigner) signedURL(scope, key string) string {
+	active := s.keys[0]
+	expires := strconv.FormatInt(time.Now().Add(s.ttl).Unix(), 10)
+	token := active.ID + "." + expires + "." + mediaSignature(active.Secret, scope, expires)
+	return fmt.Sprintf("%s/api/v1/media/%s/%s", s.baseURL, token, escapeKey(key))
+}
+
+// secret returns the secret of the key with the given ID
+func (s *MediaURLSigner) secret(keyID string) (string, bool) {
+	for _, key := range s.keys {
+		if key.ID == keyID {
+			return key.Secret, true
+		}
+	}
+	return "", false
+}
+
+// mediaSignature computes the hex HMAC-SHA256 of a scope and expiry
+func mediaSignature(secret, scope, expires string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("media:" + scope + ":" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+)
+
+const testMediaBaseURL = "https://api.arabella.uz"
+
+func newTestSigner(t *testing.T, keys ...SigningKey) *MediaURLSigner {
+	t.Helper()
+
+	signer, err := NewMediaURLSigner(keys, testMediaBaseURL+"/", time.Hour)
+	if err != nil {
+		t.Fatalf("NewMediaURLSigner: %v", err)
+	}
+	return signer
+}
+
+// splitSignedURL returns the token and unescaped key of a signed media URL
+func splitSignedURL(t *testing.T, signedURL string) (token, key string) {
+	t.Helper()
+
+	rest, ok := strings.CutPrefix(signedURL, testMediaBaseURL+"/api/v1/media/")
+	if !ok {
+		t.Fatalf("signed URL %q is not under the media endpoint", signedURL)
+	}
+	token, escaped, _ := strings.Cut(rest, "/")
+	key, err := url.PathUnescape(escaped)
+	if err != nil {
+		t.Fatalf("signed URL %q has a malformed key: %v", signedURL, err)
+	}
+	return token, key
+}
+
+func TestMediaURLSignerSignsKey(t *testing.T) {
+	signer := newTestSigner(t, SigningKey{ID: "2025b", Secret: "new-secret"})
+
+	token, key := splitSignedURL(t, signer.SignMediaURL("videos/job 1/video.mp4"))
+	if key != "videos/job 1/video.mp4" {
+		t.Fatalf("key = %q", key)
+	}
+	if !strings.HasPrefix(token, "2025b.") {
+		t.Errorf("token %q is not signed with the active key", token)
+	}
+
+	if err := signer.VerifyMediaURL(token, key); err != nil {
+		t.Errorf("VerifyMediaURL(own key) = %v", err)
+	}
+	if err := signer.VerifyMediaURL(token, "/"+key); err != nil {
+		t.Errorf("VerifyMediaURL(leading slash) = %v", err)
+	}
+	for _, other := range []string{"videos/job 1/poster.jpg", "videos/job 2/video.mp4", "videos/job 1/"} {
+		if err := signer.VerifyMediaURL(token, other); !errors.Is(err, entity.ErrInvalidMediaURL) {
+			t.Errorf("VerifyMediaURL(%q) = %v, want ErrInvalidMediaURL", other, err)
+		}
+	}
+}
+
+func TestMediaURLSignerSignsDirectory(t *testing.T) {
+	signer := newTestSigner(t, SigningKey{ID: "k1", Secret: "secret"})
+
+	token, key := splitSignedURL(t, signer.SignMediaDirURL("hls/job-1/master.m3u8"))
+	if key != "hls/job-1/master.m3u8" {
+		t.Fatalf("key = %q", key)
+	}
+
+	for _, covered := range []string{"hls/job-1/master.m3u8", "hls/job-1/720p.m3u8", "hls/job-1/720p/segment_003.ts"} {
+		if err := signer.VerifyMediaURL(token, covered); err != nil {
+			t.Errorf("VerifyMediaURL(%q) = %v, want it covered by the directory", covered, err)
+		}
+	}
+	for _, outside := range []string{"hls/job-2/master.m3u8", "hls/job-1/../job-2/master.m3u8", "hls/job-1/..", "videos/job-1/video.mp4", "hls/master.m3u8"} {
+		if err := signer.VerifyMediaURL(token, outside); !errors.Is(err, entity.ErrInvalidMediaURL) {
+			t.Errorf("VerifyMediaURL(%q) = %v, want ErrInvalidMediaURL", outside, err)
+		}
+	}
+}
+
+func TestMediaURLSignerAllowsDotsInNames(t *testing.T) {
+	signer := newTestSigner(t, SigningKey{ID: "k1", Secret: "secret"})
+
+	// Only a ".." path segment climbs directories; dots inside a name are ordinary characters
+	for _, key := range []string{"videos/job-1/a..b.mp4", "uploads/..hidden/image.jpg"} {
+		token, _ := splitSignedURL(t, signer.SignMediaURL(key))
+		if err := signer.VerifyMediaURL(token, key); err != nil {
+			t.Errorf("VerifyMediaURL(%q) = %v, want valid", key, err)
+		}
+	}
+}
+
+func TestMediaURLSignerRejectsBadTokens(t *testing.T) {
+	signer := newTestSigner(t, SigningKey{ID: "k1", Secret: "secret"})
+	key := "videos/job-1/video.mp4"
+	token, _ := splitSignedURL(t, signer.SignMediaURL(key))
+	keyID, expires, signature := splitToken(t, token)
+
+	past := strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)
+	later := strconv.FormatInt(time.Now().Add(48*time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"expired", keyID + "." + past + "." + mediaSignature("secret", key, past)},
+		{"expiry extended", keyID + "." + later + "." + signature},
+		{"signature tampered", keyID + "." + expires + "." + strings.Repeat("0", len(signature))},
+		{"unknown key", "k9." + expires + "." + signature},
+		{"wrong secret", keyID + "." + expires + "." + mediaSignature("other", key, expires)},
+		{"non-numeric expiry", keyID + ".soon." + signature},
+		{"missing part", keyID + "." + expires},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := signer.VerifyMediaURL(tt.token, key); !errors.Is(err, entity.ErrInvalidMediaURL) {
+				t.Errorf("VerifyMediaURL = %v, want ErrInvalidMediaURL", err)
+			}
+		})
+	}
+}
+
+func TestMediaURLSignerKeyRotation(t *testing.T) {
+	oldKey := SigningKey{ID: "2025a", Secret: "old-secret"}
+	newKey := SigningKey{ID: "2025b", Secret: "new-secret"}
+	key := "thumbnails/job-1/poster.jpg"
+
+	before := newTestSigner(t, oldKey)
+	oldToken, _ := splitSignedURL(t, before.SignMediaURL(key))
+
+	// During rotation the new key signs and the old one still verifies
+	rotating := newTestSigner(t, newKey, oldKey)
+	if err := rotating.VerifyMediaURL(oldToken, key); err != nil {
+		t.Errorf("link signed before rotation rejected: %v", err)
+	}
+	newToken, _ := splitSignedURL(t, rotating.SignMediaURL(key))
+	if !strings.HasPrefix(newToken, newKey.ID+".") {
+		t.Errorf("token %q is not signed with the new key", newToken)
+	}
+
+	// Once the old key is retired its links stop working
+	after := newTestSigner(t, newKey)
+	if err := after.VerifyMediaURL(oldToken, key); !errors.Is(err, entity.ErrInvalidMediaURL) {
+		t.Errorf("link signed with a retired key = %v, want ErrInvalidMediaURL", err)
+	}
+	if err := after.VerifyMediaURL(newToken, key); err != nil {
+		t.Errorf("link signed with the new key rejected: %v", err)
+	}
+}
+
+func TestNewMediaURLSignerValidatesKeys(t *testing.T) {
+	invalid := [][]SigningKey{
+		nil,
+		{{ID: "", Secret: "secret"}},
+		{{ID: "k1", Secret: ""}},
+		{{ID: "k.1", Secret: "secret"}},
+		{{ID: "k/1", Secret: "secret"}},
+		{{ID: "k1", Secret: "secret"}, {ID: "k2", Secret: ""}},
+	}
+	for _, keys := range invalid {
+		if _, err := NewMediaURLSigner(keys, testMediaBaseURL, time.Hour); err == nil {
+			t.Errorf("NewMediaURLSigner(%v) accepted invalid keys", keys)
+		}
+	}
+}
+
+// splitToken returns the key ID, expiry and signature of a token
+func splitToken(t *testing.T, token string) (keyID, expires, signature string) {
+	t.Helper()
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token %q does not have three parts", token)
+	}
+	return parts[0], parts[1], parts[2]
+}
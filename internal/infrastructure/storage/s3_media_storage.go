@@ -0,0 +1,406 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+const (
+	// s3RequestTimeout bounds a single S3 request, including uploading a large video
+	s3RequestTimeout = 10 * time.Minute
+
+	// s3EmptyPayloadHash is the SHA-256 of an empty body, signed for requests without one
+	s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	// s3UnsignedPayload lets uploads stream without hashing the body first
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+	// maxS3ListBytes bounds a single ListObjectsV2 response; a full page of 1000 keys is far smaller
+	maxS3ListBytes = 16 << 20
+)
+
+// S3Config holds the settings for an S3-compatible bucket
+type S3Config struct {
+	Endpoint      string // Custom endpoint for S3-compatible services such as MinIO (empty for AWS)
+	Region        string
+	Bucket        string
+	AccessKey     string
+	SecretKey     string
+	PathStyle     bool   // Address the bucket as endpoint/bucket instead of bucket.endpoint (required by MinIO)
+	PublicBaseURL string // CDN or public base URL objects are served from (empty serves straight from the bucket)
+}
+
+// S3MediaStorage stores generated media in an S3-compatible bucket, signing requests with AWS Signature V4
+type S3MediaStorage struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3MediaStorage creates a media store backed by the configured bucket
+func NewS3MediaStorage(cfg S3Config) (*S3MediaStorage, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	cfg.PublicBaseURL = strings.TrimSuffix(cfg.PublicBaseURL, "/")
+
+	return &S3MediaStorage{
+		cfg:      cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: s3RequestTimeout},
+	}, nil
+}
+
+// Put uploads size bytes read from body to key
+func (s *S3MediaStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		return fmt.Errorf("S3 uploads require a known size")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), body)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, s3UnsignedPayload, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("S3 upload failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("upload", key, resp)
+	}
+	return nil
+}
+
+// Open looks up the object stored under key and returns a reader that downloads it lazily with
+// ranged GETs, so seeking to serve a range request does not transfer the bytes before it
+func (s *S3MediaStorage) Open(ctx context.Context, key string) (io.ReadSeekCloser, service.MediaObjectInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.objectURL(key), nil)
+	if err != nil {
+		return nil, service.MediaObjectInfo{}, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	s.sign(req, s3EmptyPayloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, service.MediaObjectInfo{}, fmt.Errorf("S3 download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, service.MediaObjectInfo{}, fmt.Errorf("S3 object %q: %w", key, os.ErrNotExist)
+	default:
+		return nil, service.MediaObjectInfo{}, s3Error("download", key, resp)
+	}
+	if resp.ContentLength < 0 {
+		return nil, service.MediaObjectInfo{}, fmt.Errorf("S3 object %q has no content length", key)
+	}
+
+	info := service.MediaObjectInfo{
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        resp.Header.Get("ETag"),
+	}
+	info.ModTime, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+
+	return &s3Object{storage: s, ctx: ctx, key: key, size: info.Size}, info, nil
+}
+
+// Delete removes the object stored under key; deleting a missing object is not an error
+func (s *S3MediaStorage) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	s.sign(req, s3EmptyPayloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("S3 delete failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error("delete", key, resp)
+	}
+	return nil
+}
+
+// URL returns the CDN URL for key, or the bucket URL when no CDN is configured
+func (s *S3MediaStorage) URL(key string) string {
+	if s.cfg.PublicBaseURL != "" {
+		return s.cfg.PublicBaseURL + "/" + escapeKey(key)
+	}
+	return s.objectURL(key)
+}
+
+// Walk lists every object whose key starts with prefix, one ListObjectsV2 page at a time
+func (s *S3MediaStorage) Walk(ctx context.Context, prefix string, fn func(key string, info service.MediaObjectInfo) error) error {
+	token := ""
+	for {
+		page, err := s.listObjects(ctx, prefix, token)
+		if err != nil {
+			return err
+		}
+		for _, object := range page.Contents {
+			if err := fn(object.Key, service.MediaObjectInfo{
+				Size:    object.Size,
+				ModTime: object.LastModified,
+				ETag:    object.ETag,
+			}); err != nil {
+				return err
+			}
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// s3ListPage is the part of a ListObjectsV2 response Walk reads
+type s3ListPage struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		ETag         string    `xml:"ETag"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+// listObjects fetches one page of the objects under prefix, continuing from token if it is set
+func (s *S3MediaStorage) listObjects(ctx context.Context, prefix, token string) (*s3ListPage, error) {
+	// SigV4 signs the query string as sent, so parameters are encoded by hand and kept in sorted order
+	query := "list-type=2&prefix=" + s3URIEncode(prefix, true)
+	if token != "" {
+		query = "continuation-token=" + s3URIEncode(token, true) + "&" + query
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.bucketURL()+"?"+query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	s.sign(req, s3EmptyPayloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 list failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error("list", prefix, resp)
+	}
+
+	var page s3ListPage
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, maxS3ListBytes)).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode S3 listing: %w", err)
+	}
+	return &page, nil
+}
+
+// bucketURL returns the URL of the bucket itself in path-style or virtual-hosted style
+func (s *S3MediaStorage) bucketURL() string {
+	if s.cfg.PathStyle {
+		return fmt.Sprintf("%s://%s/%s", s.endpoint.Scheme, s.endpoint.Host, s.cfg.Bucket)
+	}
+	return fmt.Sprintf("%s://%s.%s/", s.endpoint.Scheme, s.cfg.Bucket, s.endpoint.Host)
+}
+
+// objectURL returns the bucket URL for key in path-style or virtual-hosted style
+func (s *S3MediaStorage) objectURL(key string) string {
+	path := "/" + s3URIEncode(strings.TrimPrefix(key, "/"), false)
+	if s.cfg.PathStyle {
+		return fmt.Sprintf("%s://%s/%s%s", s.endpoint.Scheme, s.endpoint.Host, s.cfg.Bucket, path)
+	}
+	return fmt.Sprintf("%s://%s.%s%s", s.endpoint.Scheme, s.cfg.Bucket, s.endpoint.Host, path)
+}
+
+// sign adds AWS Signature Version 4 headers to req
+func (s *S3MediaStorage) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Host, content hash and date are the only headers signed; they are all we send that S3 inspects
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	hashedRequest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashedRequest[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, s.cfg.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+// s3Object reads an S3 object from the current offset, opening a ranged GET on the first read
+// after a seek
+type s3Object struct {
+	storage *S3MediaStorage
+	ctx     context.Context
+	key     string
+	size    int64
+	offset  int64
+	body    io.ReadCloser
+}
+
+// Read reads from the object at the current offset
+func (o *s3Object) Read(p []byte) (int, error) {
+	if o.offset >= o.size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		if err := o.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	if err == io.EOF && o.offset < o.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// Seek moves the offset of the next read; the open download is dropped only if the offset changes
+func (o *s3Object) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		offset += o.size
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("S3 object %q: negative seek offset", o.key)
+	}
+
+	if offset != o.offset && o.body != nil {
+		o.body.Close()
+		o.body = nil
+	}
+	o.offset = offset
+	return offset, nil
+}
+
+// Close closes the open download, if any
+func (o *s3Object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
+
+// open starts downloading the object from the current offset
+func (o *s3Object) open() error {
+	req, err := http.NewRequestWithContext(o.ctx, http.MethodGet, o.storage.objectURL(o.key), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create S3 request: %w", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", o.offset))
+	o.storage.sign(req, s3EmptyPayloadHash, time.Now())
+
+	resp, err := o.storage.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("S3 download failed: %w", err)
+	}
+
+	// A server may answer a range starting at zero with the whole object
+	switch {
+	case resp.StatusCode == http.StatusPartialContent, resp.StatusCode == http.StatusOK && o.offset == 0:
+		o.body = resp.Body
+		return nil
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return fmt.Errorf("S3 object %q: %w", o.key, os.ErrNotExist)
+	default:
+		defer resp.Body.Close()
+		return s3Error("download", o.key, resp)
+	}
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data under key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3URIEncode encodes a path or query value the way SigV4 expects: every byte except unreserved
+// characters, and slashes unless encodeSlash is set
+func s3URIEncode(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(value) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Error builds an error from an unexpected S3 response, including the start of its XML error body
+func s3Error(operation, key string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("S3 %s of %q failed with status %d: %s", operation, key, resp.StatusCode, strings.TrimSpace(string(body)))
+}
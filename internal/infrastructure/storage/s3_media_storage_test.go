@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 serves one object with range support and records the Range header of every GET
+type fakeS3 struct {
+	content []byte
+	modTime time.Time
+
+	mu     sync.Mutex
+	ranges []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/media/videos/job-1/video.mp4" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method == http.MethodGet {
+		f.mu.Lock()
+		f.ranges = append(f.ranges, r.Header.Get("Range"))
+		f.mu.Unlock()
+	}
+	w.Header().Set("Content-Type", "video/mp4")
+	w.Header().Set("ETag", `"abc123"`)
+	http.ServeContent(w, r, "", f.modTime, bytes.NewReader(f.content))
+}
+
+func (f *fakeS3) gets() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.ranges...)
+}
+
+func newFakeS3Storage(t *testing.T) (*S3MediaStorage, *fakeS3) {
+	t.Helper()
+
+	fake := &fakeS3{
+		content: []byte(strings.Repeat("0123456789", 10)),
+		modTime: time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC),
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	s, err := NewS3MediaStorage(S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "media",
+		AccessKey: "test",
+		SecretKey: "test",
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3MediaStorage: %v", err)
+	}
+	return s, fake
+}
+
+func TestS3ObjectServesRangeRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		rangeValue string
+		wantStatus int
+		wantBody   string
+		wantRange  string // Content-Range of the response
+		wantGets   []string
+	}{
+		{"whole object", "", http.StatusOK, strings.Repeat("0123456789", 10), "", []string{"bytes=0-"}},
+		{"bounded range", "bytes=10-14", http.StatusPartialContent, "01234", "bytes 10-14/100", []string{"bytes=10-"}},
+		{"open range", "bytes=95-", http.StatusPartialContent, "56789", "bytes 95-99/100", []string{"bytes=95-"}},
+		{"suffix range", "bytes=-3", http.StatusPartialContent, "789", "bytes 97-99/100", []string{"bytes=97-"}},
+		{"end past size", "bytes=98-500", http.StatusPartialContent, "89", "bytes 98-99/100", []string{"bytes=98-"}},
+		{"unsatisfiable", "bytes=100-", http.StatusRequestedRangeNotSatisfiable, "", "bytes */100", nil},
+		{"malformed", "bytes=abc", http.StatusRequestedRangeNotSatisfiable, "", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, fake := newFakeS3Storage(t)
+
+			object, info, err := s.Open(context.Background(), "videos/job-1/video.mp4")
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			defer object.Close()
+			if info.Size != 100 || info.ETag != `"abc123"` || info.ContentType != "video/mp4" || !info.ModTime.Equal(fake.modTime) {
+				t.Fatalf("info = %+v", info)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+			if tt.rangeValue != "" {
+				req.Header.Set("Range", tt.rangeValue)
+			}
+			w := httptest.NewRecorder()
+			// As in the media handler, a known content type spares ServeContent from sniffing the first bytes
+			w.Header().Set("Content-Type", info.ContentType)
+			http.ServeContent(w, req, "", info.ModTime, object)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus < 300 && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if got := w.Header().Get("Content-Range"); got != tt.wantRange {
+				t.Errorf("Content-Range = %q, want %q", got, tt.wantRange)
+			}
+
+			// Only the requested bytes are downloaded from the bucket
+			gets := fake.gets()
+			if len(gets) != len(tt.wantGets) {
+				t.Fatalf("S3 GETs = %q, want %q", gets, tt.wantGets)
+			}
+			for i := range gets {
+				if gets[i] != tt.wantGets[i] {
+					t.Errorf("S3 GET %d range = %q, want %q", i, gets[i], tt.wantGets[i])
+				}
+			}
+		})
+	}
+}
+
+func TestS3ObjectSeek(t *testing.T) {
+	s, fake := newFakeS3Storage(t)
+
+	object, _, err := s.Open(context.Background(), "videos/job-1/video.mp4")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer object.Close()
+
+	read := func(n int) string {
+		t.Helper()
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(object, buf); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		return string(buf)
+	}
+
+	if got := read(3); got != "012" {
+		t.Errorf("first read = %q", got)
+	}
+	// Reading on from the current offset reuses the open download
+	if _, err := object.Seek(0, io.SeekCurrent); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(2); got != "34" {
+		t.Errorf("continued read = %q", got)
+	}
+	if offset, err := object.Seek(-4, io.SeekEnd); err != nil || offset != 96 {
+		t.Fatalf("Seek(-4, end) = %d, %v", offset, err)
+	}
+	if got := read(4); got != "6789" {
+		t.Errorf("read after seek = %q", got)
+	}
+	if n, err := object.Read(make([]byte, 1)); n != 0 || err != io.EOF {
+		t.Errorf("read at end = %d, %v, want EOF", n, err)
+	}
+	if _, err := object.Seek(-1, io.SeekStart); err == nil {
+		t.Error("negative seek accepted")
+	}
+
+	want := []string{"bytes=0-", "bytes=96-"}
+	if gets := fake.gets(); len(gets) != len(want) || gets[0] != want[0] || gets[1] != want[1] {
+		t.Errorf("S3 GETs = %q, want %q", gets, want)
+	}
+}
+
+func TestS3OpenMissingObject(t *testing.T) {
+	s, _ := newFakeS3Storage(t)
+
+	if _, _, err := s.Open(context.Background(), "videos/missing.mp4"); err == nil || !strings.Contains(err.Error(), "file does not exist") {
+		t.Errorf("Open(missing) = %v, want a not-exist error", err)
+	}
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+	"go.uber.org/zap"
+)
+
+// ProviderHealthMonitor periodically refreshes the provider health cache
+type ProviderHealthMonitor struct {
+	providerSelector service.ProviderSelector
+	interval         time.Duration
+	logger           *zap.Logger
+	stopChan         chan struct{}
+}
+
+// NewProviderHealthMonitor creates a new provider health monitor
+func NewProviderHealthMonitor(
+	providerSelector service.ProviderSelector,
+	interval time.Duration,
+	logger *zap.Logger,
+) *ProviderHealthMonitor {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	return &ProviderHealthMonitor{
+		providerSelector: providerSelector,
+		interval:         interval,
+		logger:           logger,
+		stopChan:         make(chan struct{}),
+	}
+}
+
+// Start starts the monitor in a goroutine
+func (m *ProviderHealthMonitor) Start(ctx context.Context) {
+	go m.run(ctx)
+}
+
+// Stop stops the monitor
+func (m *ProviderHealthMonitor) Stop() {
+	close(m.stopChan)
+}
+
+// run is the main monitor loop
+func (m *ProviderHealthMonitor) run(ctx context.Context) {
+	m.logger.Info("Provider health monitor started", zap.Duration("interval", m.interval))
+	defer m.logger.Info("Provider health monitor stopped")
+
+	// Populate the cache right away so selection doesn't start blind
+	m.refresh(ctx)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.refresh(ctx)
+		}
+	}
+}
+
+// refresh refreshes the health of all providers
+func (m *ProviderHealthMonitor) refresh(ctx context.Context) {
+	if err := m.providerSelector.RefreshHealth(ctx); err != nil {
+		m.logger.Error("Failed to refresh provider health", zap.Error(err))
+	}
+}
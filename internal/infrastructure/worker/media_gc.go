@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/usecase"
+	"go.uber.org/zap"
+)
+
+// MediaCollector deletes stored media nothing references any more
+type MediaCollector interface {
+	CollectGarbage(ctx context.Context, dryRun bool) (*usecase.MediaGCReport, error)
+}
+
+// MediaGarbageCollector periodically sweeps storage for orphaned media
+type MediaGarbageCollector struct {
+	collector MediaCollector
+	interval  time.Duration
+	dryRun    bool
+	logger    *zap.Logger
+	stopChan  chan struct{}
+}
+
+// NewMediaGarbageCollector creates a new media garbage collector. In dry-run mode it only logs
+// what it would delete.
+func NewMediaGarbageCollector(
+	collector MediaCollector,
+	interval time.Duration,
+	dryRun bool,
+	logger *zap.Logger,
+) *MediaGarbageCollector {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	return &MediaGarbageCollector{
+		collector: collector,
+		interval:  interval,
+		dryRun:    dryRun,
+		logger:    logger,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start starts the collector in a goroutine
+func (g *MediaGarbageCollector) Start(ctx context.Context) {
+	go g.run(ctx)
+}
+
+// Stop stops the collector
+func (g *MediaGarbageCollector) Stop() {
+	close(g.stopChan)
+}
+
+// run is the main collector loop. The first sweep waits a full interval so restarts don't hit
+// storage with a listing every time.
+func (g *MediaGarbageCollector) run(ctx context.Context) {
+	g.logger.Info("Media garbage collector started",
+		zap.Duration("interval", g.interval),
+		zap.Bool("dry_run", g.dryRun),
+	)
+	defer g.logger.Info("Media garbage collector stopped")
+
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-g.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.collect(ctx)
+		}
+	}
+}
+
+// collect runs one sweep and logs its report
+func (g *MediaGarbageCollector) collect(ctx context.Context) {
+	report, err := g.collector.CollectGarbage(ctx, g.dryRun)
+	if err != nil {
+		g.logger.Error("Media garbage collection failed", zap.Error(err))
+		return
+	}
+
+	for _, store := range report.Stores {
+		g.logger.Info("Media store swept",
+			zap.String("store", store.Store),
+			zap.Int("scanned", store.Scanned),
+			zap.Int("orphaned", store.Orphaned),
+			zap.Int("deleted", store.Deleted),
+			zap.Int("failed", store.Failed),
+			zap.Int64("bytes_reclaimed", store.BytesReclaimed),
+		)
+	}
+	g.logger.Info("Media garbage collection finished",
+		zap.Bool("dry_run", report.DryRun),
+		zap.Int("orphaned", report.Orphaned),
+		zap.Int("deleted", report.Deleted),
+		zap.Int64("bytes_reclaimed", report.BytesReclaimed),
+		zap.Duration("took", report.FinishedAt.Sub(report.StartedAt)),
+	)
+}
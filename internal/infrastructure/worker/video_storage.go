@@ -0,0 +1,309 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"go.uber.org/zap"
+)
+
+const (
+	// uploadingProgress is the progress reported while a result is copied into media storage
+	uploadingProgress = 95
+
+	// maxStoredVideoBytes is the largest provider result copied into media storage
+	maxStoredVideoBytes = 2 << 30
+
+	// maxStoredThumbnailBytes is the largest provider thumbnail copied into media storage
+	maxStoredThumbnailBytes = 10 << 20
+
+	// mediaDownloadTimeout bounds downloading a single result from a provider
+	mediaDownloadTimeout = 10 * time.Minute
+
+	// storeAttempts is how many times copying a video is tried before the job fails
+	storeAttempts = 3
+)
+
+// storeResult copies a provider result into media storage and points the job at the stored copy,
+// then measures it and derives a poster and preview from it. Provider-hosted URLs expire, so a job
+// only completes once its video is stored. Videos of free-tier users are watermarked, keeping the clean original.
+func (w *VideoWorker) storeResult(ctx context.Context, job *entity.VideoJob, result *entity.VideoResult) error {
+	videoKey := fmt.Sprintf("videos/%s/%s.mp4", job.UserID, job.ID)
+	watermark := w.media.Watermark && w.needsWatermark(ctx, job)
+
+	storeKey := videoKey
+	if watermark {
+		storeKey = fmt.Sprintf("sources/%s/%s.mp4", job.UserID, job.ID)
+	}
+	video, err := w.storeVideo(ctx, job, result.VideoURL, storeKey)
+	if err != nil {
+		return fmt.Errorf("%w: %v", entity.ErrStorageUploadFailed, err)
+	}
+	defer discard(video)
+	w.probeVideo(ctx, job, video.Name())
+
+	// Streaming renditions are cut from the video users get; posters and previews stay clean
+	streamed := video
+	if watermark {
+		watermarked, err := w.storeWatermarked(ctx, video.Name(), videoKey)
+		if err != nil {
+			return fmt.Errorf("%w: %v", entity.ErrStorageUploadFailed, err)
+		}
+		defer discard(watermarked)
+		job.StoreWatermarked(videoKey, w.mediaStorage.URL(videoKey), storeKey)
+		streamed = watermarked
+	} else {
+		job.StoreVideo(videoKey, w.mediaStorage.URL(videoKey))
+	}
+
+	thumbnailKey := fmt.Sprintf("thumbnails/%s/%s.jpg", job.UserID, job.ID)
+	if w.storeDerived(ctx, job, "poster", video.Name(), thumbnailKey, "image/jpeg", w.mediaProcessor.ExtractPoster) {
+		job.StoreThumbnail(thumbnailKey, w.mediaStorage.URL(thumbnailKey))
+	} else if result.ThumbnailURL != "" && isRemoteURL(result.ThumbnailURL) {
+		// Fall back to the provider's thumbnail; it is a convenience, so a failed copy keeps the provider's URL
+		if err := w.copyToStorage(ctx, result.ThumbnailURL, thumbnailKey, "image/jpeg", maxStoredThumbnailBytes); err != nil {
+			w.logger.Warn("Failed to store thumbnail",
+				zap.String("job_id", job.ID.String()),
+				zap.Error(err),
+			)
+		} else {
+			job.StoreThumbnail(thumbnailKey, w.mediaStorage.URL(thumbnailKey))
+		}
+	}
+
+	previewKey := fmt.Sprintf("previews/%s/%s.gif", job.UserID, job.ID)
+	if w.storeDerived(ctx, job, "preview", video.Name(), previewKey, "image/gif", w.mediaProcessor.ExtractPreview) {
+		job.StorePreview(previewKey, w.mediaStorage.URL(previewKey))
+	}
+
+	if w.media.HLS {
+		w.storeHLS(ctx, job, streamed.Name())
+	}
+
+	return nil
+}
+
+// probeVideo records the measured metadata of the provider's video on the job, warning when it
+// differs from the requested params. Probing is informational; on failure the job keeps the reported values.
+func (w *VideoWorker) probeVideo(ctx context.Context, job *entity.VideoJob, videoPath string) {
+	metadata, err := w.mediaProcessor.ProbeVideo(ctx, videoPath)
+	if errors.Is(err, entity.ErrMediaToolUnavailable) {
+		w.logger.Debug("Skipping probe: ffprobe is not available", zap.String("job_id", job.ID.String()))
+		return
+	}
+	if err != nil {
+		w.logger.Warn("Failed to probe video",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	// A stream ffprobe could not fully describe leaves fields unset rather than failing the probe
+	if metadata.Width == 0 || metadata.Height == 0 || metadata.FPS == 0 {
+		w.logger.Warn("Probe returned incomplete video metadata",
+			zap.String("job_id", job.ID.String()),
+			zap.Int("width", metadata.Width),
+			zap.Int("height", metadata.Height),
+			zap.Float64("fps", metadata.FPS),
+		)
+	}
+
+	job.RecordMetadata(*metadata)
+	if len(job.Metadata.Mismatches) > 0 {
+		w.logger.Warn("Video does not match requested parameters",
+			zap.String("job_id", job.ID.String()),
+			zap.String("provider", string(job.Provider)),
+			zap.Any("mismatches", job.Metadata.Mismatches),
+		)
+	}
+}
+
+// needsWatermark reports whether the job's owner is on the free tier. If the owner cannot be
+// loaded the video is watermarked, since a missing watermark cannot be added later.
+func (w *VideoWorker) needsWatermark(ctx context.Context, job *entity.VideoJob) bool {
+	user, err := w.userRepo.GetByID(ctx, job.UserID)
+	if err != nil {
+		w.logger.Warn("Failed to get user for watermarking",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err),
+		)
+		return true
+	}
+	return !user.IsPremium()
+}
+
+// storeWatermarked overlays the watermark on the local video and stores the result under key.
+// The watermarked copy is returned for post-processing; the caller must discard it.
+func (w *VideoWorker) storeWatermarked(ctx context.Context, videoPath, key string) (*os.File, error) {
+	file, err := os.CreateTemp("", "watermarked-*.mp4")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	err = w.mediaProcessor.ApplyWatermark(ctx, videoPath, file.Name())
+	var stat os.FileInfo
+	if err == nil {
+		stat, err = file.Stat()
+	}
+	if err == nil {
+		err = w.mediaStorage.Put(ctx, key, file, stat.Size(), "video/mp4")
+	}
+	if err != nil {
+		discard(file)
+		return nil, fmt.Errorf("failed to watermark video: %w", err)
+	}
+	return file, nil
+}
+
+// storeHLS transcodes the local video into an HLS ladder and stores every playlist and segment.
+// Adaptive streaming is optional; on failure the job keeps only its MP4.
+func (w *VideoWorker) storeHLS(ctx context.Context, job *entity.VideoJob, videoPath string) {
+	playlistKey, err := w.hls.Publish(ctx, videoPath, fmt.Sprintf("hls/%s/%s", job.UserID, job.ID))
+	if errors.Is(err, entity.ErrMediaToolUnavailable) {
+		w.logger.Debug("Skipping HLS: ffmpeg is not available", zap.String("job_id", job.ID.String()))
+		return
+	}
+	if err != nil {
+		w.logger.Warn("Failed to create HLS renditions",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	job.StoreHLS(playlistKey, w.mediaStorage.URL(playlistKey))
+}
+
+// storeVideo copies the video at sourceURL to key, retrying transient failures. The spooled
+// local copy is returned for post-processing; the caller must discard it.
+func (w *VideoWorker) storeVideo(ctx context.Context, job *entity.VideoJob, sourceURL, key string) (*os.File, error) {
+	var err error
+	for attempt := 1; attempt <= storeAttempts; attempt++ {
+		var video *os.File
+		var size int64
+		var contentType string
+		if video, size, contentType, err = w.spool(ctx, sourceURL, "video/mp4", maxStoredVideoBytes); err == nil {
+			if err = w.mediaStorage.Put(ctx, key, video, size, contentType); err == nil {
+				return video, nil
+			}
+			discard(video)
+		}
+
+		w.logger.Warn("Failed to store video",
+			zap.String("job_id", job.ID.String()),
+			zap.Int("attempt", attempt),
+			zap.Error(err),
+		)
+		if attempt < storeAttempts {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Duration(attempt) * 5 * time.Second):
+			}
+		}
+	}
+	return nil, err
+}
+
+// storeDerived renders a derived image (poster or preview) from the local video and stores it under key.
+// Derived media is optional, so failures, including a missing ffmpeg, are logged and reported as false.
+func (w *VideoWorker) storeDerived(
+	ctx context.Context,
+	job *entity.VideoJob,
+	kind, videoPath, key, contentType string,
+	extract func(ctx context.Context, videoPath string) ([]byte, error),
+) bool {
+	data, err := extract(ctx, videoPath)
+	if errors.Is(err, entity.ErrMediaToolUnavailable) {
+		w.logger.Debug("Skipping "+kind+": ffmpeg is not available", zap.String("job_id", job.ID.String()))
+		return false
+	}
+	if err == nil {
+		err = w.mediaStorage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+	}
+	if err != nil {
+		w.logger.Warn("Failed to create "+kind,
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err),
+		)
+		return false
+	}
+	return true
+}
+
+// copyToStorage downloads sourceURL and stores it under key
+func (w *VideoWorker) copyToStorage(ctx context.Context, sourceURL, key, defaultContentType string, maxBytes int64) error {
+	file, size, contentType, err := w.spool(ctx, sourceURL, defaultContentType, maxBytes)
+	if err != nil {
+		return err
+	}
+	defer discard(file)
+
+	return w.mediaStorage.Put(ctx, key, file, size, contentType)
+}
+
+// spool downloads sourceURL to a temporary file, returned rewound with its size and content type.
+// Spooling gives uploads a known size and means a whole video is never held in memory.
+func (w *VideoWorker) spool(ctx context.Context, sourceURL, defaultContentType string, maxBytes int64) (*os.File, int64, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("invalid result URL: %w", err)
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("download failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, "", fmt.Errorf("download failed with status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, 0, "", fmt.Errorf("result is %d bytes, more than the %d byte limit", resp.ContentLength, maxBytes)
+	}
+
+	file, err := os.CreateTemp("", "media-*")
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	size, err := io.Copy(file, io.LimitReader(resp.Body, maxBytes+1))
+	if err == nil && size > maxBytes {
+		err = fmt.Errorf("result is more than the %d byte limit", maxBytes)
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		discard(file)
+		return nil, 0, "", fmt.Errorf("download failed: %w", err)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, strings.Split(defaultContentType, "/")[0]+"/") {
+		contentType = defaultContentType
+	}
+
+	return file, size, contentType, nil
+}
+
+// discard closes and removes a temporary file
+func discard(file *os.File) {
+	file.Close()
+	os.Remove(file.Name())
+}
+
+// isRemoteURL reports whether rawURL is an http(s) URL that can be downloaded
+func isRemoteURL(rawURL string) bool {
+	return strings.HasPrefix(rawURL, "http://") || strings.HasPrefix(rawURL, "https://")
+}
@@ -2,7 +2,10 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/arabella/ai-studio-backend/internal/domain/entity"
@@ -17,42 +20,137 @@ type VideoWorker struct {
 	jobRepo          repository.VideoJobRepository
 	templateRepo     repository.TemplateRepository
 	userRepo         repository.UserRepository
+	assetRepo        repository.UserAssetRepository
+	assetURLs        AssetURLSigner
+	mediaStorage     service.MediaStorage
+	mediaURLs        MediaURLSigner
+	mediaProcessor   MediaProcessor
+	media            MediaConfig
+	hls              HLSPublisher
 	providerSelector service.ProviderSelector
 	queue            QueueService
 	wsHub            WebSocketHub
+	poll             PollConfig
+	httpClient       *http.Client
 	logger           *zap.Logger
 	stopChan         chan struct{}
 }
 
+// PollConfig controls how the worker polls providers for job completion
+type PollConfig struct {
+	Interval             time.Duration
+	Timeout              time.Duration
+	MaxConsecutiveErrors int
+}
+
+// DefaultPollConfig polls every 5 seconds for up to 30 minutes and fails after 5 consecutive errors
+func DefaultPollConfig() PollConfig {
+	return PollConfig{
+		Interval:             5 * time.Second,
+		Timeout:              30 * time.Minute,
+		MaxConsecutiveErrors: 5,
+	}
+}
+
 // QueueService interface for queue operations
 type QueueService interface {
 	Dequeue(ctx context.Context) (*entity.VideoJob, error)
+	Requeue(ctx context.Context, job *entity.VideoJob, delay time.Duration) error
 	UpdateJobStatus(ctx context.Context, jobID uuid.UUID, status entity.JobStatus, progress int) error
 }
 
+// AssetURLSigner issues signed URLs providers can fetch private user assets from
+type AssetURLSigner interface {
+	SignAssetURL(assetID uuid.UUID) string
+}
+
+// MediaURLSigner issues signed, expiring links to stored media
+type MediaURLSigner interface {
+	SignMediaURL(key string) string
+	SignMediaDirURL(key string) string
+}
+
+// MediaConfig controls optional post-processing of completed videos
+type MediaConfig struct {
+	HLS       bool // Transcode videos into an adaptive HLS ladder
+	Watermark bool // Overlay the configured logo on videos of free-tier users
+}
+
+// MediaProcessor measures a local video file and derives posters, previews and watermarked copies from it
+type MediaProcessor interface {
+	ProbeVideo(ctx context.Context, videoPath string) (*entity.VideoMetadata, error)
+	ExtractPoster(ctx context.Context, videoPath string) ([]byte, error)
+	ExtractPreview(ctx context.Context, videoPath string) ([]byte, error)
+	ApplyWatermark(ctx context.Context, videoPath, outPath string) error
+}
+
+// HLSPublisher transcodes a local video into an HLS ladder stored under a key prefix
+type HLSPublisher interface {
+	Publish(ctx context.Context, videoPath, prefix string) (playlistKey string, err error)
+}
+
 // WebSocketHub interface for broadcasting updates
 type WebSocketHub interface {
 	BroadcastToJob(jobID uuid.UUID, eventType string, payload interface{})
 	BroadcastToUser(userID uuid.UUID, eventType string, payload interface{})
 }
 
+const (
+	// throttleRetryDelay is how long a throttled job waits when the provider gives no retry hint
+	throttleRetryDelay = 30 * time.Second
+
+	// quotaRetryDelay is how long a job waits when every provider has used its daily quota
+	quotaRetryDelay = 15 * time.Minute
+
+	// maxDeferral is how long after creation a job may keep being deferred before it fails
+	maxDeferral = 2 * time.Hour
+)
+
 // NewVideoWorker creates a new video worker
 func NewVideoWorker(
 	jobRepo repository.VideoJobRepository,
 	templateRepo repository.TemplateRepository,
 	userRepo repository.UserRepository,
+	assetRepo repository.UserAssetRepository,
+	assetURLs AssetURLSigner,
+	mediaStorage service.MediaStorage,
+	mediaURLs MediaURLSigner,
+	mediaProcessor MediaProcessor,
+	media MediaConfig,
+	hls HLSPublisher,
 	providerSelector service.ProviderSelector,
 	queue QueueService,
 	wsHub WebSocketHub,
+	poll PollConfig,
 	logger *zap.Logger,
 ) *VideoWorker {
+	defaults := DefaultPollConfig()
+	if poll.Interval <= 0 {
+		poll.Interval = defaults.Interval
+	}
+	if poll.Timeout <= 0 {
+		poll.Timeout = defaults.Timeout
+	}
+	if poll.MaxConsecutiveErrors <= 0 {
+		poll.MaxConsecutiveErrors = defaults.MaxConsecutiveErrors
+	}
+
 	return &VideoWorker{
 		jobRepo:          jobRepo,
 		templateRepo:     templateRepo,
 		userRepo:         userRepo,
+		assetRepo:        assetRepo,
+		assetURLs:        assetURLs,
+		mediaStorage:     mediaStorage,
+		mediaURLs:        mediaURLs,
+		mediaProcessor:   mediaProcessor,
+		media:            media,
+		hls:              hls,
 		providerSelector: providerSelector,
 		queue:            queue,
 		wsHub:            wsHub,
+		poll:             poll,
+		httpClient:       &http.Client{Timeout: mediaDownloadTimeout},
 		logger:           logger,
 		stopChan:         make(chan struct{}),
 	}
@@ -114,7 +212,7 @@ func (w *VideoWorker) processNextJob(ctx context.Context) {
 // processJob processes a single video generation job
 func (w *VideoWorker) processJob(ctx context.Context, job *entity.VideoJob) {
 	// Update job status to processing
-	job.StartProcessing(entity.ProviderWanAI) // Default to Wan AI
+	job.StartProcessing()
 	if err := w.jobRepo.Update(ctx, job); err != nil {
 		w.logger.Error("Failed to update job status", zap.Error(err))
 		return
@@ -140,31 +238,46 @@ func (w *VideoWorker) processJob(ctx context.Context, job *entity.VideoJob) {
 		return
 	}
 
-	// Select provider
+	// Select provider: request override, then experiment arm, then template preference, then routing policy
 	providerReq := service.ProviderSelectionRequest{
+		JobID:              job.ID.String(),
+		TemplateID:         template.ID.String(),
 		UserTier:           user.Tier,
+		RequestedProvider:  job.RequestedProvider,
 		RequiredResolution: job.Params.Resolution,
 		RequiredDuration:   job.Params.Duration,
+		RequiredFPS:        job.Params.FPS,
 		AspectRatio:        job.Params.AspectRatio,
+		Advanced:           job.Params.Advanced,
+		RequiresImageInput: job.StartImageID != nil,
+		RequiresAudioInput: job.AudioID != nil,
+	}
+	if template.PreferredProvider != nil && *template.PreferredProvider != "" {
+		templateProvider := entity.AIProvider(*template.PreferredProvider)
+		providerReq.TemplateProvider = &templateProvider
 	}
 
-	// Prefer Wan AI
-	wanAIProvider := entity.ProviderWanAI
-	providerReq.PreferredProvider = &wanAIProvider
-
-	provider, err := w.providerSelector.SelectProvider(ctx, providerReq)
+	selection, err := w.providerSelector.SelectProvider(ctx, providerReq)
+	if err != nil && w.deferJob(ctx, job, err) {
+		return
+	}
 	if err != nil {
 		w.failJob(ctx, job, fmt.Sprintf("Failed to select provider: %v", err))
 		return
 	}
+	provider := selection.Provider
 
 	w.logger.Info("Selected provider",
 		zap.String("job_id", job.ID.String()),
 		zap.String("provider", string(provider.GetName())),
+		zap.String("reason", selection.Reason),
 	)
 
 	// Update job with provider
-	job.Provider = provider.GetName()
+	job.AssignProvider(provider.GetName(), selection.Reason)
+	if selection.Experiment != "" {
+		job.AssignExperiment(selection.Experiment, selection.Arm)
+	}
 	if err := w.jobRepo.Update(ctx, job); err != nil {
 		w.logger.Error("Failed to update job provider", zap.Error(err))
 	}
@@ -177,7 +290,26 @@ func (w *VideoWorker) processJob(ctx context.Context, job *entity.VideoJob) {
 		TemplateID:   template.ID.String(),
 		BasePrompt:   template.BasePrompt,
 		ThumbnailURL: template.ThumbnailURL, // Pass template thumbnail for image-to-video
+		TemplateTags: template.Tags,
 		UserTier:     user.Tier,
+		Model:        selection.Model,
+		MockScenario: job.MockScenario,
+	}
+	if job.StartImageID != nil {
+		asset, err := w.assetRepo.GetByID(ctx, *job.StartImageID)
+		if err != nil {
+			w.failJob(ctx, job, fmt.Sprintf("Start image is no longer available: %v", err))
+			return
+		}
+		genReq.StartImageURL = w.assetURLs.SignAssetURL(asset.ID)
+	}
+	if job.AudioID != nil {
+		asset, err := w.assetRepo.GetByID(ctx, *job.AudioID)
+		if err != nil {
+			w.failJob(ctx, job, fmt.Sprintf("Soundtrack is no longer available: %v", err))
+			return
+		}
+		genReq.AudioURL = w.assetURLs.SignAssetURL(asset.ID)
 	}
 
 	w.logger.Info("Calling provider to generate video",
@@ -187,52 +319,39 @@ func (w *VideoWorker) processJob(ctx context.Context, job *entity.VideoJob) {
 	)
 
 	result, err := provider.GenerateVideo(ctx, genReq)
+	if err != nil && w.deferJob(ctx, job, err) {
+		return
+	}
 	if err != nil {
 		w.failJob(ctx, job, fmt.Sprintf("Video generation failed: %v", err))
 		return
 	}
 
-	// Update job with provider job ID
-	job.SetProviderJobID(result.ProviderJobID)
-
-	// If video URL is already available (e.g., from mock provider), complete immediately
-	if result.VideoURL != "" {
-		w.logger.Info("Video URL available immediately",
+	// Record the estimated cost against the provider's budget
+	if err := w.providerSelector.RecordSpend(ctx, provider, providerReq); err != nil {
+		w.logger.Warn("Failed to record provider spend",
 			zap.String("job_id", job.ID.String()),
-			zap.String("video_url", result.VideoURL),
+			zap.String("provider", string(provider.GetName())),
+			zap.Error(err),
 		)
+	}
 
-		thumbnailURL := result.ThumbnailURL
-		if thumbnailURL == "" {
-			thumbnailURL = ""
-		}
-
-		duration := result.Duration
-		if duration == 0 {
-			duration = job.Params.Duration
-			if duration == 0 {
-				duration = 15 // Default
-			}
-		}
-
-		job.Complete(result.VideoURL, thumbnailURL, duration)
-		if err := w.jobRepo.Update(ctx, job); err != nil {
-			w.logger.Error("Failed to complete job", zap.Error(err))
-			return
-		}
+	// Update job with provider job ID and the API key that submitted it
+	job.SetProviderJobID(result.ProviderJobID)
+	job.SetProviderKeyID(result.ProviderKeyID)
+	job.SetSeed(result.Seed)
 
-		w.queue.UpdateJobStatus(ctx, job.ID, job.Status, job.Progress)
-		w.wsHub.BroadcastToJob(job.ID, "completed", map[string]interface{}{
-			"status":        job.Status,
-			"progress":      job.Progress,
-			"video_url":     job.VideoURL,
-			"thumbnail_url": job.ThumbnailURL,
-		})
+	// Status calls must use the key that submitted the job
+	if job.ProviderKeyID != nil {
+		ctx = service.WithProviderKeyID(ctx, *job.ProviderKeyID)
+	}
 
-		w.logger.Info("Video job completed immediately",
+	// If the provider finished synchronously (e.g., mock provider), complete immediately
+	if result.VideoURL != "" {
+		w.logger.Info("Video available immediately",
 			zap.String("job_id", job.ID.String()),
-			zap.String("video_url", result.VideoURL),
 		)
+		w.completeJob(ctx, job, provider)
 		return
 	}
 
@@ -246,13 +365,13 @@ func (w *VideoWorker) processJob(ctx context.Context, job *entity.VideoJob) {
 
 // pollForCompletion polls the provider for job completion
 func (w *VideoWorker) pollForCompletion(ctx context.Context, job *entity.VideoJob, provider service.VideoProvider) {
-	ticker := time.NewTicker(5 * time.Second) // Poll every 5 seconds (reduced frequency)
+	ticker := time.NewTicker(w.poll.Interval)
 	defer ticker.Stop()
 
-	maxAttempts := 360 // Max 30 minutes (360 * 5 seconds = 30 minutes)
+	maxAttempts := int(w.poll.Timeout / w.poll.Interval)
 	attempts := 0
 	consecutiveErrors := 0
-	maxConsecutiveErrors := 5 // Fail after 5 consecutive errors (25 seconds)
+	maxConsecutiveErrors := w.poll.MaxConsecutiveErrors
 
 	for {
 		select {
@@ -263,7 +382,7 @@ func (w *VideoWorker) pollForCompletion(ctx context.Context, job *entity.VideoJo
 		case <-ticker.C:
 			attempts++
 			if attempts > maxAttempts {
-				w.failJob(ctx, job, "Video generation timeout after 30 minutes")
+				w.failJob(ctx, job, fmt.Sprintf("Video generation timeout after %s", w.poll.Timeout))
 				return
 			}
 
@@ -280,6 +399,13 @@ func (w *VideoWorker) pollForCompletion(ctx context.Context, job *entity.VideoJo
 			}
 
 			progress, err := provider.GetProgress(ctx, *job.ProviderJobID)
+			if errors.Is(err, entity.ErrProviderRateLimited) {
+				// Throttled polls are retried on the next tick and don't count as errors
+				w.logger.Debug("Progress check throttled",
+					zap.String("job_id", job.ID.String()),
+				)
+				continue
+			}
 			if err != nil {
 				consecutiveErrors++
 				w.logger.Warn("Failed to get progress",
@@ -337,79 +463,113 @@ func (w *VideoWorker) completeJob(ctx context.Context, job *entity.VideoJob, pro
 		return
 	}
 
-	// Get video URL from provider
-	var videoURL string
-	var thumbnailURL string
+	// Get the final result from the provider
+	result, err := provider.GetResult(ctx, *job.ProviderJobID)
+	if err != nil {
+		w.failJob(ctx, job, fmt.Sprintf("Failed to retrieve video result: %v", err))
+		return
+	}
+	if err := validateResult(result); err != nil {
+		w.failJob(ctx, job, fmt.Sprintf("Provider returned an invalid result: %v", err))
+		return
+	}
 
-	if job.Provider == entity.ProviderWanAI {
-		// For DashScope (Wan AI), fetch the video URL from the task status
-		// Use type assertion to call GetVideoURL if available
-		if wanAIProvider, ok := provider.(interface {
-			GetVideoURL(ctx context.Context, providerJobID string) (string, error)
-		}); ok {
-			var err error
-			videoURL, err = wanAIProvider.GetVideoURL(ctx, *job.ProviderJobID)
-			if err != nil {
-				w.logger.Warn("Failed to get video URL from DashScope",
-					zap.String("job_id", job.ID.String()),
-					zap.String("task_id", *job.ProviderJobID),
-					zap.Error(err),
-				)
-				videoURL = "" // Will be empty, but job will be marked as completed
-			}
-		} else {
-			w.logger.Warn("Provider does not support GetVideoURL",
-				zap.String("provider", string(job.Provider)),
-			)
-		}
-		thumbnailURL = ""
-	} else if job.Provider == entity.ProviderMock {
-		// For mock provider, use a working sample video URL
-		videoURL = "https://commondatastorage.googleapis.com/gtv-videos-bucket/sample/BigBuckBunny.mp4"
-		thumbnailURL = ""
-	} else {
-		// For other providers, use a working sample video URL (no storage domain needed)
-		videoURL = "https://commondatastorage.googleapis.com/gtv-videos-bucket/sample/BigBuckBunny.mp4"
-		thumbnailURL = ""
-	}
-
-	// Default duration if not set
-	duration := job.Params.Duration
-	if duration == 0 {
-		duration = 15 // Default 15 seconds
-	}
-
-	// If video URL is still empty, we'll need to fetch it from DashScope
-	// For now, mark as completed and the frontend can poll for the video URL
-	if videoURL == "" && job.Provider == entity.ProviderWanAI {
-		w.logger.Warn("Video URL not available yet, job will be marked as completed",
-			zap.String("job_id", job.ID.String()),
-			zap.String("task_id", *job.ProviderJobID),
-		)
-		// Set a placeholder - the actual URL will be fetched later
-		videoURL = fmt.Sprintf("dashscope://task/%s", *job.ProviderJobID)
+	// Copy the result into our storage before completing, since provider URLs expire
+	job.UpdateProgress(uploadingProgress, entity.JobStatusUploading)
+	if err := w.jobRepo.Update(ctx, job); err != nil {
+		w.logger.Error("Failed to update job status", zap.Error(err))
 	}
+	w.queue.UpdateJobStatus(ctx, job.ID, job.Status, job.Progress)
+	w.wsHub.BroadcastToJob(job.ID, "status_update", map[string]interface{}{
+		"status":   job.Status,
+		"progress": job.Progress,
+		"message":  "Saving video",
+	})
+
+	// The job only completes once its video is stored, so nothing ever sees it completed without one
+	if err := w.storeResult(ctx, job, result); err != nil {
+		w.failJob(ctx, job, fmt.Sprintf("Failed to store video: %v", err))
+		return
+	}
+	job.CompleteWithResult(result)
 
-	job.Complete(videoURL, thumbnailURL, duration)
 	if err := w.jobRepo.Update(ctx, job); err != nil {
 		w.logger.Error("Failed to complete job", zap.Error(err))
 		return
 	}
 
+	// Clients get signed links; the job keeps its unsigned URLs
+	signed := *job
+	signed.SignMediaURLs(w.mediaURLs.SignMediaURL, w.mediaURLs.SignMediaDirURL)
+
 	w.queue.UpdateJobStatus(ctx, job.ID, job.Status, job.Progress)
 	w.wsHub.BroadcastToJob(job.ID, "completed", map[string]interface{}{
-		"status":        job.Status,
-		"progress":      job.Progress,
-		"video_url":     job.VideoURL,
-		"thumbnail_url": job.ThumbnailURL,
+		"status":           job.Status,
+		"progress":         job.Progress,
+		"video_url":        signed.VideoURL,
+		"thumbnail_url":    signed.ThumbnailURL,
+		"preview_url":      signed.PreviewURL,
+		"hls_playlist_url": signed.HLSPlaylistURL,
+		"duration_seconds": job.DurationSeconds,
+		"metadata":         job.Metadata,
 	})
 
 	w.logger.Info("Video job completed",
 		zap.String("job_id", job.ID.String()),
-		zap.String("video_url", videoURL),
+		zap.String("video_url", *job.VideoURL),
 	)
 }
 
+// deferJob returns a job to the queue when its provider call was throttled or every provider
+// has used its daily quota. It reports false if the error is not retryable or the job has waited too long.
+func (w *VideoWorker) deferJob(ctx context.Context, job *entity.VideoJob, cause error) bool {
+	var delay time.Duration
+	var rateLimitErr *service.RateLimitError
+	switch {
+	case errors.As(cause, &rateLimitErr) && rateLimitErr.RetryAfter > 0:
+		delay = rateLimitErr.RetryAfter
+	case errors.Is(cause, entity.ErrProviderRateLimited):
+		delay = throttleRetryDelay
+	case errors.Is(cause, entity.ErrProviderQuotaExceeded):
+		delay = quotaRetryDelay
+	default:
+		return false
+	}
+
+	if time.Since(job.CreatedAt)+delay > maxDeferral {
+		return false
+	}
+
+	job.Defer(fmt.Sprintf("deferred %s: %v", delay.Round(time.Second), cause))
+	if err := w.jobRepo.Update(ctx, job); err != nil {
+		w.logger.Error("Failed to update deferred job", zap.Error(err))
+		return false
+	}
+
+	if err := w.queue.Requeue(ctx, job, delay); err != nil {
+		w.logger.Error("Failed to requeue deferred job",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	w.queue.UpdateJobStatus(ctx, job.ID, job.Status, job.Progress)
+	w.wsHub.BroadcastToJob(job.ID, "status_update", map[string]interface{}{
+		"status":   job.Status,
+		"progress": job.Progress,
+		"message":  "Waiting for provider capacity",
+	})
+
+	w.logger.Info("Video job deferred",
+		zap.String("job_id", job.ID.String()),
+		zap.Duration("delay", delay),
+		zap.Error(cause),
+	)
+
+	return true
+}
+
 // failJob marks the job as failed
 func (w *VideoWorker) failJob(ctx context.Context, job *entity.VideoJob, errorMsg string) {
 	job.Fail(errorMsg)
@@ -430,4 +590,20 @@ func (w *VideoWorker) failJob(ctx context.Context, job *entity.VideoJob, errorMs
 	)
 }
 
+// validateResult rejects provider results that cannot be served to users
+func validateResult(result *entity.VideoResult) error {
+	if result == nil {
+		return fmt.Errorf("empty result")
+	}
+
+	u, err := url.Parse(result.VideoURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid video URL %q", result.VideoURL)
+	}
 
+	if result.Duration < 0 {
+		return fmt.Errorf("invalid duration %d", result.Duration)
+	}
+
+	return nil
+}
@@ -0,0 +1,343 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/repository"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+	"github.com/arabella/ai-studio-backend/internal/infrastructure/provider"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// testPollConfig polls fast enough for a stalled job to time out within the test
+var testPollConfig = PollConfig{
+	Interval:             5 * time.Millisecond,
+	Timeout:              100 * time.Millisecond,
+	MaxConsecutiveErrors: 3,
+}
+
+type fakeJobRepo struct {
+	repository.VideoJobRepository
+	updates  int
+	statuses []entity.JobStatus // Status saved by each update
+}
+
+func (r *fakeJobRepo) Update(ctx context.Context, job *entity.VideoJob) error {
+	r.updates++
+	r.statuses = append(r.statuses, job.Status)
+	return nil
+}
+
+type fakeTemplateRepo struct {
+	repository.TemplateRepository
+	template *entity.Template
+}
+
+func (r *fakeTemplateRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Template, error) {
+	return r.template, nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	user *entity.User
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	return r.user, nil
+}
+
+type fakeSelector struct {
+	service.ProviderSelector
+	provider service.VideoProvider
+}
+
+func (s *fakeSelector) SelectProvider(ctx context.Context, req service.ProviderSelectionRequest) (*service.ProviderSelection, error) {
+	return &service.ProviderSelection{Provider: s.provider, Reason: "test"}, nil
+}
+
+func (s *fakeSelector) RecordSpend(ctx context.Context, p service.VideoProvider, req service.ProviderSelectionRequest) error {
+	return nil
+}
+
+type requeuedJob struct {
+	job   *entity.VideoJob
+	delay time.Duration
+}
+
+type fakeQueue struct {
+	requeued []requeuedJob
+}
+
+func (q *fakeQueue) Dequeue(ctx context.Context) (*entity.VideoJob, error) {
+	return nil, nil
+}
+
+func (q *fakeQueue) Requeue(ctx context.Context, job *entity.VideoJob, delay time.Duration) error {
+	q.requeued = append(q.requeued, requeuedJob{job: job, delay: delay})
+	return nil
+}
+
+func (q *fakeQueue) UpdateJobStatus(ctx context.Context, jobID uuid.UUID, status entity.JobStatus, progress int) error {
+	return nil
+}
+
+type fakeHub struct {
+	events []string
+}
+
+func (h *fakeHub) BroadcastToJob(jobID uuid.UUID, eventType string, payload interface{}) {
+	h.events = append(h.events, eventType)
+}
+
+func (h *fakeHub) BroadcastToUser(userID uuid.UUID, eventType string, payload interface{}) {}
+
+// workerTest runs one job through a worker backed by the mock provider
+type workerTest struct {
+	worker *VideoWorker
+	jobs   *fakeJobRepo
+	queue  *fakeQueue
+	hub    *fakeHub
+}
+
+func newWorkerTest(completionTime time.Duration) *workerTest {
+	return newStoringWorkerTest(completionTime, "https://cdn.arabella.app/samples/sample.mp4", nil)
+}
+
+// newStoringWorkerTest returns a workerTest whose mock jobs complete with sampleURL, stored in storage
+func newStoringWorkerTest(completionTime time.Duration, sampleURL string, storage service.MediaStorage) *workerTest {
+	logger := zap.NewNop()
+	mock := provider.NewMockProvider(logger, true, completionTime, sampleURL)
+
+	wt := &workerTest{
+		jobs:  &fakeJobRepo{},
+		queue: &fakeQueue{},
+		hub:   &fakeHub{},
+	}
+	wt.worker = NewVideoWorker(
+		wt.jobs,
+		&fakeTemplateRepo{template: &entity.Template{ID: uuid.New(), Name: "Test"}},
+		&fakeUserRepo{user: &entity.User{ID: uuid.New(), Tier: entity.UserTierFree}},
+		nil, nil, storage, nil, nil,
+		MediaConfig{},
+		nil,
+		&fakeSelector{provider: mock},
+		wt.queue,
+		wt.hub,
+		testPollConfig,
+		logger,
+	)
+	return wt
+}
+
+// run processes a job for the scenario and returns it once the worker is done with it
+func (wt *workerTest) run(t *testing.T, scenario provider.MockScenario) *entity.VideoJob {
+	t.Helper()
+
+	job := entity.NewVideoJob(uuid.New(), uuid.New(), "A paper boat", entity.VideoParams{
+		Duration:   5,
+		Resolution: entity.Resolution720p,
+	}, 10)
+	job.MockScenario = string(scenario)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wt.worker.processJob(context.Background(), job)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("worker did not finish the %s job", scenario)
+	}
+	return job
+}
+
+func assertFailed(t *testing.T, job *entity.VideoJob, wantMessage string) {
+	t.Helper()
+
+	if job.Status != entity.JobStatusFailed {
+		t.Fatalf("status = %s, want %s", job.Status, entity.JobStatusFailed)
+	}
+	if job.ErrorMessage == nil || !strings.Contains(*job.ErrorMessage, wantMessage) {
+		t.Errorf("error message = %v, want it to contain %q", job.ErrorMessage, wantMessage)
+	}
+}
+
+func TestVideoWorkerFailSubmitFailsJob(t *testing.T) {
+	wt := newWorkerTest(time.Hour)
+
+	job := wt.run(t, provider.MockScenarioFailSubmit)
+
+	assertFailed(t, job, "Video generation failed: mock: simulated submission failure")
+	if job.ProviderJobID != nil {
+		t.Errorf("provider job ID = %q, want none for a rejected submission", *job.ProviderJobID)
+	}
+	if len(wt.queue.requeued) != 0 {
+		t.Errorf("failed submission was requeued %d times", len(wt.queue.requeued))
+	}
+}
+
+func TestVideoWorkerFailProgressFailsJob(t *testing.T) {
+	// The mock reports FAILED once the job is halfway through
+	wt := newWorkerTest(20 * time.Millisecond)
+
+	job := wt.run(t, provider.MockScenarioFailProgress)
+
+	assertFailed(t, job, "Mock: simulated generation failure")
+	if job.ProviderJobID == nil {
+		t.Error("provider job ID was not recorded for a submitted job")
+	}
+	var progressed bool
+	for _, event := range wt.hub.events {
+		progressed = progressed || event == "progress_update"
+	}
+	if !progressed {
+		t.Error("no progress_update broadcast before the failure")
+	}
+}
+
+func TestVideoWorkerStallTimesOut(t *testing.T) {
+	wt := newWorkerTest(time.Hour)
+
+	job := wt.run(t, provider.MockScenarioStall)
+
+	assertFailed(t, job, "Video generation timeout after 100ms")
+	if wt.hub.events[len(wt.hub.events)-1] != "failed" {
+		t.Errorf("last event = %s, want failed", wt.hub.events[len(wt.hub.events)-1])
+	}
+}
+
+func TestVideoWorkerProgressErrorsFailAfterMaxConsecutiveErrors(t *testing.T) {
+	wt := newWorkerTest(time.Hour)
+
+	job := wt.run(t, provider.MockScenarioProgressErrors)
+
+	assertFailed(t, job, "Failed to get progress after 3 attempts")
+	for _, event := range wt.hub.events {
+		if event == "progress_update" {
+			t.Errorf("progress_update broadcast for a job whose every poll failed")
+		}
+	}
+}
+
+func TestVideoWorkerRateLimitDefersJob(t *testing.T) {
+	wt := newWorkerTest(time.Hour)
+
+	job := wt.run(t, provider.MockScenarioRateLimit)
+
+	if job.Status != entity.JobStatusPending {
+		t.Fatalf("status = %s, want %s", job.Status, entity.JobStatusPending)
+	}
+	if job.ErrorMessage != nil {
+		t.Errorf("deferred job has error message %q", *job.ErrorMessage)
+	}
+	if len(wt.queue.requeued) != 1 {
+		t.Fatalf("job requeued %d times, want once", len(wt.queue.requeued))
+	}
+	// The mock's retry hint wins over the default throttle delay
+	if delay := wt.queue.requeued[0].delay; delay != 10*time.Second {
+		t.Errorf("requeue delay = %s, want 10s", delay)
+	}
+	if job.ProviderReason == nil || !strings.HasPrefix(*job.ProviderReason, "deferred 10s") {
+		t.Errorf("provider reason = %v, want the deferral", job.ProviderReason)
+	}
+}
+
+func TestVideoWorkerRateLimitFailsAfterMaxDeferral(t *testing.T) {
+	wt := newWorkerTest(time.Hour)
+
+	job := entity.NewVideoJob(uuid.New(), uuid.New(), "A paper boat", entity.VideoParams{Duration: 5}, 10)
+	job.MockScenario = string(provider.MockScenarioRateLimit)
+	job.CreatedAt = time.Now().Add(-maxDeferral)
+	wt.worker.processJob(context.Background(), job)
+
+	assertFailed(t, job, "Video generation failed")
+	if len(wt.queue.requeued) != 0 {
+		t.Errorf("job requeued after waiting longer than %s", maxDeferral)
+	}
+}
+
+func TestVideoWorkerMalformedResultIsRejected(t *testing.T) {
+	wt := newWorkerTest(10 * time.Millisecond)
+
+	job := wt.run(t, provider.MockScenarioMalformedResult)
+
+	assertFailed(t, job, "Provider returned an invalid result")
+	if job.VideoURL != nil {
+		t.Errorf("video URL = %q, want none for a rejected result", *job.VideoURL)
+	}
+}
+
+func TestValidateResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *entity.VideoResult
+		wantErr bool
+	}{
+		{"valid", &entity.VideoResult{VideoURL: "https://cdn.example.com/v.mp4", Duration: 5}, false},
+		{"unknown duration", &entity.VideoResult{VideoURL: "http://cdn.example.com/v.mp4"}, false},
+		{"nil", nil, true},
+		{"empty URL", &entity.VideoResult{}, true},
+		{"non-http scheme", &entity.VideoResult{VideoURL: "mock://malformed"}, true},
+		{"relative URL", &entity.VideoResult{VideoURL: "/videos/v.mp4"}, true},
+		{"negative duration", &entity.VideoResult{VideoURL: "https://cdn.example.com/v.mp4", Duration: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateResult(tt.result); (err != nil) != tt.wantErr {
+				t.Errorf("validateResult() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// failingStorage rejects every upload, cancelling the job's context so the worker stops retrying
+type failingStorage struct {
+	service.MediaStorage
+	cancel context.CancelFunc
+}
+
+func (s *failingStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	s.cancel()
+	return errors.New("bucket unavailable")
+}
+
+func TestVideoWorkerStorageFailureNeverCompletes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "video/mp4")
+		io.WriteString(w, "not really an mp4")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wt := newStoringWorkerTest(10*time.Millisecond, server.URL+"/sample.mp4", &failingStorage{cancel: cancel})
+
+	job := entity.NewVideoJob(uuid.New(), uuid.New(), "A paper boat", entity.VideoParams{Duration: 5}, 10)
+	wt.worker.processJob(ctx, job)
+
+	assertFailed(t, job, "Failed to store video")
+	for _, status := range wt.jobs.statuses {
+		if status == entity.JobStatusCompleted {
+			t.Errorf("job was saved as completed before its video was stored: %v", wt.jobs.statuses)
+		}
+	}
+	for _, event := range wt.hub.events {
+		if event == "completed" {
+			t.Error("completed event broadcast for a job whose video was not stored")
+		}
+	}
+	if job.VideoURL != nil {
+		t.Errorf("failed job points at video %q", *job.VideoURL)
+	}
+}
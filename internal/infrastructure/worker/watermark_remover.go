@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// WatermarkRemovalProcessor runs queued watermark removals
+type WatermarkRemovalProcessor interface {
+	ProcessWatermarkRemoval(ctx context.Context) (bool, error)
+}
+
+// WatermarkRemover runs the watermark removals users queue, which are too slow for the request that
+// asks for them since their HLS renditions are transcoded again
+type WatermarkRemover struct {
+	processor WatermarkRemovalProcessor
+	interval  time.Duration
+	logger    *zap.Logger
+	stopChan  chan struct{}
+}
+
+// NewWatermarkRemover creates a new watermark remover that checks for queued removals every interval
+func NewWatermarkRemover(processor WatermarkRemovalProcessor, interval time.Duration, logger *zap.Logger) *WatermarkRemover {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &WatermarkRemover{
+		processor: processor,
+		interval:  interval,
+		logger:    logger,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start starts the remover in a goroutine
+func (w *WatermarkRemover) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Stop stops the remover
+func (w *WatermarkRemover) Stop() {
+	close(w.stopChan)
+}
+
+// run is the main remover loop
+func (w *WatermarkRemover) run(ctx context.Context) {
+	w.logger.Info("Watermark remover started", zap.Duration("interval", w.interval))
+	defer w.logger.Info("Watermark remover stopped")
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.drain(ctx)
+		}
+	}
+}
+
+// drain runs queued removals one at a time until none is left or the remover is stopped
+func (w *WatermarkRemover) drain(ctx context.Context) {
+	for {
+		select {
+		case <-w.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		processed, err := w.processor.ProcessWatermarkRemoval(ctx)
+		if err != nil {
+			w.logger.Error("Watermark removal failed", zap.Error(err))
+		}
+		if !processed {
+			return
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/arabella/ai-studio-backend/internal/interface/http/middleware"
+	"github.com/arabella/ai-studio-backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxStartImageUploadSize is the largest start image accepted before resizing
+const maxStartImageUploadSize = 10 * 1024 * 1024
+
+// maxAudioUploadSize is the largest soundtrack accepted
+const maxAudioUploadSize = 15 * 1024 * 1024
+
+// AssetHandler handles user asset endpoints
+type AssetHandler struct {
+	assetUseCase *usecase.AssetUseCase
+}
+
+// NewAssetHandler creates a new AssetHandler
+func NewAssetHandler(assetUseCase *usecase.AssetUseCase) *AssetHandler {
+	return &AssetHandler{
+		assetUseCase: assetUseCase,
+	}
+}
+
+// UploadStartImage uploads an image to animate
+// @Summary Upload start image
+// @Description Upload a private image to use as the first frame of an image-to-video generation (jpeg, png or gif, at least 360x360, max 10MB). Images are resized to at most 2000px per side. Pass the returned id as start_image_id when generating.
+// @Tags assets
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Image file"
+// @Success 201 {object} entity.UserAsset
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /assets/images [post]
+func (h *AssetHandler) UploadStartImage(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "Authentication required",
+			Code:  "UNAUTHORIZED",
+		})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "No file provided",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if file.Size > maxStartImageUploadSize {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "File too large. Maximum size is 10MB",
+			Code:    "FILE_TOO_LARGE",
+			Details: fmt.Sprintf("File size: %d bytes", file.Size),
+		})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Failed to read file",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, maxStartImageUploadSize+1))
+	if err != nil || len(data) > maxStartImageUploadSize {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Failed to read file",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	asset, err := h.assetUseCase.UploadStartImage(c.Request.Context(), userID, data)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, asset)
+}
+
+// UploadAudio uploads a soundtrack to synchronise a video to
+// @Summary Upload soundtrack
+// @Description Upload a private soundtrack for audio-driven generation (wav or mp3, 3-30 seconds, max 15MB). Pass the returned id as audio_id when generating; the video duration must match the soundtrack length to within a second.
+// @Tags assets
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Audio file"
+// @Success 201 {object} entity.UserAsset
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /assets/audio [post]
+func (h *AssetHandler) UploadAudio(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "Authentication required",
+			Code:  "UNAUTHORIZED",
+		})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "No file provided",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if file.Size > maxAudioUploadSize {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "File too large. Maximum size is 15MB",
+			Code:    "FILE_TOO_LARGE",
+			Details: fmt.Sprintf("File size: %d bytes", file.Size),
+		})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Failed to read file",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, maxAudioUploadSize+1))
+	if err != nil || len(data) > maxAudioUploadSize {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Failed to read file",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	asset, err := h.assetUseCase.UploadAudio(c.Request.Context(), userID, data)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, asset)
+}
+
+// GetAsset retrieves one of the user's assets
+// @Summary Get asset
+// @Description Get an uploaded asset with a fresh short-lived signed URL
+// @Tags assets
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Asset ID" format(uuid)
+// @Success 200 {object} entity.UserAsset
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /assets/{id} [get]
+func (h *AssetHandler) GetAsset(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "Authentication required",
+			Code:  "UNAUTHORIZED",
+		})
+		return
+	}
+
+	assetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid asset ID",
+			Code:  "INVALID_ID",
+		})
+		return
+	}
+
+	asset, err := h.assetUseCase.GetAsset(c.Request.Context(), userID, assetID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, asset)
+}
+
+// GetAssetContent serves an asset file through a signed URL
+// @Summary Get asset content
+// @Description Serve an asset file. Requires the expires and signature parameters from a signed asset URL.
+// @Tags assets
+// @Produce octet-stream
+// @Param id path string true "Asset ID" format(uuid)
+// @Param expires query string true "Expiry (unix seconds)"
+// @Param signature query string true "URL signature"
+// @Success 200 {file} binary
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /assets/{id}/content [get]
+func (h *AssetHandler) GetAssetContent(c *gin.Context) {
+	assetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid asset ID",
+			Code:  "INVALID_ID",
+		})
+		return
+	}
+
+	asset, content, err := h.assetUseCase.OpenAssetContent(c.Request.Context(), assetID, c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Type", asset.ContentType)
+	c.Header("Cache-Control", "private, max-age=300")
+	http.ServeContent(c.Writer, c.Request, "", asset.CreatedAt, content)
+}
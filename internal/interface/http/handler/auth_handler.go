@@ -105,7 +105,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 
 // TestLogin handles test/demo login without Google OAuth
 // @Summary Test login (skip Google auth)
-// @Description Create or get a test user for development/demo purposes
+// @Description Create or get a test user for development/demo purposes. Only registered when running in development.
 // @Tags auth
 // @Accept json
 // @Produce json
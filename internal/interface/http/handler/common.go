@@ -46,7 +46,9 @@ func handleError(c *gin.Context, err error) {
 	switch {
 	case errors.Is(err, entity.ErrUserNotFound),
 		errors.Is(err, entity.ErrTemplateNotFound),
-		errors.Is(err, entity.ErrJobNotFound):
+		errors.Is(err, entity.ErrJobNotFound),
+		errors.Is(err, entity.ErrAssetNotFound),
+		errors.Is(err, entity.ErrMediaNotFound):
 		c.JSON(http.StatusNotFound, ErrorResponse{
 			Error: err.Error(),
 			Code:  "NOT_FOUND",
@@ -61,7 +63,12 @@ func handleError(c *gin.Context, err error) {
 		})
 
 	case errors.Is(err, entity.ErrInsufficientCredits),
-		errors.Is(err, entity.ErrTemplatePremiumOnly):
+		errors.Is(err, entity.ErrTemplatePremiumOnly),
+		errors.Is(err, entity.ErrProviderOverrideNotAllowed),
+		errors.Is(err, entity.ErrWatermarkRemovalNotAllowed),
+		errors.Is(err, entity.ErrInvalidAssetURL),
+		errors.Is(err, entity.ErrInvalidMediaURL),
+		errors.Is(err, entity.ErrProxyTargetNotAllowed):
 		c.JSON(http.StatusForbidden, ErrorResponse{
 			Error: err.Error(),
 			Code:  "FORBIDDEN",
@@ -75,22 +82,38 @@ func handleError(c *gin.Context, err error) {
 
 	case errors.Is(err, entity.ErrInvalidInput),
 		errors.Is(err, entity.ErrInvalidPrompt),
-		errors.Is(err, entity.ErrInvalidParams):
+		errors.Is(err, entity.ErrInvalidParams),
+		errors.Is(err, entity.ErrUnknownProvider),
+		errors.Is(err, entity.ErrInvalidAsset),
+		errors.Is(err, entity.ErrStartImageNotSupported),
+		errors.Is(err, entity.ErrAudioNotSupported):
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error: err.Error(),
 			Code:  "BAD_REQUEST",
 		})
 
 	case errors.Is(err, entity.ErrProviderUnavailable),
-		errors.Is(err, entity.ErrProviderTimeout):
+		errors.Is(err, entity.ErrProviderTimeout),
+		errors.Is(err, entity.ErrProviderBudgetExceeded),
+		errors.Is(err, entity.ErrProviderQuotaExceeded):
 		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
 			Error: err.Error(),
 			Code:  "SERVICE_UNAVAILABLE",
 		})
 
+	case errors.Is(err, entity.ErrProxyFetchFailed),
+		errors.Is(err, entity.ErrProxyInvalidImage):
+		c.JSON(http.StatusBadGateway, ErrorResponse{
+			Error: err.Error(),
+			Code:  "BAD_GATEWAY",
+		})
+
 	case errors.Is(err, entity.ErrJobCannotBeCancelled),
 		errors.Is(err, entity.ErrJobAlreadyCompleted),
-		errors.Is(err, entity.ErrJobAlreadyCancelled):
+		errors.Is(err, entity.ErrJobAlreadyCancelled),
+		errors.Is(err, entity.ErrVideoNotWatermarked),
+		errors.Is(err, entity.ErrWatermarkRemovalInProgress),
+		errors.Is(err, entity.ErrMediaGCInProgress):
 		c.JSON(http.StatusConflict, ErrorResponse{
 			Error: err.Error(),
 			Code:  "CONFLICT",
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/arabella/ai-studio-backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// MediaGCHandler handles media garbage collection endpoints
+type MediaGCHandler struct {
+	mediaGCUseCase *usecase.MediaGCUseCase
+}
+
+// NewMediaGCHandler creates a new MediaGCHandler
+func NewMediaGCHandler(mediaGCUseCase *usecase.MediaGCUseCase) *MediaGCHandler {
+	return &MediaGCHandler{
+		mediaGCUseCase: mediaGCUseCase,
+	}
+}
+
+// CollectGarbage runs media garbage collection
+// @Summary Collect orphaned media
+// @Description Sweep every storage backend and delete files no template, video or user asset references that are older than the grace period (admin only). Runs as a dry run that only reports what would be deleted unless dry_run=false.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "Report orphans without deleting them" default(true)
+// @Success 200 {object} usecase.MediaGCReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /admin/media/gc [post]
+func (h *MediaGCHandler) CollectGarbage(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "true"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "dry_run must be true or false",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	// A sweep that is cut short by the client disconnecting would leave its report unread, not its work undone
+	report, err := h.mediaGCUseCase.CollectGarbage(context.WithoutCancel(c.Request.Context()), dryRun)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+	"github.com/arabella/ai-studio-backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// MediaHandler serves generated media through signed URLs
+type MediaHandler struct {
+	mediaUseCase *usecase.MediaUseCase
+}
+
+// NewMediaHandler creates a new MediaHandler
+func NewMediaHandler(mediaUseCase *usecase.MediaUseCase) *MediaHandler {
+	return &MediaHandler{
+		mediaUseCase: mediaUseCase,
+	}
+}
+
+// ServeMedia serves a stored video, thumbnail, preview or HLS file
+// @Summary Get media
+// @Description Serve generated media. Links come signed and expiring from the video endpoints; the token is part of the path so HLS playlists can reference their segments relatively.
+// @Tags media
+// @Produce octet-stream
+// @Param token path string true "Signature token from a signed media URL"
+// @Param key path string true "Media key"
+// @Success 200 {file} binary
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /media/{token}/{key} [get]
+func (h *MediaHandler) ServeMedia(c *gin.Context) {
+	content, info, err := h.mediaUseCase.OpenMedia(c.Request.Context(), c.Param("token"), c.Param("key"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.Header("Cache-Control", "private, max-age=300")
+	serveMedia(c, content, info)
+}
+
+// serveMedia writes a stored object, answering conditional and range requests
+// (Range, If-Range, If-None-Match, If-Modified-Since) from its size, modification time and ETag
+func serveMedia(c *gin.Context, content io.ReadSeeker, info service.MediaObjectInfo) {
+	c.Header("Content-Type", info.ContentType)
+	if info.ETag != "" {
+		c.Header("ETag", info.ETag)
+	}
+	http.ServeContent(c.Writer, c.Request, "", info.ModTime, content)
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+	"github.com/gin-gonic/gin"
+)
+
+func TestServeMediaRangeAndConditionalRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	content := strings.Repeat("0123456789", 10)
+	modTime := time.Date(2025, 10, 8, 12, 0, 0, 0, time.UTC)
+	info := service.MediaObjectInfo{
+		Size:        int64(len(content)),
+		ContentType: "video/mp4",
+		ModTime:     modTime,
+		ETag:        `"v1"`,
+	}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+		wantBody   string
+		wantRange  string
+	}{
+		{"full", nil, http.StatusOK, content, ""},
+		{"range", map[string]string{"Range": "bytes=0-4"}, http.StatusPartialContent, "01234", "bytes 0-4/100"},
+		{"suffix range", map[string]string{"Range": "bytes=-2"}, http.StatusPartialContent, "89", "bytes 98-99/100"},
+		{"unsatisfiable range", map[string]string{"Range": "bytes=150-"}, http.StatusRequestedRangeNotSatisfiable, "", "bytes */100"},
+		{"If-Range matching ETag", map[string]string{"Range": "bytes=10-12", "If-Range": `"v1"`}, http.StatusPartialContent, "012", "bytes 10-12/100"},
+		{"If-Range stale ETag", map[string]string{"Range": "bytes=10-12", "If-Range": `"v0"`}, http.StatusOK, content, ""},
+		{"If-Range matching date", map[string]string{"Range": "bytes=10-12", "If-Range": modTime.Format(http.TimeFormat)}, http.StatusPartialContent, "012", "bytes 10-12/100"},
+		{"If-Range older date", map[string]string{"Range": "bytes=10-12", "If-Range": modTime.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK, content, ""},
+		{"If-None-Match", map[string]string{"If-None-Match": `"v1"`}, http.StatusNotModified, "", ""},
+		{"If-Modified-Since", map[string]string{"If-Modified-Since": modTime.Format(http.TimeFormat)}, http.StatusNotModified, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/media", func(c *gin.Context) {
+				serveMedia(c, strings.NewReader(content), info)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/media", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus < 300 && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+			if got := w.Header().Get("Content-Range"); got != tt.wantRange {
+				t.Errorf("Content-Range = %q, want %q", got, tt.wantRange)
+			}
+			if tt.wantStatus == http.StatusOK || tt.wantStatus == http.StatusPartialContent {
+				if got := w.Header().Get("Content-Type"); got != "video/mp4" {
+					t.Errorf("Content-Type = %q, want video/mp4", got)
+				}
+				if got := w.Header().Get("ETag"); got != `"v1"` {
+					t.Errorf("ETag = %q", got)
+				}
+			}
+		})
+	}
+}
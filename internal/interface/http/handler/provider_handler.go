@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/arabella/ai-studio-backend/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// ProviderHandler handles AI provider administration endpoints
+type ProviderHandler struct {
+	providerUseCase *usecase.ProviderUseCase
+}
+
+// NewProviderHandler creates a new ProviderHandler
+func NewProviderHandler(providerUseCase *usecase.ProviderUseCase) *ProviderHandler {
+	return &ProviderHandler{
+		providerUseCase: providerUseCase,
+	}
+}
+
+// GetProviderHealth retrieves provider health
+// @Summary Get provider health
+// @Description Get current and historical health for each AI provider (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} usecase.ProviderHealthResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/providers/health [get]
+func (h *ProviderHandler) GetProviderHealth(c *gin.Context) {
+	response, err := h.providerUseCase.GetProviderHealth(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetProviderSpend retrieves provider spend
+// @Summary Get provider spend
+// @Description Get daily and monthly spend against budget caps for each AI provider (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} usecase.ProviderSpendResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/providers/spend [get]
+func (h *ProviderHandler) GetProviderSpend(c *gin.Context) {
+	response, err := h.providerUseCase.GetProviderSpend(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetExperimentReport retrieves the experiment report
+// @Summary Get experiment report
+// @Description Compare completion rate, latency and user ratings between provider experiment arms (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param experiment query string false "Limit the report to one experiment"
+// @Success 200 {object} usecase.ExperimentReportResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /admin/experiments/report [get]
+func (h *ProviderHandler) GetExperimentReport(c *gin.Context) {
+	response, err := h.providerUseCase.GetExperimentReport(c.Request.Context(), c.Query("experiment"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+	"github.com/gin-gonic/gin"
+)
+
+// ImageProxy fetches external images through a cache
+type ImageProxy interface {
+	Open(ctx context.Context, rawURL string) (io.ReadSeekCloser, service.MediaObjectInfo, error)
+}
+
+// ProxyHandler serves external images the frontend or providers cannot load directly
+type ProxyHandler struct {
+	images ImageProxy
+}
+
+// NewProxyHandler creates a new ProxyHandler
+func NewProxyHandler(images ImageProxy) *ProxyHandler {
+	return &ProxyHandler{
+		images: images,
+	}
+}
+
+// ProxyImage serves an external image from an allowlisted host
+// @Summary Proxy image
+// @Description Fetch an image from an allowlisted host and serve it from a local cache. Only public addresses are fetched, and only jpeg, png, gif and webp images are served.
+// @Tags proxy
+// @Produce image/jpeg,image/png,image/gif,image/webp
+// @Param url query string true "Image URL"
+// @Success 200 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 502 {object} ErrorResponse
+// @Router /proxy/image [get]
+func (h *ProxyHandler) ProxyImage(c *gin.Context) {
+	imageURL := c.Query("url")
+	if imageURL == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "url parameter is required",
+			Code:  "INVALID_REQUEST",
+		})
+		return
+	}
+
+	content, info, err := h.images.Open(c.Request.Context(), imageURL)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("X-Content-Type-Options", "nosniff")
+	serveMedia(c, content, info)
+}
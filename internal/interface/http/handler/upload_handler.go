@@ -1,36 +1,48 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
 	"github.com/arabella/ai-studio-backend/internal/interface/http/middleware"
+	"github.com/arabella/ai-studio-backend/internal/usecase"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
+// maxImageUploadSize is the largest image accepted before normalising
+const maxImageUploadSize = 10 * 1024 * 1024
+
+// legacyUploadDir holds images uploaded before uploads moved into media storage
+const legacyUploadDir = "./static/uploads"
+
 // UploadHandler handles file uploads
-type UploadHandler struct{}
+type UploadHandler struct {
+	uploadUseCase *usecase.UploadUseCase
+}
 
 // NewUploadHandler creates a new UploadHandler
-func NewUploadHandler() *UploadHandler {
-	return &UploadHandler{}
+func NewUploadHandler(uploadUseCase *usecase.UploadUseCase) *UploadHandler {
+	return &UploadHandler{
+		uploadUseCase: uploadUseCase,
+	}
 }
 
 // UploadImage handles image uploads
 // @Summary Upload image
-// @Description Upload an image file (for thumbnails, etc.)
+// @Description Upload an image file (for thumbnails, etc.). The type is detected from the file contents (jpeg, png or gif, max 10MB); the image is re-encoded as a JPEG of at most 2560px per side without metadata, with 1280px (detail) and 480px (listing) variants. Identical images are stored once.
 // @Tags admin
 // @Security BearerAuth
 // @Accept multipart/form-data
 // @Produce json
 // @Param file formData file true "Image file"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Router /admin/upload/image [post]
@@ -56,33 +68,8 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 		return
 	}
 
-	// Validate file type
-	allowedTypes := map[string]bool{
-		"image/jpeg":               true,
-		"image/jpg":                true,
-		"image/png":                true,
-		"image/gif":                true,
-		"image/webp":               true,
-		"application/octet-stream": true, // Some browsers send this
-	}
-
-	contentType := file.Header.Get("Content-Type")
-	if !allowedTypes[contentType] && !strings.HasSuffix(strings.ToLower(file.Filename), ".jpg") &&
-		!strings.HasSuffix(strings.ToLower(file.Filename), ".jpeg") &&
-		!strings.HasSuffix(strings.ToLower(file.Filename), ".png") &&
-		!strings.HasSuffix(strings.ToLower(file.Filename), ".gif") &&
-		!strings.HasSuffix(strings.ToLower(file.Filename), ".webp") {
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Error:   "Invalid file type. Only images are allowed (jpg, jpeg, png, gif, webp)",
-			Code:    "INVALID_FILE_TYPE",
-			Details: fmt.Sprintf("Content-Type: %s", contentType),
-		})
-		return
-	}
-
 	// Validate file size (max 10MB)
-	maxSize := int64(10 * 1024 * 1024) // 10MB
-	if file.Size > maxSize {
+	if file.Size > maxImageUploadSize {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "File too large. Maximum size is 10MB",
 			Code:    "FILE_TOO_LARGE",
@@ -91,46 +78,40 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 		return
 	}
 
-	// Create uploads directory if it doesn't exist
-	uploadDir := "./static/uploads"
-	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to create upload directory",
-			Code:    "INTERNAL_ERROR",
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Failed to read file",
+			Code:    "INVALID_REQUEST",
 			Details: err.Error(),
 		})
 		return
 	}
+	defer src.Close()
 
-	// Generate unique filename
-	ext := filepath.Ext(file.Filename)
-	if ext == "" {
-		// Default to .jpg if no extension
-		ext = ".jpg"
-	}
-	filename := fmt.Sprintf("%s%s", uuid.New().String(), ext)
-	filePath := filepath.Join(uploadDir, filename)
-
-	// Save the file
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to save file",
-			Code:    "INTERNAL_ERROR",
-			Details: err.Error(),
+	data, err := io.ReadAll(io.LimitReader(src, maxImageUploadSize+1))
+	if err != nil || len(data) > maxImageUploadSize {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Failed to read file",
+			Code:  "INVALID_REQUEST",
 		})
 		return
 	}
 
-	// Verify the file was saved and is readable
-	fileInfo, err := os.Stat(filePath)
-	if err != nil || fileInfo.Size() == 0 {
-		os.Remove(filePath) // Clean up
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error: "Failed to verify uploaded file",
-			Code:  "INTERNAL_ERROR",
+	// The client's Content-Type and file name are ignored; the contents decide what the file is
+	upload, err := h.uploadUseCase.UploadImage(c.Request.Context(), data)
+	if errors.Is(err, entity.ErrInvalidAsset) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid file type. Only images are allowed (jpg, jpeg, png, gif)",
+			Code:    "INVALID_FILE_TYPE",
+			Details: err.Error(),
 		})
 		return
 	}
+	if err != nil {
+		handleError(c, err)
+		return
+	}
 
 	// Return the URL to access the uploaded file
 	// Use the Origin header (frontend domain) or construct from request
@@ -167,14 +148,49 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 
 	// Use /api/v1/uploads so it works through Nginx proxy
 	// Nginx proxies /api/* to backend, so /api/v1/uploads will work
-	imageURL := fmt.Sprintf("%s/api/v1/uploads/%s", baseURL, filename)
+	original := upload.Variants["original"]
+	variants := make(map[string]string, len(upload.Variants))
+	for name, variant := range upload.Variants {
+		variants[name] = fmt.Sprintf("%s/api/v1/uploads/%s", baseURL, variant.Key)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"url":         imageURL,
-		"filename":    filename,
-		"size":        fileInfo.Size(),
-		"uploaded_at": time.Now().UTC().Format(time.RFC3339),
+		"url":          variants["original"],
+		"filename":     original.Key,
+		"size":         original.Size,
+		"width":        original.Width,
+		"height":       original.Height,
+		"content_type": "image/jpeg",
+		"variants":     variants,
+		"deduplicated": upload.Deduplicated,
+		"uploaded_at":  time.Now().UTC().Format(time.RFC3339),
 	})
 }
 
+// ServeUpload serves an uploaded image
+// @Summary Get uploaded image
+// @Description Serve an uploaded image or one of its variants. Uploads are content-addressed, so responses are cacheable indefinitely.
+// @Tags uploads
+// @Produce jpeg
+// @Param filepath path string true "Upload key, e.g. {hash}/listing.jpg"
+// @Success 200 {file} binary
+// @Failure 404 {object} ErrorResponse
+// @Router /uploads/{filepath} [get]
+func (h *UploadHandler) ServeUpload(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("filepath"), "/")
+
+	content, info, err := h.uploadUseCase.OpenUpload(c.Request.Context(), key)
+	if errors.Is(err, entity.ErrMediaNotFound) {
+		// Fall back to images uploaded before content-addressed storage
+		c.File(filepath.Join(legacyUploadDir, filepath.Clean("/"+key)))
+		return
+	}
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	defer content.Close()
 
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	serveMedia(c, content, info)
+}
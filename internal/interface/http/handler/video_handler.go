@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
 	"github.com/arabella/ai-studio-backend/internal/domain/entity"
@@ -12,13 +14,15 @@ import (
 
 // VideoHandler handles video generation endpoints
 type VideoHandler struct {
-	videoUseCase *usecase.VideoUseCase
+	videoUseCase  *usecase.VideoUseCase
+	mockScenarios bool // Honour the X-Mock-Scenario header; only while the mock provider is in use
 }
 
 // NewVideoHandler creates a new VideoHandler
-func NewVideoHandler(videoUseCase *usecase.VideoUseCase) *VideoHandler {
+func NewVideoHandler(videoUseCase *usecase.VideoUseCase, mockScenarios bool) *VideoHandler {
 	return &VideoHandler{
-		videoUseCase: videoUseCase,
+		videoUseCase:  videoUseCase,
+		mockScenarios: mockScenarios,
 	}
 }
 
@@ -27,6 +31,13 @@ type GenerateVideoRequest struct {
 	TemplateID string              `json:"template_id" binding:"required,uuid"`
 	Prompt     string              `json:"prompt" binding:"required,min=10,max=2000"`
 	Params     *VideoParamsRequest `json:"params,omitempty"`
+	Provider   string              `json:"provider,omitempty"` // Provider override (premium and pro tiers only)
+	// StrictParams rejects the request instead of adjusting params no provider can honour
+	StrictParams bool `json:"strict_params,omitempty"`
+	// StartImageID is an uploaded image (POST /assets/images) to animate instead of the template thumbnail
+	StartImageID string `json:"start_image_id,omitempty" binding:"omitempty,uuid"`
+	// AudioID is an uploaded soundtrack (POST /assets/audio) to synchronise the video to
+	AudioID string `json:"audio_id,omitempty" binding:"omitempty,uuid"`
 }
 
 // VideoParamsRequest represents video generation parameters
@@ -37,6 +48,8 @@ type VideoParamsRequest struct {
 	FPS            int    `json:"fps,omitempty"`
 	Style          string `json:"style,omitempty"`
 	NegativePrompt string `json:"negative_prompt,omitempty"`
+	// Advanced holds optional provider-specific features (seed, audio, multi-shot, reference images)
+	Advanced *entity.AdvancedParams `json:"advanced,omitempty"`
 }
 
 // GenerateVideo initiates video generation
@@ -47,6 +60,7 @@ type VideoParamsRequest struct {
 // @Accept json
 // @Produce json
 // @Param request body GenerateVideoRequest true "Video Generation Request"
+// @Param X-Mock-Scenario header string false "Mock provider scenario, honoured only when the mock provider is enabled: success, fail_submit, fail_progress, stall, rate_limit, progress_errors, malformed_result"
 // @Success 201 {object} usecase.VideoGenerationResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
@@ -84,8 +98,15 @@ func (h *VideoHandler) GenerateVideo(c *gin.Context) {
 
 	// Build use case request
 	useCaseReq := usecase.VideoGenerationRequest{
-		TemplateID: templateID,
-		Prompt:     req.Prompt,
+		TemplateID:   templateID,
+		Prompt:       req.Prompt,
+		StrictParams: req.StrictParams,
+		MockScenario: h.mockScenario(c),
+	}
+
+	if req.Provider != "" {
+		provider := entity.AIProvider(req.Provider)
+		useCaseReq.Provider = &provider
 	}
 
 	if req.Params != nil {
@@ -94,6 +115,30 @@ func (h *VideoHandler) GenerateVideo(c *gin.Context) {
 		useCaseReq.Params = nil
 	}
 
+	if req.StartImageID != "" {
+		startImageID, err := uuid.Parse(req.StartImageID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Invalid start image ID",
+				Code:  "INVALID_ID",
+			})
+			return
+		}
+		useCaseReq.StartImageID = &startImageID
+	}
+
+	if req.AudioID != "" {
+		audioID, err := uuid.Parse(req.AudioID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error: "Invalid audio ID",
+				Code:  "INVALID_ID",
+			})
+			return
+		}
+		useCaseReq.AudioID = &audioID
+	}
+
 	response, err := h.videoUseCase.GenerateVideo(c.Request.Context(), userID, useCaseReq)
 	if err != nil {
 		handleError(c, err)
@@ -103,6 +148,84 @@ func (h *VideoHandler) GenerateVideo(c *gin.Context) {
 	c.JSON(http.StatusCreated, response)
 }
 
+// RegenerateVideoRequest represents optional overrides for a regeneration
+type RegenerateVideoRequest struct {
+	Prompt       string              `json:"prompt,omitempty" binding:"omitempty,min=10,max=2000"`
+	Params       *VideoParamsRequest `json:"params,omitempty"`
+	Provider     string              `json:"provider,omitempty"` // Provider override (premium and pro tiers only)
+	StrictParams bool                `json:"strict_params,omitempty"`
+	// NewSeed picks a fresh seed instead of reusing the original job's
+	NewSeed bool `json:"new_seed,omitempty"`
+}
+
+// RegenerateVideo starts a new job with an earlier job's settings
+// @Summary Regenerate video
+// @Description Start a new generation with the same template, prompt, params and seed as an earlier job, with optional overrides. Charged as a normal generation.
+// @Tags videos
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID" format(uuid)
+// @Param request body RegenerateVideoRequest false "Overrides"
+// @Param X-Mock-Scenario header string false "Mock provider scenario, honoured only when the mock provider is enabled"
+// @Success 201 {object} usecase.VideoGenerationResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Router /videos/{id}/regenerate [post]
+func (h *VideoHandler) RegenerateVideo(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "Authentication required",
+			Code:  "UNAUTHORIZED",
+		})
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid job ID",
+			Code:  "INVALID_ID",
+		})
+		return
+	}
+
+	// The body is optional; an empty one regenerates with identical settings
+	var req RegenerateVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	useCaseReq := usecase.RegenerateVideoRequest{
+		Prompt:       req.Prompt,
+		Params:       convertVideoParams(req.Params),
+		StrictParams: req.StrictParams,
+		NewSeed:      req.NewSeed,
+		MockScenario: h.mockScenario(c),
+	}
+	if req.Provider != "" {
+		provider := entity.AIProvider(req.Provider)
+		useCaseReq.Provider = &provider
+	}
+
+	response, err := h.videoUseCase.RegenerateVideo(c.Request.Context(), userID, jobID, useCaseReq)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
 // GetJobStatus retrieves the status of a video job
 // @Summary Get job status
 // @Description Get the current status of a video generation job
@@ -307,6 +430,226 @@ func (h *VideoHandler) GetRecentVideos(c *gin.Context) {
 	})
 }
 
+// RateVideoRequest represents a video rating request
+type RateVideoRequest struct {
+	Rating int `json:"rating" binding:"required,min=1,max=5" example:"4"`
+}
+
+// RateVideo records the user's rating of a completed video
+// @Summary Rate video
+// @Description Rate a completed video from 1 to 5
+// @Tags videos
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID" format(uuid)
+// @Param request body RateVideoRequest true "Rating"
+// @Success 200 {object} entity.VideoJob
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /videos/{id}/rating [post]
+func (h *VideoHandler) RateVideo(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "Authentication required",
+			Code:  "UNAUTHORIZED",
+		})
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid job ID",
+			Code:  "INVALID_ID",
+		})
+		return
+	}
+
+	var req RateVideoRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request body",
+			Code:    "INVALID_REQUEST",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	job, err := h.videoUseCase.RateVideo(c.Request.Context(), userID, jobID, req.Rating)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// RemoveWatermark queues the switch of a watermarked video to its clean original
+// @Summary Remove watermark
+// @Description Queue the replacement of the watermarked copy of a video generated on the free plan with the clean original. Requires a premium or pro plan; the video is not generated again, though its HLS renditions are transcoded again from the original. The job's watermark_removal field tracks the removal, and a watermark_removal WebSocket event reports how it ended.
+// @Tags videos
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Job ID" format(uuid)
+// @Success 202 {object} entity.VideoJob
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /videos/{id}/unwatermark [post]
+func (h *VideoHandler) RemoveWatermark(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "Authentication required",
+			Code:  "UNAUTHORIZED",
+		})
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid job ID",
+			Code:  "INVALID_ID",
+		})
+		return
+	}
+
+	job, err := h.videoUseCase.RemoveWatermark(c.Request.Context(), userID, jobID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// ShareVideo creates a share link for a completed video
+// @Summary Share video
+// @Description Get a link that streams the video without signing in, creating it if the video has none
+// @Tags videos
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Job ID" format(uuid)
+// @Success 200 {object} usecase.ShareLinkResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /videos/{id}/share [post]
+func (h *VideoHandler) ShareVideo(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "Authentication required",
+			Code:  "UNAUTHORIZED",
+		})
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid job ID",
+			Code:  "INVALID_ID",
+		})
+		return
+	}
+
+	link, err := h.videoUseCase.ShareVideo(c.Request.Context(), userID, jobID)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// UnshareVideo revokes a video's share link
+// @Summary Revoke share link
+// @Description Revoke the video's share link so it no longer streams without signing in
+// @Tags videos
+// @Security BearerAuth
+// @Param id path string true "Job ID" format(uuid)
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /videos/{id}/share [delete]
+func (h *VideoHandler) UnshareVideo(c *gin.Context) {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error: "Authentication required",
+			Code:  "UNAUTHORIZED",
+		})
+		return
+	}
+
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid job ID",
+			Code:  "INVALID_ID",
+		})
+		return
+	}
+
+	if err := h.videoUseCase.UnshareVideo(c.Request.Context(), userID, jobID); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// StreamVideo streams a completed video's stored file
+// @Summary Stream video
+// @Description Stream the video with support for Range and If-Range requests. Owners authenticate with a bearer token or the token query parameter (for video elements); anyone else needs the share query parameter from the video's share link.
+// @Tags videos
+// @Security BearerAuth
+// @Produce mp4
+// @Param id path string true "Job ID" format(uuid)
+// @Param share query string false "Share token from the video's share link"
+// @Param Range header string false "Byte range, e.g. bytes=0-1048575"
+// @Success 200 {file} binary
+// @Success 206 {file} binary
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /videos/{id}/stream [get]
+func (h *VideoHandler) StreamVideo(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error: "Invalid job ID",
+			Code:  "INVALID_ID",
+		})
+		return
+	}
+
+	var viewer *uuid.UUID
+	if userID, ok := middleware.GetUserID(c); ok {
+		viewer = &userID
+	}
+
+	content, info, err := h.videoUseCase.OpenVideoStream(c.Request.Context(), viewer, jobID, c.Query("share"))
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+	defer content.Close()
+
+	// The stored file never changes, but access can be revoked, so shared caches must not keep it
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.Header("Vary", "Authorization")
+	serveMedia(c, content, info)
+}
+
 // convertVideoParams converts request params to entity params
 func convertVideoParams(req *VideoParamsRequest) *entity.VideoParams {
 	if req == nil {
@@ -319,6 +662,14 @@ func convertVideoParams(req *VideoParamsRequest) *entity.VideoParams {
 		FPS:            req.FPS,
 		Style:          req.Style,
 		NegativePrompt: req.NegativePrompt,
+		Advanced:       req.Advanced,
 	}
 }
 
+// mockScenario returns the X-Mock-Scenario test header, or nothing unless the mock provider is in use
+func (h *VideoHandler) mockScenario(c *gin.Context) string {
+	if !h.mockScenarios {
+		return ""
+	}
+	return c.GetHeader("X-Mock-Scenario")
+}
@@ -114,6 +114,32 @@ func (m *AuthMiddleware) RequirePremium() gin.HandlerFunc {
 	}
 }
 
+// RequireAdmin middleware requires the authenticated user to have been made an admin in the database
+func (m *AuthMiddleware) RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, exists := GetUser(c)
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Authentication required",
+				"code":  "UNAUTHORIZED",
+			})
+			c.Abort()
+			return
+		}
+
+		if !user.IsAdmin {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Admin access required",
+				"code":  "FORBIDDEN",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // extractToken extracts the JWT token from the request
 func extractToken(c *gin.Context) string {
 	// Check Authorization header
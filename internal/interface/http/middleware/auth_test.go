@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		user *entity.User
+		want int
+	}{
+		{"admin", &entity.User{Email: "ops@arabella.uz", IsAdmin: true}, http.StatusOK},
+		{"not an admin", &entity.User{Email: "user@arabella.uz"}, http.StatusForbidden},
+		{"email alone grants nothing", &entity.User{Email: "admin@arabella.uz"}, http.StatusForbidden},
+		{"unauthenticated", nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if tt.user != nil {
+					c.Set(UserKey, tt.user)
+				}
+			})
+			router.Use((&AuthMiddleware{}).RequireAdmin())
+			router.POST("/admin/media/gc", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/media/gc", nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+// AssetStore stores private asset files by key
+type AssetStore interface {
+	Save(ctx context.Context, key string, data []byte) error
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// AssetURLSigner issues and verifies signed asset URLs
+type AssetURLSigner interface {
+	SignAssetURL(assetID uuid.UUID) string
+	VerifyAssetURL(assetID uuid.UUID, expires, signature string) error
+}
+
+// ImageProcessor validates an uploaded start image and re-encodes it as a JPEG
+type ImageProcessor interface {
+	PrepareStartImage(data []byte) (jpeg []byte, width, height int, err error)
+}
+
+// AudioInspector validates an uploaded soundtrack and measures its duration
+type AudioInspector interface {
+	InspectAudio(data []byte) (contentType, ext string, duration float64, err error)
+}
+
+// AssetUseCase handles user asset uploads and access
+type AssetUseCase struct {
+	assetRepo repository.UserAssetRepository
+	store     AssetStore
+	signer    AssetURLSigner
+	images    ImageProcessor
+	audio     AudioInspector
+}
+
+// NewAssetUseCase creates a new AssetUseCase
+func NewAssetUseCase(assetRepo repository.UserAssetRepository, store AssetStore, signer AssetURLSigner, images ImageProcessor, audio AudioInspector) *AssetUseCase {
+	return &AssetUseCase{
+		assetRepo: assetRepo,
+		store:     store,
+		signer:    signer,
+		images:    images,
+		audio:     audio,
+	}
+}
+
+// UploadStartImage validates, resizes and privately stores an image the user can animate
+func (uc *AssetUseCase) UploadStartImage(ctx context.Context, userID uuid.UUID, data []byte) (*entity.UserAsset, error) {
+	jpeg, width, height, err := uc.images.PrepareStartImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := entity.NewUserAsset(userID, entity.AssetKindImage, "image/jpeg")
+	asset.StorageKey = fmt.Sprintf("%s/%s.jpg", userID, asset.ID)
+	asset.SizeBytes = int64(len(jpeg))
+	asset.Width = width
+	asset.Height = height
+
+	if err := uc.store.Save(ctx, asset.StorageKey, jpeg); err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrStorageUploadFailed, err)
+	}
+
+	if err := uc.assetRepo.Create(ctx, asset); err != nil {
+		_ = uc.store.Delete(ctx, asset.StorageKey)
+		return nil, err
+	}
+
+	asset.URL = uc.signer.SignAssetURL(asset.ID)
+	return asset, nil
+}
+
+// UploadAudio validates and privately stores a soundtrack the user can synchronise a video to
+func (uc *AssetUseCase) UploadAudio(ctx context.Context, userID uuid.UUID, data []byte) (*entity.UserAsset, error) {
+	contentType, ext, duration, err := uc.audio.InspectAudio(data)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := entity.NewUserAsset(userID, entity.AssetKindAudio, contentType)
+	asset.StorageKey = fmt.Sprintf("%s/%s%s", userID, asset.ID, ext)
+	asset.SizeBytes = int64(len(data))
+	asset.Duration = duration
+
+	if err := uc.store.Save(ctx, asset.StorageKey, data); err != nil {
+		return nil, fmt.Errorf("%w: %v", entity.ErrStorageUploadFailed, err)
+	}
+
+	if err := uc.assetRepo.Create(ctx, asset); err != nil {
+		_ = uc.store.Delete(ctx, asset.StorageKey)
+		return nil, err
+	}
+
+	asset.URL = uc.signer.SignAssetURL(asset.ID)
+	return asset, nil
+}
+
+// GetAsset retrieves one of the user's assets with a fresh signed URL
+func (uc *AssetUseCase) GetAsset(ctx context.Context, userID, assetID uuid.UUID) (*entity.UserAsset, error) {
+	asset, err := uc.assetRepo.GetByID(ctx, assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Other users' assets are reported as missing so their IDs cannot be probed
+	if !asset.IsOwnedBy(userID) {
+		return nil, entity.ErrAssetNotFound
+	}
+
+	asset.URL = uc.signer.SignAssetURL(asset.ID)
+	return asset, nil
+}
+
+// OpenAssetContent opens an asset's file after checking its signed URL parameters
+func (uc *AssetUseCase) OpenAssetContent(ctx context.Context, assetID uuid.UUID, expires, signature string) (*entity.UserAsset, io.ReadSeekCloser, error) {
+	if err := uc.signer.VerifyAssetURL(assetID, expires, signature); err != nil {
+		return nil, nil, err
+	}
+
+	asset, err := uc.assetRepo.GetByID(ctx, assetID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := uc.store.Open(ctx, asset.StorageKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", entity.ErrStorageDownloadFailed, err)
+	}
+
+	return asset, content, nil
+}
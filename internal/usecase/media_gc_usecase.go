@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/repository"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+// defaultMediaGCGracePeriod protects recently written objects, such as media stored for a job that
+// is still being saved or an upload whose template has not been created yet
+const defaultMediaGCGracePeriod = 7 * 24 * time.Hour
+
+// generatedMediaPrefixes are the media storage prefixes written for video jobs. Together with the
+// uploads prefix they are all the collector sweeps, so other objects in a shared bucket are left alone.
+var generatedMediaPrefixes = []string{"videos/", "sources/", "thumbnails/", "previews/", "hls/"}
+
+// mediaKeyReferencePattern finds media storage keys inside URLs, such as a template preview that
+// points at a generated video
+var mediaKeyReferencePattern = regexp.MustCompile(`(?:videos|sources|thumbnails|previews|hls)/[^?#"'\s\\]+`)
+
+// uploadReferencePattern captures the path segment after "uploads/" in a URL: the hash of a
+// content-addressed upload or the file name of a legacy one
+var uploadReferencePattern = regexp.MustCompile(`uploads/([^/?#"'\s\\]+)`)
+
+// MediaSweepStore is a storage backend the media garbage collector can list and delete from
+type MediaSweepStore interface {
+	Walk(ctx context.Context, prefix string, fn func(key string, info service.MediaObjectInfo) error) error
+	Delete(ctx context.Context, key string) error
+}
+
+// MediaGCStores are the storage backends swept for orphaned files; a nil store is skipped
+type MediaGCStores struct {
+	Media         service.MediaStorage // Generated videos, their derived media and content-addressed uploads
+	Assets        MediaSweepStore      // Private user assets such as start images and soundtracks
+	LegacyUploads MediaSweepStore      // Images uploaded before content-addressed storage
+	ImageCache    MediaSweepStore      // Images cached by the image proxy
+}
+
+// MediaGCStoreReport describes the sweep of one storage backend
+type MediaGCStoreReport struct {
+	Store          string `json:"store" example:"media"`
+	Scanned        int    `json:"scanned" example:"1840"`
+	Orphaned       int    `json:"orphaned" example:"12"` // Unreferenced and older than the grace period
+	Deleted        int    `json:"deleted" example:"12"`
+	Failed         int    `json:"failed" example:"0"`
+	BytesReclaimed int64  `json:"bytes_reclaimed" example:"73400320"` // Bytes freed, or that would be freed in a dry run
+}
+
+// MediaGCReport summarises a garbage collection run
+type MediaGCReport struct {
+	DryRun         bool                 `json:"dry_run"`
+	GracePeriod    string               `json:"grace_period" example:"168h0m0s"`
+	StartedAt      time.Time            `json:"started_at"`
+	FinishedAt     time.Time            `json:"finished_at"`
+	Orphaned       int                  `json:"orphaned" example:"12"`
+	Deleted        int                  `json:"deleted" example:"12"`
+	BytesReclaimed int64                `json:"bytes_reclaimed" example:"73400320"`
+	Stores         []MediaGCStoreReport `json:"stores"`
+}
+
+// MediaGCUseCase deletes stored files that no template, video job or user asset references any more
+type MediaGCUseCase struct {
+	jobRepo      repository.VideoJobRepository
+	templateRepo repository.TemplateRepository
+	assetRepo    repository.UserAssetRepository
+	stores       MediaGCStores
+	gracePeriod  time.Duration
+	running      sync.Mutex
+}
+
+// NewMediaGCUseCase creates a new MediaGCUseCase
+func NewMediaGCUseCase(
+	jobRepo repository.VideoJobRepository,
+	templateRepo repository.TemplateRepository,
+	assetRepo repository.UserAssetRepository,
+	stores MediaGCStores,
+	gracePeriod time.Duration,
+) *MediaGCUseCase {
+	if gracePeriod <= 0 {
+		gracePeriod = defaultMediaGCGracePeriod
+	}
+	return &MediaGCUseCase{
+		jobRepo:      jobRepo,
+		templateRepo: templateRepo,
+		assetRepo:    assetRepo,
+		stores:       stores,
+		gracePeriod:  gracePeriod,
+	}
+}
+
+// CollectGarbage sweeps every storage backend and deletes unreferenced files older than the grace
+// period. A dry run only reports what would be deleted.
+func (uc *MediaGCUseCase) CollectGarbage(ctx context.Context, dryRun bool) (*MediaGCReport, error) {
+	if !uc.running.TryLock() {
+		return nil, entity.ErrMediaGCInProgress
+	}
+	defer uc.running.Unlock()
+
+	report := &MediaGCReport{
+		DryRun:      dryRun,
+		GracePeriod: uc.gracePeriod.String(),
+		StartedAt:   time.Now(),
+	}
+
+	// References are loaded before listing, so anything written after this point is within the grace period
+	refs, err := uc.loadReferences(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load media references: %w", err)
+	}
+	cutoff := report.StartedAt.Add(-uc.gracePeriod)
+
+	sweeps := []struct {
+		name       string
+		store      MediaSweepStore
+		prefixes   []string
+		referenced func(key string) bool
+	}{
+		{"media", uc.stores.Media, append(generatedMediaPrefixes, uploadPrefix), refs.media},
+		{"assets", uc.stores.Assets, []string{""}, refs.asset},
+		{"legacy_uploads", uc.stores.LegacyUploads, []string{""}, refs.legacyUpload},
+		// The proxy cache is only a cache: nothing stored references it, so age alone decides
+		{"image_cache", uc.stores.ImageCache, []string{""}, func(string) bool { return false }},
+	}
+	for _, sweep := range sweeps {
+		if sweep.store == nil {
+			continue
+		}
+		storeReport, err := uc.sweep(ctx, sweep.store, sweep.prefixes, sweep.referenced, cutoff, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sweep %s: %w", sweep.name, err)
+		}
+		storeReport.Store = sweep.name
+
+		report.Stores = append(report.Stores, *storeReport)
+		report.Orphaned += storeReport.Orphaned
+		report.Deleted += storeReport.Deleted
+		report.BytesReclaimed += storeReport.BytesReclaimed
+	}
+	report.FinishedAt = time.Now()
+
+	return report, nil
+}
+
+// sweep lists a store under each prefix and deletes the files that are unreferenced and older than cutoff
+func (uc *MediaGCUseCase) sweep(
+	ctx context.Context,
+	store MediaSweepStore,
+	prefixes []string,
+	referenced func(key string) bool,
+	cutoff time.Time,
+	dryRun bool,
+) (*MediaGCStoreReport, error) {
+	report := &MediaGCStoreReport{}
+
+	// Orphans are collected before anything is deleted so the listing is never modified mid-walk
+	orphans := make(map[string]int64)
+	for _, prefix := range prefixes {
+		err := store.Walk(ctx, prefix, func(key string, info service.MediaObjectInfo) error {
+			report.Scanned++
+			// Objects without a modification time cannot be proven old enough to delete
+			if info.ModTime.IsZero() || info.ModTime.After(cutoff) || referenced(key) {
+				return nil
+			}
+			orphans[key] = info.Size
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for key, size := range orphans {
+		report.Orphaned++
+		if dryRun {
+			report.BytesReclaimed += size
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := store.Delete(ctx, key); err != nil {
+			report.Failed++
+			continue
+		}
+		report.Deleted++
+		report.BytesReclaimed += size
+	}
+
+	return report, nil
+}
+
+// mediaReferences is everything stored files may be referenced by
+type mediaReferences struct {
+	keys    map[string]bool // Media storage keys
+	dirs    map[string]bool // Media storage directories kept whole, such as HLS renditions beside their playlist
+	uploads map[string]bool // Upload hashes and legacy upload file names
+	assets  map[string]bool // Asset storage keys
+}
+
+// loadReferences collects the storage keys of every video job and user asset, and the media
+// templates point at
+func (uc *MediaGCUseCase) loadReferences(ctx context.Context) (*mediaReferences, error) {
+	refs := &mediaReferences{
+		keys:    make(map[string]bool),
+		dirs:    make(map[string]bool),
+		uploads: make(map[string]bool),
+		assets:  make(map[string]bool),
+	}
+
+	jobKeys, err := uc.jobRepo.ListStorageKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range jobKeys {
+		refs.addKey(key)
+	}
+
+	assetKeys, err := uc.assetRepo.ListStorageKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range assetKeys {
+		refs.assets[key] = true
+	}
+
+	templateRefs, err := uc.templateRepo.ListMediaReferences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range templateRefs {
+		for _, key := range mediaKeyReferencePattern.FindAllString(ref, -1) {
+			refs.addKey(unescapeReference(key))
+		}
+		for _, match := range uploadReferencePattern.FindAllStringSubmatch(ref, -1) {
+			refs.uploads[match[1]] = true
+			refs.uploads[unescapeReference(match[1])] = true
+		}
+	}
+
+	return refs, nil
+}
+
+// addKey references a media storage key. A playlist references the whole directory it is in,
+// since its renditions and segments are only linked from the playlist files themselves.
+func (r *mediaReferences) addKey(key string) {
+	r.keys[key] = true
+	if strings.HasSuffix(key, ".m3u8") {
+		r.dirs[path.Dir(key)] = true
+	}
+}
+
+// media reports whether a media storage key is referenced
+func (r *mediaReferences) media(key string) bool {
+	if r.keys[key] {
+		return true
+	}
+	if rest, ok := strings.CutPrefix(key, uploadPrefix); ok {
+		hash, _, _ := strings.Cut(rest, "/")
+		return r.uploads[hash]
+	}
+	for dir := path.Dir(key); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if r.dirs[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// asset reports whether an asset storage key is referenced
+func (r *mediaReferences) asset(key string) bool {
+	return r.assets[key]
+}
+
+// legacyUpload reports whether a file in the legacy uploads directory is referenced
+func (r *mediaReferences) legacyUpload(key string) bool {
+	return r.uploads[key]
+}
+
+// unescapeReference decodes a percent-encoded path taken from a URL, keeping it as is if it is malformed
+func unescapeReference(ref string) string {
+	if unescaped, err := url.PathUnescape(ref); err == nil {
+		return unescaped
+	}
+	return ref
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/repository"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+type gcJobRepo struct {
+	repository.VideoJobRepository
+	keys []string
+}
+
+func (r *gcJobRepo) ListStorageKeys(ctx context.Context) ([]string, error) {
+	return r.keys, nil
+}
+
+type gcTemplateRepo struct {
+	repository.TemplateRepository
+	refs []string
+}
+
+func (r *gcTemplateRepo) ListMediaReferences(ctx context.Context) ([]string, error) {
+	return r.refs, nil
+}
+
+type gcAssetRepo struct {
+	repository.UserAssetRepository
+	keys []string
+}
+
+func (r *gcAssetRepo) ListStorageKeys(ctx context.Context) ([]string, error) {
+	return r.keys, nil
+}
+
+// gcStore keeps objects and their modification times in memory
+type gcStore struct {
+	service.MediaStorage
+	objects map[string]time.Time
+}
+
+func (s *gcStore) Walk(ctx context.Context, prefix string, fn func(key string, info service.MediaObjectInfo) error) error {
+	for key, modTime := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			if err := fn(key, service.MediaObjectInfo{Size: 10, ModTime: modTime}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *gcStore) Delete(ctx context.Context, key string) error {
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *gcStore) list() []string {
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newGCStore returns a store holding keys written long before any grace period
+func newGCStore(keys ...string) *gcStore {
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	store := &gcStore{objects: make(map[string]time.Time, len(keys))}
+	for _, key := range keys {
+		store.objects[key] = old
+	}
+	return store
+}
+
+func TestCollectGarbageReferenceMatching(t *testing.T) {
+	media := newGCStore(
+		// A playlist keeps its whole directory, but not directories that merely share its name
+		"hls/u/j1/master.m3u8",
+		"hls/u/j1/720p.m3u8",
+		"hls/u/j1/720p/segment_000.ts",
+		"hls/u/j1-1700000000/master.m3u8",
+		"hls/u/j1-1700000000/720p/segment_000.ts",
+		"hls/u/j2/master.m3u8",
+		"videos/u/j1.mp4",
+		"videos/u/j2.mp4",
+		// A template referencing one variant of a content-addressed upload keeps them all
+		"uploads/abc123/large.jpg",
+		"uploads/abc123/thumb.jpg",
+		"uploads/def456/large.jpg",
+		// Templates may point at generated media, with escaped paths
+		"previews/u/t 1.gif",
+		"previews/u/t2.gif",
+	)
+	media.objects["videos/u/new.mp4"] = time.Now()
+	media.objects["videos/u/unknown-age.mp4"] = time.Time{}
+	assets := newGCStore("u/a.png", "u/b.png")
+	legacy := newGCStore("legacy.jpg", "stale.jpg")
+
+	uc := NewMediaGCUseCase(
+		&gcJobRepo{keys: []string{"hls/u/j1/master.m3u8", "videos/u/j1.mp4"}},
+		&gcTemplateRepo{refs: []string{
+			"https://api.arabella.uz/api/v1/uploads/abc123/large.jpg",
+			"https://cdn.arabella.app/previews/u/t%201.gif?token=x",
+			`{"reference_image":"/uploads/legacy.jpg"}`,
+		}},
+		&gcAssetRepo{keys: []string{"u/a.png"}},
+		MediaGCStores{Media: media, Assets: assets, LegacyUploads: legacy},
+		7*24*time.Hour,
+	)
+
+	// A dry run only reports
+	report, err := uc.CollectGarbage(context.Background(), true)
+	if err != nil {
+		t.Fatalf("CollectGarbage: %v", err)
+	}
+	if report.Orphaned != 8 || report.Deleted != 0 || len(media.objects) != 15 {
+		t.Errorf("dry run orphaned %d and deleted %d, leaving %v", report.Orphaned, report.Deleted, media.list())
+	}
+
+	if _, err := uc.CollectGarbage(context.Background(), false); err != nil {
+		t.Fatalf("CollectGarbage: %v", err)
+	}
+
+	wantMedia := []string{
+		"hls/u/j1/720p.m3u8",
+		"hls/u/j1/720p/segment_000.ts",
+		"hls/u/j1/master.m3u8",
+		"previews/u/t 1.gif",
+		"uploads/abc123/large.jpg",
+		"uploads/abc123/thumb.jpg",
+		"videos/u/j1.mp4",
+		"videos/u/new.mp4",
+		"videos/u/unknown-age.mp4",
+	}
+	if got := media.list(); strings.Join(got, ",") != strings.Join(wantMedia, ",") {
+		t.Errorf("media kept = %v, want %v", got, wantMedia)
+	}
+	if got := assets.list(); strings.Join(got, ",") != "u/a.png" {
+		t.Errorf("assets kept = %v, want only the referenced one", got)
+	}
+	if got := legacy.list(); strings.Join(got, ",") != "legacy.jpg" {
+		t.Errorf("legacy uploads kept = %v, want only the referenced one", got)
+	}
+}
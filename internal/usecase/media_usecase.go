@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+// MediaURLSigner issues and verifies signed, expiring URLs for stored media
+type MediaURLSigner interface {
+	SignMediaURL(key string) string
+	SignMediaDirURL(key string) string
+	VerifyMediaURL(token, key string) error
+}
+
+// HLSPublisher transcodes a stored video into an HLS ladder stored under a key prefix
+type HLSPublisher interface {
+	PublishStored(ctx context.Context, videoKey, prefix string) (playlistKey string, err error)
+}
+
+// mediaContentTypes maps stored media extensions to the content type they are served with
+var mediaContentTypes = map[string]string{
+	".mp4":  "video/mp4",
+	".jpg":  "image/jpeg",
+	".gif":  "image/gif",
+	".m3u8": "application/vnd.apple.mpegurl",
+	".ts":   "video/mp2t",
+}
+
+// MediaUseCase serves stored media through signed URLs
+type MediaUseCase struct {
+	storage service.MediaStorage
+	signer  MediaURLSigner
+}
+
+// NewMediaUseCase creates a new MediaUseCase
+func NewMediaUseCase(storage service.MediaStorage, signer MediaURLSigner) *MediaUseCase {
+	return &MediaUseCase{
+		storage: storage,
+		signer:  signer,
+	}
+}
+
+// OpenMedia opens the object under key after checking the token from its signed URL.
+// The returned info carries the content type to serve it with.
+func (uc *MediaUseCase) OpenMedia(ctx context.Context, token, key string) (io.ReadSeekCloser, service.MediaObjectInfo, error) {
+	key = strings.TrimPrefix(key, "/")
+	if err := uc.signer.VerifyMediaURL(token, key); err != nil {
+		return nil, service.MediaObjectInfo{}, err
+	}
+	return openMedia(ctx, uc.storage, key)
+}
+
+// openMedia opens a stored object, mapping a missing object to ErrMediaNotFound and
+// setting the content type from the key's extension
+func openMedia(ctx context.Context, storage service.MediaStorage, key string) (io.ReadSeekCloser, service.MediaObjectInfo, error) {
+	content, info, err := storage.Open(ctx, key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, service.MediaObjectInfo{}, entity.ErrMediaNotFound
+	}
+	if err != nil {
+		return nil, service.MediaObjectInfo{}, fmt.Errorf("%w: %v", entity.ErrStorageDownloadFailed, err)
+	}
+
+	if contentType, ok := mediaContentTypes[path.Ext(key)]; ok {
+		info.ContentType = contentType
+	} else if info.ContentType == "" {
+		info.ContentType = "application/octet-stream"
+	}
+	return content, info, nil
+}
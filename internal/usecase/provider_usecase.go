@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/repository"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+// ProviderHealthResponse represents the provider health report
+type ProviderHealthResponse struct {
+	Providers []service.ProviderHealthReport `json:"providers"`
+}
+
+// ProviderSpendResponse represents the provider spend report
+type ProviderSpendResponse struct {
+	Providers []service.ProviderSpendReport `json:"providers"`
+}
+
+// ExperimentReportResponse represents the per-arm experiment report
+type ExperimentReportResponse struct {
+	Arms []*repository.ExperimentArmStats `json:"arms"`
+}
+
+// ProviderUseCase handles AI provider administration
+type ProviderUseCase struct {
+	providerSelector service.ProviderSelector
+	jobRepo          repository.VideoJobRepository
+}
+
+// NewProviderUseCase creates a new ProviderUseCase
+func NewProviderUseCase(providerSelector service.ProviderSelector, jobRepo repository.VideoJobRepository) *ProviderUseCase {
+	return &ProviderUseCase{
+		providerSelector: providerSelector,
+		jobRepo:          jobRepo,
+	}
+}
+
+// GetProviderHealth retrieves current and historical health for all providers
+func (uc *ProviderUseCase) GetProviderHealth(ctx context.Context) (*ProviderHealthResponse, error) {
+	reports, err := uc.providerSelector.GetHealthReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProviderHealthResponse{
+		Providers: reports,
+	}, nil
+}
+
+// GetProviderSpend retrieves current spend and budget caps for all providers
+func (uc *ProviderUseCase) GetProviderSpend(ctx context.Context) (*ProviderSpendResponse, error) {
+	reports, err := uc.providerSelector.GetSpendReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProviderSpendResponse{
+		Providers: reports,
+	}, nil
+}
+
+// GetExperimentReport compares completion rate, latency and ratings between experiment arms
+func (uc *ProviderUseCase) GetExperimentReport(ctx context.Context, experiment string) (*ExperimentReportResponse, error) {
+	var filter *string
+	if experiment != "" {
+		filter = &experiment
+	}
+
+	arms, err := uc.jobRepo.GetExperimentStats(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if arms == nil {
+		arms = []*repository.ExperimentArmStats{}
+	}
+
+	return &ExperimentReportResponse{
+		Arms: arms,
+	}, nil
+}
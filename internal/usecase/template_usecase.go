@@ -5,6 +5,7 @@ import (
 
 	"github.com/arabella/ai-studio-backend/internal/domain/entity"
 	"github.com/arabella/ai-studio-backend/internal/domain/repository"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
 	"github.com/google/uuid"
 )
 
@@ -31,8 +32,9 @@ type TemplateListResponse struct {
 
 // TemplateUseCase handles template-related business logic
 type TemplateUseCase struct {
-	templateRepo repository.TemplateRepository
-	cache        CacheService
+	templateRepo     repository.TemplateRepository
+	cache            CacheService
+	providerSelector service.ProviderSelector
 }
 
 // CacheService interface for caching
@@ -47,10 +49,12 @@ type CacheService interface {
 func NewTemplateUseCase(
 	templateRepo repository.TemplateRepository,
 	cache CacheService,
+	providerSelector service.ProviderSelector,
 ) *TemplateUseCase {
 	return &TemplateUseCase{
-		templateRepo: templateRepo,
-		cache:        cache,
+		templateRepo:     templateRepo,
+		cache:            cache,
+		providerSelector: providerSelector,
 	}
 }
 
@@ -212,6 +216,9 @@ func (uc *TemplateUseCase) CreateTemplate(ctx context.Context, template *entity.
 	if template.BasePrompt == "" {
 		return entity.ErrInvalidInput
 	}
+	if err := uc.validatePreferredProvider(template); err != nil {
+		return err
+	}
 	return uc.templateRepo.Create(ctx, template)
 }
 
@@ -222,9 +229,23 @@ func (uc *TemplateUseCase) UpdateTemplate(ctx context.Context, template *entity.
 	if err != nil {
 		return err
 	}
+	if err := uc.validatePreferredProvider(template); err != nil {
+		return err
+	}
 	return uc.templateRepo.Update(ctx, template)
 }
 
+// validatePreferredProvider ensures a template's preferred provider is registered
+func (uc *TemplateUseCase) validatePreferredProvider(template *entity.Template) error {
+	if template.PreferredProvider == nil || *template.PreferredProvider == "" {
+		return nil
+	}
+	if !uc.providerSelector.IsRegistered(entity.AIProvider(*template.PreferredProvider)) {
+		return entity.ErrUnknownProvider
+	}
+	return nil
+}
+
 // DeleteTemplate deletes a template (soft delete)
 func (uc *TemplateUseCase) DeleteTemplate(ctx context.Context, id uuid.UUID) error {
 	return uc.templateRepo.Delete(ctx, id)
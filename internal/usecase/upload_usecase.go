@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+)
+
+// uploadPrefix is the media storage prefix under which uploaded images are stored
+const uploadPrefix = "uploads/"
+
+// uploadVariants lists the sizes stored for every uploaded image, largest first. The first is the
+// normalised original; the others are derived from it for the listing and detail views.
+var uploadVariants = []struct {
+	Name    string
+	MaxSide int
+}{
+	{Name: "original", MaxSide: 2560},
+	{Name: "detail", MaxSide: 1280},
+	{Name: "listing", MaxSide: 480},
+}
+
+// uploadKeyPattern matches the keys of stored upload variants: {sha256}/{variant}.jpg
+var uploadKeyPattern = regexp.MustCompile(`^[0-9a-f]{64}/(original|detail|listing)\.jpg$`)
+
+// UploadImageProcessor validates an uploaded image by its content and re-encodes it as a clean JPEG
+type UploadImageProcessor interface {
+	PrepareUploadImage(data []byte, maxSide int) (jpeg []byte, width, height int, err error)
+}
+
+// UploadedImage is one stored size of an uploaded image
+type UploadedImage struct {
+	Key    string // Relative to the uploads prefix
+	Width  int
+	Height int
+	Size   int64
+}
+
+// UploadResult describes a stored upload and its variants
+type UploadResult struct {
+	Hash         string
+	Variants     map[string]UploadedImage
+	Deduplicated bool // The same image had already been uploaded
+}
+
+// UploadUseCase stores site images such as template thumbnails
+type UploadUseCase struct {
+	storage service.MediaStorage
+	images  UploadImageProcessor
+}
+
+// NewUploadUseCase creates a new UploadUseCase
+func NewUploadUseCase(storage service.MediaStorage, images UploadImageProcessor) *UploadUseCase {
+	return &UploadUseCase{
+		storage: storage,
+		images:  images,
+	}
+}
+
+// UploadImage normalises an uploaded image and stores it with its variants. Storage is
+// content-addressed by the normalised image, so uploading the same image again stores nothing new.
+func (uc *UploadUseCase) UploadImage(ctx context.Context, data []byte) (*UploadResult, error) {
+	original, width, height, err := uc.images.PrepareUploadImage(data, uploadVariants[0].MaxSide)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(original)
+	result := &UploadResult{
+		Hash:         hex.EncodeToString(sum[:]),
+		Variants:     make(map[string]UploadedImage, len(uploadVariants)),
+		Deduplicated: true,
+	}
+
+	for i, variant := range uploadVariants {
+		image := original
+		if i > 0 {
+			// Variants are cut from the normalised original, which is already upright and clean
+			if image, width, height, err = uc.images.PrepareUploadImage(original, variant.MaxSide); err != nil {
+				return nil, err
+			}
+		}
+
+		key := result.Hash + "/" + variant.Name + ".jpg"
+		stored, err := uc.storeOnce(ctx, uploadPrefix+key, image)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", entity.ErrStorageUploadFailed, err)
+		}
+		if stored {
+			result.Deduplicated = false
+		}
+
+		result.Variants[variant.Name] = UploadedImage{
+			Key:    key,
+			Width:  width,
+			Height: height,
+			Size:   int64(len(image)),
+		}
+	}
+
+	return result, nil
+}
+
+// OpenUpload opens a stored upload variant by its key relative to the uploads prefix
+func (uc *UploadUseCase) OpenUpload(ctx context.Context, key string) (io.ReadSeekCloser, service.MediaObjectInfo, error) {
+	key = strings.TrimPrefix(key, "/")
+	if !uploadKeyPattern.MatchString(key) {
+		return nil, service.MediaObjectInfo{}, entity.ErrMediaNotFound
+	}
+	return openMedia(ctx, uc.storage, path.Join(uploadPrefix, key))
+}
+
+// storeOnce stores data under key unless an object is already there, reporting whether it stored it.
+// Keys are content hashes, so an existing object already holds the same bytes.
+func (uc *UploadUseCase) storeOnce(ctx context.Context, key string, data []byte) (bool, error) {
+	existing, _, err := uc.storage.Open(ctx, key)
+	if err == nil {
+		existing.Close()
+		return false, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+
+	if err := uc.storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "image/jpeg"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
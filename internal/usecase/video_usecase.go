@@ -2,6 +2,15 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"path"
+	"time"
 
 	"github.com/arabella/ai-studio-backend/internal/domain/entity"
 	"github.com/arabella/ai-studio-backend/internal/domain/repository"
@@ -9,20 +18,55 @@ import (
 	"github.com/google/uuid"
 )
 
+// maxAudioDurationDrift is how far, in seconds, a soundtrack's length may differ from the video duration
+const maxAudioDurationDrift = 1.0
+
+// watermarkRemovalStaleAfter is how long a watermark removal may run before it is taken to have been
+// lost, for instance to a restart, and is claimed again
+const watermarkRemovalStaleAfter = 15 * time.Minute
+
 // VideoGenerationRequest represents a video generation request
 type VideoGenerationRequest struct {
 	TemplateID uuid.UUID           `json:"template_id" binding:"required"`
 	Prompt     string              `json:"prompt" binding:"required,min=10,max=2000"`
 	Params     *entity.VideoParams `json:"params,omitempty"`
+	Provider   *entity.AIProvider  `json:"provider,omitempty"`
+	// StrictParams rejects the request instead of adjusting params no provider can honour
+	StrictParams bool `json:"strict_params,omitempty"`
+	// MockScenario scripts the mock provider for this job (from the X-Mock-Scenario test header)
+	MockScenario string `json:"-"`
+	// StartImageID is an uploaded image asset to animate instead of the template thumbnail
+	StartImageID *uuid.UUID `json:"start_image_id,omitempty"`
+	// AudioID is an uploaded soundtrack the video is synchronised to; its length must match the duration
+	AudioID *uuid.UUID `json:"audio_id,omitempty"`
+	// ParentJobID links a regeneration to the job it was cloned from
+	ParentJobID *uuid.UUID `json:"-"`
+}
+
+// RegenerateVideoRequest overrides settings cloned from an earlier job; empty fields keep the original's
+type RegenerateVideoRequest struct {
+	Prompt       string
+	Params       *entity.VideoParams
+	Provider     *entity.AIProvider
+	StrictParams bool
+	NewSeed      bool // Let the provider pick a fresh seed instead of reusing the original's
+	MockScenario string
 }
 
 // VideoGenerationResponse represents the response after initiating generation
 // @Description Response after starting a video generation job
 type VideoGenerationResponse struct {
-	JobID         uuid.UUID `json:"job_id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Status        string    `json:"status" example:"pending" enums:"pending,processing,diffusing,uploading,completed,failed,cancelled"`
-	EstimatedTime int       `json:"estimated_time" example:"90"` // in seconds
-	QueuePosition int       `json:"queue_position" example:"0"`
+	JobID         uuid.UUID                `json:"job_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status        string                   `json:"status" example:"pending" enums:"pending,processing,diffusing,uploading,completed,failed,cancelled"`
+	EstimatedTime int                      `json:"estimated_time" example:"90"` // in seconds
+	QueuePosition int                      `json:"queue_position" example:"0"`
+	Adjustments   []entity.ParamAdjustment `json:"adjustments,omitempty"` // Changes made so a provider could honour the request
+}
+
+// ShareLinkResponse represents a video's share link
+type ShareLinkResponse struct {
+	ShareToken string `json:"share_token" example:"3q2-7wX0bM1f9kQ4T6yZcA"`
+	StreamPath string `json:"stream_path" example:"/api/v1/videos/550e8400-e29b-41d4-a716-446655440000/stream?share=3q2-7wX0bM1f9kQ4T6yZcA"` // Relative to the API host
 }
 
 // VideoJobListRequest represents a request to list video jobs
@@ -53,9 +97,13 @@ type VideoUseCase struct {
 	jobRepo          repository.VideoJobRepository
 	templateRepo     repository.TemplateRepository
 	userRepo         repository.UserRepository
+	assetRepo        repository.UserAssetRepository
 	providerSelector service.ProviderSelector
 	jobQueue         JobQueueService
 	wsHub            WebSocketHub
+	mediaStorage     service.MediaStorage
+	mediaURLs        MediaURLSigner
+	hls              HLSPublisher
 }
 
 // WebSocketHub interface for real-time updates
@@ -69,17 +117,25 @@ func NewVideoUseCase(
 	jobRepo repository.VideoJobRepository,
 	templateRepo repository.TemplateRepository,
 	userRepo repository.UserRepository,
+	assetRepo repository.UserAssetRepository,
 	providerSelector service.ProviderSelector,
 	jobQueue JobQueueService,
 	wsHub WebSocketHub,
+	mediaStorage service.MediaStorage,
+	mediaURLs MediaURLSigner,
+	hls HLSPublisher,
 ) *VideoUseCase {
 	return &VideoUseCase{
 		jobRepo:          jobRepo,
 		templateRepo:     templateRepo,
 		userRepo:         userRepo,
+		assetRepo:        assetRepo,
 		providerSelector: providerSelector,
 		jobQueue:         jobQueue,
 		wsHub:            wsHub,
+		mediaStorage:     mediaStorage,
+		mediaURLs:        mediaURLs,
+		hls:              hls,
 	}
 }
 
@@ -110,27 +166,80 @@ func (uc *VideoUseCase) GenerateVideo(ctx context.Context, userID uuid.UUID, req
 		return nil, entity.ErrInsufficientCredits
 	}
 
-	// Merge params with template defaults
-	params := template.DefaultParams
-	if req.Params != nil {
-		if req.Params.Duration > 0 {
-			params.Duration = req.Params.Duration
+	// Validate provider override
+	if req.Provider != nil {
+		if !user.IsPremium() {
+			return nil, entity.ErrProviderOverrideNotAllowed
+		}
+		if !uc.providerSelector.IsRegistered(*req.Provider) {
+			return nil, entity.ErrUnknownProvider
+		}
+	}
+
+	// Validate the start image: it must be one of the user's own image assets
+	if req.StartImageID != nil {
+		asset, err := uc.assetRepo.GetByID(ctx, *req.StartImageID)
+		if err != nil {
+			return nil, err
+		}
+		if !asset.IsOwnedBy(userID) {
+			return nil, entity.ErrAssetNotFound
 		}
-		if req.Params.Resolution != "" {
-			params.Resolution = req.Params.Resolution
+		if asset.Kind != entity.AssetKindImage {
+			return nil, entity.NewDomainError("VALIDATION_ERROR", "start_image_id must refer to an image asset", entity.ErrInvalidAsset)
 		}
-		if req.Params.AspectRatio != "" {
-			params.AspectRatio = req.Params.AspectRatio
+	}
+
+	// Validate the soundtrack the same way; its length is checked once params are final
+	var audio *entity.UserAsset
+	if req.AudioID != nil {
+		audio, err = uc.assetRepo.GetByID(ctx, *req.AudioID)
+		if err != nil {
+			return nil, err
 		}
-		if req.Params.FPS > 0 {
-			params.FPS = req.Params.FPS
+		if !audio.IsOwnedBy(userID) {
+			return nil, entity.ErrAssetNotFound
 		}
-		if req.Params.Style != "" {
-			params.Style = req.Params.Style
+		if audio.Kind != entity.AssetKindAudio {
+			return nil, entity.NewDomainError("VALIDATION_ERROR", "audio_id must refer to an audio asset", entity.ErrInvalidAsset)
 		}
-		if req.Params.NegativePrompt != "" {
-			params.NegativePrompt = req.Params.NegativePrompt
+	}
+
+	// Merge params with template defaults
+	params := mergeVideoParams(template.DefaultParams, req.Params)
+	if err := params.Advanced.Validate(); err != nil {
+		return nil, entity.NewDomainError("VALIDATION_ERROR", err.Error(), err)
+	}
+
+	// Check params against provider capabilities before anything is charged
+	selectionReq := service.ProviderSelectionRequest{
+		UserTier:           user.Tier,
+		RequestedProvider:  req.Provider,
+		RequiresImageInput: req.StartImageID != nil,
+		RequiresAudioInput: req.AudioID != nil,
+	}
+	if template.PreferredProvider != nil && *template.PreferredProvider != "" {
+		templateProvider := entity.AIProvider(*template.PreferredProvider)
+		selectionReq.TemplateProvider = &templateProvider
+	}
+	normalization, err := uc.providerSelector.NormalizeParams(ctx, selectionReq, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(normalization.Adjustments) > 0 {
+		if req.StrictParams {
+			return nil, entity.NewDomainError("VALIDATION_ERROR",
+				"Requested parameters cannot be honoured: "+normalization.Explanation,
+				entity.ErrParamsNotSupported)
 		}
+		params = normalization.Params
+	}
+
+	// The soundtrack drives the video, so it must be as long as the requested duration
+	if audio != nil && math.Abs(audio.Duration-float64(params.Duration)) > maxAudioDurationDrift {
+		return nil, entity.NewDomainError("VALIDATION_ERROR",
+			fmt.Sprintf("Audio is %.1f seconds but the video duration is %d seconds", audio.Duration, params.Duration),
+			entity.ErrInvalidParams)
 	}
 
 	// Use only the user's prompt (ignore template base prompt)
@@ -138,6 +247,15 @@ func (uc *VideoUseCase) GenerateVideo(ctx context.Context, userID uuid.UUID, req
 
 	// Create the job
 	job := entity.NewVideoJob(userID, template.ID, fullPrompt, params, template.CreditCost)
+	job.RequestedProvider = req.Provider
+	job.ParamAdjustments = normalization.Adjustments
+	job.ParentJobID = req.ParentJobID
+	job.StartImageID = req.StartImageID
+	job.AudioID = req.AudioID
+	job.MockScenario = req.MockScenario
+	if params.Advanced != nil {
+		job.SetSeed(params.Advanced.Seed)
+	}
 
 	// Deduct credits
 	if err := uc.userRepo.UpdateCredits(ctx, userID, -template.CreditCost); err != nil {
@@ -173,9 +291,103 @@ func (uc *VideoUseCase) GenerateVideo(ctx context.Context, userID uuid.UUID, req
 		Status:        string(job.Status),
 		EstimatedTime: estimatedTime,
 		QueuePosition: queuePosition,
+		Adjustments:   job.ParamAdjustments,
 	}, nil
 }
 
+// RegenerateVideo starts a new job with an earlier job's template, prompt, params and seed,
+// applying any overrides. It is charged as a normal generation.
+func (uc *VideoUseCase) RegenerateVideo(ctx context.Context, userID, jobID uuid.UUID, req RegenerateVideoRequest) (*VideoGenerationResponse, error) {
+	parent, err := uc.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership
+	if parent.UserID != userID {
+		return nil, entity.ErrUnauthorized
+	}
+
+	params := parent.Params
+	if params.Advanced != nil {
+		advanced := *params.Advanced
+		params.Advanced = &advanced
+	}
+	params = mergeVideoParams(params, req.Params)
+
+	// Reuse the original seed unless the overrides pick one or ask for a fresh one
+	overrideSeed := req.Params != nil && req.Params.Advanced != nil && req.Params.Advanced.Seed != nil
+	if !overrideSeed {
+		if req.NewSeed {
+			if params.Advanced != nil {
+				advanced := *params.Advanced
+				advanced.Seed = nil
+				params.Advanced = &advanced
+			}
+		} else if parent.Seed != nil {
+			if params.Advanced == nil {
+				params.Advanced = &entity.AdvancedParams{}
+			} else {
+				advanced := *params.Advanced
+				params.Advanced = &advanced
+			}
+			seed := *parent.Seed
+			params.Advanced.Seed = &seed
+		}
+	}
+
+	prompt := parent.Prompt
+	if req.Prompt != "" {
+		prompt = req.Prompt
+	}
+
+	provider := parent.RequestedProvider
+	if req.Provider != nil {
+		provider = req.Provider
+	}
+
+	return uc.GenerateVideo(ctx, userID, VideoGenerationRequest{
+		TemplateID:   parent.TemplateID,
+		Prompt:       prompt,
+		Params:       &params,
+		Provider:     provider,
+		StrictParams: req.StrictParams,
+		MockScenario: req.MockScenario,
+		StartImageID: parent.StartImageID,
+		AudioID:      parent.AudioID,
+		ParentJobID:  &parent.ID,
+	})
+}
+
+// mergeVideoParams applies the set fields of override on top of base
+func mergeVideoParams(base entity.VideoParams, override *entity.VideoParams) entity.VideoParams {
+	if override == nil {
+		return base
+	}
+	if override.Duration > 0 {
+		base.Duration = override.Duration
+	}
+	if override.Resolution != "" {
+		base.Resolution = override.Resolution
+	}
+	if override.AspectRatio != "" {
+		base.AspectRatio = override.AspectRatio
+	}
+	if override.FPS > 0 {
+		base.FPS = override.FPS
+	}
+	if override.Style != "" {
+		base.Style = override.Style
+	}
+	if override.NegativePrompt != "" {
+		base.NegativePrompt = override.NegativePrompt
+	}
+	if !override.Advanced.IsEmpty() {
+		base.Advanced = override.Advanced
+	}
+	return base
+}
+
 // GetJobStatus retrieves the status of a video job
 func (uc *VideoUseCase) GetJobStatus(ctx context.Context, userID, jobID uuid.UUID) (*entity.VideoJob, error) {
 	job, err := uc.jobRepo.GetByID(ctx, jobID)
@@ -188,6 +400,35 @@ func (uc *VideoUseCase) GetJobStatus(ctx context.Context, userID, jobID uuid.UUI
 		return nil, entity.ErrUnauthorized
 	}
 
+	uc.signMediaURLs(job)
+	return job, nil
+}
+
+// RateVideo records the user's 1-5 rating of a completed video
+func (uc *VideoUseCase) RateVideo(ctx context.Context, userID, jobID uuid.UUID, rating int) (*entity.VideoJob, error) {
+	job, err := uc.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership
+	if job.UserID != userID {
+		return nil, entity.ErrUnauthorized
+	}
+
+	if job.Status != entity.JobStatusCompleted {
+		return nil, entity.NewDomainError("VIDEO_NOT_READY", "Only completed videos can be rated", nil)
+	}
+
+	if err := job.Rate(rating); err != nil {
+		return nil, entity.NewDomainError("VALIDATION_ERROR", "Rating must be between 1 and 5", err)
+	}
+
+	if err := uc.jobRepo.Update(ctx, job); err != nil {
+		return nil, err
+	}
+
+	uc.signMediaURLs(job)
 	return job, nil
 }
 
@@ -218,6 +459,9 @@ func (uc *VideoUseCase) GetUserJobs(ctx context.Context, userID uuid.UUID, req V
 	if err != nil {
 		return nil, err
 	}
+	for _, job := range jobs {
+		uc.signMediaURLs(job)
+	}
 
 	// Calculate total pages
 	totalPages := int(total) / req.PageSize
@@ -277,7 +521,15 @@ func (uc *VideoUseCase) GetRecentJobs(ctx context.Context, userID uuid.UUID, lim
 		limit = 10
 	}
 
-	return uc.jobRepo.GetRecentByUser(ctx, userID, limit)
+	jobs, err := uc.jobRepo.GetRecentByUser(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		uc.signMediaURLs(job)
+	}
+	return jobs, nil
 }
 
 // GetVideo retrieves a completed video
@@ -296,5 +548,224 @@ func (uc *VideoUseCase) GetVideo(ctx context.Context, userID, jobID uuid.UUID) (
 		return nil, entity.NewDomainError("VIDEO_NOT_READY", "Video is not ready yet", nil)
 	}
 
+	uc.signMediaURLs(job)
 	return job, nil
 }
+
+// RemoveWatermark queues the switch of a watermarked video to the clean original kept when it was
+// generated, for users who have since upgraded. The switch itself is made in the background by
+// ProcessWatermarkRemoval.
+func (uc *VideoUseCase) RemoveWatermark(ctx context.Context, userID, jobID uuid.UUID) (*entity.VideoJob, error) {
+	job, err := uc.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership
+	if job.UserID != userID {
+		return nil, entity.ErrUnauthorized
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsPremium() {
+		return nil, entity.ErrWatermarkRemovalNotAllowed
+	}
+
+	if !job.Watermarked || job.SourceKey == nil {
+		return nil, entity.ErrVideoNotWatermarked
+	}
+
+	// Only one removal may be queued or running per job, whatever the job looked like when it was read
+	queued, err := uc.jobRepo.RequestWatermarkRemoval(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !queued {
+		return nil, entity.ErrWatermarkRemovalInProgress
+	}
+
+	status := entity.WatermarkRemovalPending
+	job.WatermarkRemoval = &status
+	uc.signMediaURLs(job)
+	return job, nil
+}
+
+// ProcessWatermarkRemoval runs the oldest queued watermark removal, reporting false if none was
+// queued. Nothing is generated again, but HLS renditions are transcoded again from the original
+// before the switch, since the old ones show the watermark.
+func (uc *VideoUseCase) ProcessWatermarkRemoval(ctx context.Context) (bool, error) {
+	job, err := uc.jobRepo.ClaimWatermarkRemoval(ctx, watermarkRemovalStaleAfter)
+	if err != nil {
+		return false, err
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	if err := uc.removeWatermark(ctx, job); err != nil {
+		_ = uc.jobRepo.FailWatermarkRemoval(ctx, job.ID)
+		uc.broadcastWatermarkRemoval(job.ID, string(entity.WatermarkRemovalFailed))
+		return true, fmt.Errorf("failed to remove watermark from job %s: %w", job.ID, err)
+	}
+
+	return true, nil
+}
+
+// removeWatermark switches a claimed job to its clean original
+func (uc *VideoUseCase) removeWatermark(ctx context.Context, job *entity.VideoJob) error {
+	if !job.Watermarked || job.SourceKey == nil {
+		return entity.ErrVideoNotWatermarked
+	}
+
+	// The new renditions get their own prefix so the old ones keep playing until the job is switched
+	oldHLSKey := job.HLSStorageKey
+	newHLSDir := ""
+	if oldHLSKey != nil {
+		playlistKey, err := uc.hls.PublishStored(ctx, *job.SourceKey, fmt.Sprintf("hls/%s/%s-%d", job.UserID, job.ID, time.Now().Unix()))
+		switch {
+		case errors.Is(err, entity.ErrMediaToolUnavailable):
+			// Without ffmpeg the watermarked renditions cannot be replaced, so the job falls back to its MP4
+			job.ClearHLS()
+		case err != nil:
+			return fmt.Errorf("failed to transcode HLS renditions: %w", err)
+		default:
+			job.StoreHLS(playlistKey, uc.mediaStorage.URL(playlistKey))
+			newHLSDir = path.Dir(playlistKey) + "/"
+		}
+	}
+
+	watermarkedKey := job.RemoveWatermark(uc.mediaStorage.URL(*job.SourceKey))
+	switched, err := uc.jobRepo.CompleteWatermarkRemoval(ctx, job)
+	if err != nil || !switched {
+		// The job still points at its old media, so only the renditions made here are dropped. A job
+		// that was not switched had its removal finished or failed by a run that claimed it again.
+		if newHLSDir != "" {
+			uc.deleteDir(ctx, newHLSDir)
+		}
+		return err
+	}
+
+	// The job no longer references the watermarked copies; any left behind by a failure here are garbage collected
+	_ = uc.mediaStorage.Delete(ctx, watermarkedKey)
+	if oldHLSKey != nil {
+		uc.deleteDir(ctx, path.Dir(*oldHLSKey)+"/")
+	}
+
+	uc.broadcastWatermarkRemoval(job.ID, "completed")
+	return nil
+}
+
+// broadcastWatermarkRemoval tells clients watching a job how its watermark removal ended
+func (uc *VideoUseCase) broadcastWatermarkRemoval(jobID uuid.UUID, status string) {
+	if uc.wsHub != nil {
+		uc.wsHub.BroadcastToJob(jobID, "watermark_removal", map[string]interface{}{
+			"job_id": jobID.String(),
+			"status": status,
+		})
+	}
+}
+
+// deleteDir deletes every stored object under prefix, leaving any that fail for garbage collection
+func (uc *VideoUseCase) deleteDir(ctx context.Context, prefix string) {
+	var keys []string
+	_ = uc.mediaStorage.Walk(ctx, prefix, func(key string, _ service.MediaObjectInfo) error {
+		keys = append(keys, key)
+		return nil
+	})
+	for _, key := range keys {
+		_ = uc.mediaStorage.Delete(ctx, key)
+	}
+}
+
+// ShareVideo returns the share link of a completed video, creating one if it has none
+func (uc *VideoUseCase) ShareVideo(ctx context.Context, userID, jobID uuid.UUID) (*ShareLinkResponse, error) {
+	job, err := uc.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify ownership
+	if job.UserID != userID {
+		return nil, entity.ErrUnauthorized
+	}
+
+	if job.Status != entity.JobStatusCompleted {
+		return nil, entity.NewDomainError("VIDEO_NOT_READY", "Only completed videos can be shared", nil)
+	}
+
+	if job.ShareToken == nil {
+		token, err := newShareToken()
+		if err != nil {
+			return nil, err
+		}
+		job.Share(token)
+		if err := uc.jobRepo.Update(ctx, job); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ShareLinkResponse{
+		ShareToken: *job.ShareToken,
+		StreamPath: fmt.Sprintf("/api/v1/videos/%s/stream?share=%s", job.ID, url.QueryEscape(*job.ShareToken)),
+	}, nil
+}
+
+// UnshareVideo revokes a video's share link; links created afterwards use a new token
+func (uc *VideoUseCase) UnshareVideo(ctx context.Context, userID, jobID uuid.UUID) error {
+	job, err := uc.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return err
+	}
+
+	// Verify ownership
+	if job.UserID != userID {
+		return entity.ErrUnauthorized
+	}
+
+	if job.ShareToken == nil {
+		return nil
+	}
+	job.Unshare()
+	return uc.jobRepo.Update(ctx, job)
+}
+
+// OpenVideoStream opens a completed video's stored file for streaming. The viewer must own the job
+// or hold its share token; userID is nil for signed-out viewers.
+func (uc *VideoUseCase) OpenVideoStream(ctx context.Context, userID *uuid.UUID, jobID uuid.UUID, shareToken string) (io.ReadSeekCloser, service.MediaObjectInfo, error) {
+	job, err := uc.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, service.MediaObjectInfo{}, err
+	}
+
+	if !job.CanBeStreamedBy(userID, shareToken) {
+		return nil, service.MediaObjectInfo{}, entity.ErrUnauthorized
+	}
+
+	if job.Status != entity.JobStatusCompleted {
+		return nil, service.MediaObjectInfo{}, entity.NewDomainError("VIDEO_NOT_READY", "Video is not ready yet", nil)
+	}
+
+	// Videos completed before results were copied into media storage only have a provider URL
+	if job.VideoStorageKey == nil {
+		return nil, service.MediaObjectInfo{}, entity.ErrMediaNotFound
+	}
+
+	return openMedia(ctx, uc.mediaStorage, *job.VideoStorageKey)
+}
+
+// newShareToken returns a random, URL-safe share token
+func newShareToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
+
+// signMediaURLs replaces the stored URLs of a job's media with signed, expiring links
+func (uc *VideoUseCase) signMediaURLs(job *entity.VideoJob) {
+	job.SignMediaURLs(uc.mediaURLs.SignMediaURL, uc.mediaURLs.SignMediaDirURL)
+}
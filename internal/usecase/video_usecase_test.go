@@ -0,0 +1,412 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arabella/ai-studio-backend/internal/domain/entity"
+	"github.com/arabella/ai-studio-backend/internal/domain/repository"
+	"github.com/arabella/ai-studio-backend/internal/domain/service"
+	"github.com/google/uuid"
+)
+
+type fakeJobRepo struct {
+	repository.VideoJobRepository
+	job     *entity.VideoJob
+	created *entity.VideoJob
+	updates int
+}
+
+func (r *fakeJobRepo) Create(ctx context.Context, job *entity.VideoJob) error {
+	r.created = job
+	return nil
+}
+
+func (r *fakeJobRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.VideoJob, error) {
+	return r.job, nil
+}
+
+func (r *fakeJobRepo) Update(ctx context.Context, job *entity.VideoJob) error {
+	r.updates++
+	return nil
+}
+
+// The watermark removal methods follow the compare-and-set rules of the Postgres repository, and
+// hand out a copy of the job like a fresh query would
+func (r *fakeJobRepo) RequestWatermarkRemoval(ctx context.Context, id uuid.UUID) (bool, error) {
+	if !r.job.Watermarked || (r.job.WatermarkRemoval != nil && *r.job.WatermarkRemoval != entity.WatermarkRemovalFailed) {
+		return false, nil
+	}
+	r.setWatermarkRemoval(entity.WatermarkRemovalPending)
+	return true, nil
+}
+
+func (r *fakeJobRepo) ClaimWatermarkRemoval(ctx context.Context, staleAfter time.Duration) (*entity.VideoJob, error) {
+	if r.job.WatermarkRemoval == nil || *r.job.WatermarkRemoval != entity.WatermarkRemovalPending {
+		return nil, nil
+	}
+	r.setWatermarkRemoval(entity.WatermarkRemovalProcessing)
+	claimed := *r.job
+	return &claimed, nil
+}
+
+func (r *fakeJobRepo) CompleteWatermarkRemoval(ctx context.Context, job *entity.VideoJob) (bool, error) {
+	if !r.job.Watermarked || r.job.WatermarkRemoval == nil || *r.job.WatermarkRemoval != entity.WatermarkRemovalProcessing {
+		return false, nil
+	}
+	r.job.VideoURL = job.VideoURL
+	r.job.VideoStorageKey = job.VideoStorageKey
+	r.job.VideoExpiresAt = job.VideoExpiresAt
+	r.job.SourceKey = job.SourceKey
+	r.job.Watermarked = job.Watermarked
+	r.job.HLSPlaylistURL = job.HLSPlaylistURL
+	r.job.HLSStorageKey = job.HLSStorageKey
+	r.job.WatermarkRemoval = nil
+	return true, nil
+}
+
+func (r *fakeJobRepo) FailWatermarkRemoval(ctx context.Context, id uuid.UUID) error {
+	if r.job.WatermarkRemoval != nil && *r.job.WatermarkRemoval == entity.WatermarkRemovalProcessing {
+		r.setWatermarkRemoval(entity.WatermarkRemovalFailed)
+	}
+	return nil
+}
+
+func (r *fakeJobRepo) setWatermarkRemoval(status entity.WatermarkRemovalStatus) {
+	r.job.WatermarkRemoval = &status
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	user *entity.User
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.User, error) {
+	return r.user, nil
+}
+
+func (r *fakeUserRepo) UpdateCredits(ctx context.Context, id uuid.UUID, delta int) error {
+	return nil
+}
+
+type fakeTemplateRepo struct {
+	repository.TemplateRepository
+	template *entity.Template
+}
+
+func (r *fakeTemplateRepo) GetByID(ctx context.Context, id uuid.UUID) (*entity.Template, error) {
+	return r.template, nil
+}
+
+func (r *fakeTemplateRepo) IncrementUsage(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+// fakeSelector accepts every provider and leaves params unchanged
+type fakeSelector struct {
+	service.ProviderSelector
+}
+
+func (fakeSelector) IsRegistered(name entity.AIProvider) bool { return true }
+
+func (fakeSelector) NormalizeParams(ctx context.Context, req service.ProviderSelectionRequest, params entity.VideoParams) (*service.ParamNormalization, error) {
+	return &service.ParamNormalization{Params: params}, nil
+}
+
+// fakeQueue records enqueued jobs
+type fakeQueue struct {
+	jobs []*entity.VideoJob
+}
+
+func (q *fakeQueue) Enqueue(ctx context.Context, job *entity.VideoJob) error {
+	q.jobs = append(q.jobs, job)
+	return nil
+}
+
+func (q *fakeQueue) GetQueuePosition(ctx context.Context, jobID uuid.UUID) (int, error) {
+	return 0, nil
+}
+func (q *fakeQueue) GetQueueDepth(ctx context.Context) (int, error) { return len(q.jobs), nil }
+
+// fakeMediaStorage keeps object keys in memory
+type fakeMediaStorage struct {
+	keys map[string]bool
+}
+
+func (s *fakeMediaStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	s.keys[key] = true
+	return nil
+}
+
+func (s *fakeMediaStorage) Open(ctx context.Context, key string) (io.ReadSeekCloser, service.MediaObjectInfo, error) {
+	return nil, service.MediaObjectInfo{}, errors.New("not implemented")
+}
+
+func (s *fakeMediaStorage) Delete(ctx context.Context, key string) error {
+	delete(s.keys, key)
+	return nil
+}
+
+func (s *fakeMediaStorage) URL(key string) string {
+	return "https://cdn.arabella.app/" + key
+}
+
+func (s *fakeMediaStorage) Walk(ctx context.Context, prefix string, fn func(key string, info service.MediaObjectInfo) error) error {
+	for key := range s.keys {
+		if strings.HasPrefix(key, prefix) {
+			if err := fn(key, service.MediaObjectInfo{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *fakeMediaStorage) list() []string {
+	keys := make([]string, 0, len(s.keys))
+	for key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fakeHLSPublisher stores a two-file ladder under the prefix, or fails with err
+type fakeHLSPublisher struct {
+	storage   *fakeMediaStorage
+	err       error
+	published string // Key of the video transcoded
+	during    func() // Called while transcoding, to change the job behind the caller's back
+}
+
+func (p *fakeHLSPublisher) PublishStored(ctx context.Context, videoKey, prefix string) (string, error) {
+	if p.during != nil {
+		p.during()
+	}
+	if p.err != nil {
+		return "", p.err
+	}
+	p.published = videoKey
+	p.storage.keys[prefix+"/720p/segment_000.ts"] = true
+	p.storage.keys[prefix+"/master.m3u8"] = true
+	return prefix + "/master.m3u8", nil
+}
+
+type fakeURLSigner struct{}
+
+func (fakeURLSigner) SignMediaURL(key string) string         { return "signed:" + key }
+func (fakeURLSigner) SignMediaDirURL(key string) string      { return "signed-dir:" + key }
+func (fakeURLSigner) VerifyMediaURL(token, key string) error { return nil }
+
+// newWatermarkTest returns a use case holding one watermarked job with HLS renditions, owned by a premium user
+func newWatermarkTest(publishErr error) (*VideoUseCase, *fakeJobRepo, *fakeMediaStorage, *fakeHLSPublisher) {
+	user := &entity.User{ID: uuid.New(), Tier: entity.UserTierPremium}
+	job := entity.NewVideoJob(user.ID, uuid.New(), "A paper boat", entity.VideoParams{Duration: 5}, 10)
+	job.Status = entity.JobStatusCompleted
+
+	storage := &fakeMediaStorage{keys: map[string]bool{
+		"videos/u/j.mp4":              true,
+		"sources/u/j.mp4":             true,
+		"hls/u/j/master.m3u8":         true,
+		"hls/u/j/720p.m3u8":           true,
+		"hls/u/j/720p/segment_000.ts": true,
+		"hls/u/j-other/master.m3u8":   true,
+		"thumbnails/u/j.jpg":          true,
+	}}
+	job.StoreWatermarked("videos/u/j.mp4", storage.URL("videos/u/j.mp4"), "sources/u/j.mp4")
+	job.StoreHLS("hls/u/j/master.m3u8", storage.URL("hls/u/j/master.m3u8"))
+
+	jobs := &fakeJobRepo{job: job}
+	publisher := &fakeHLSPublisher{storage: storage, err: publishErr}
+	uc := NewVideoUseCase(jobs, nil, &fakeUserRepo{user: user}, nil, nil, nil, nil, storage, fakeURLSigner{}, publisher)
+	return uc, jobs, storage, publisher
+}
+
+// queueWatermarkRemoval requests a removal for the test's job, failing the test if it is not queued
+func queueWatermarkRemoval(t *testing.T, uc *VideoUseCase, job *entity.VideoJob) {
+	t.Helper()
+	if _, err := uc.RemoveWatermark(context.Background(), job.UserID, job.ID); err != nil {
+		t.Fatalf("RemoveWatermark: %v", err)
+	}
+}
+
+func TestRemoveWatermarkQueuesOnce(t *testing.T) {
+	uc, jobs, storage, publisher := newWatermarkTest(nil)
+	job := jobs.job
+
+	result, err := uc.RemoveWatermark(context.Background(), job.UserID, job.ID)
+	if err != nil {
+		t.Fatalf("RemoveWatermark: %v", err)
+	}
+	if result.WatermarkRemoval == nil || *result.WatermarkRemoval != entity.WatermarkRemovalPending {
+		t.Errorf("watermark removal = %v, want pending", result.WatermarkRemoval)
+	}
+	if publisher.published != "" || !job.Watermarked || len(storage.keys) != 7 {
+		t.Errorf("removal ran inside the request: transcoded %q, stored keys %v", publisher.published, storage.list())
+	}
+
+	// A second request cannot queue another removal while the first is queued or running
+	if _, err := uc.RemoveWatermark(context.Background(), job.UserID, job.ID); !errors.Is(err, entity.ErrWatermarkRemovalInProgress) {
+		t.Errorf("second RemoveWatermark error = %v, want %v", err, entity.ErrWatermarkRemovalInProgress)
+	}
+	if _, err := uc.ProcessWatermarkRemoval(context.Background()); err != nil {
+		t.Fatalf("ProcessWatermarkRemoval: %v", err)
+	}
+	if processed, _ := uc.ProcessWatermarkRemoval(context.Background()); processed {
+		t.Error("the removal ran twice")
+	}
+}
+
+func TestProcessWatermarkRemovalReplacesHLS(t *testing.T) {
+	uc, jobs, storage, publisher := newWatermarkTest(nil)
+	job := jobs.job
+	queueWatermarkRemoval(t, uc, job)
+
+	processed, err := uc.ProcessWatermarkRemoval(context.Background())
+	if err != nil || !processed {
+		t.Fatalf("ProcessWatermarkRemoval = %v, %v; want the queued removal run", processed, err)
+	}
+
+	if publisher.published != "sources/u/j.mp4" {
+		t.Errorf("transcoded %q, want the clean original", publisher.published)
+	}
+	if job.Watermarked || job.SourceKey != nil || *job.VideoStorageKey != "sources/u/j.mp4" {
+		t.Errorf("job still points at the watermarked video: key %v, watermarked %v", *job.VideoStorageKey, job.Watermarked)
+	}
+	if job.HLSStorageKey == nil || !strings.HasPrefix(*job.HLSStorageKey, "hls/"+job.UserID.String()+"/"+job.ID.String()+"-") {
+		t.Fatalf("HLS key = %v, want the new renditions", job.HLSStorageKey)
+	}
+	if job.WatermarkRemoval != nil {
+		t.Errorf("watermark removal = %v, want none once finished", *job.WatermarkRemoval)
+	}
+
+	// The watermarked MP4 and the old renditions are gone; everything else is kept
+	for _, key := range []string{"videos/u/j.mp4", "hls/u/j/master.m3u8", "hls/u/j/720p.m3u8", "hls/u/j/720p/segment_000.ts"} {
+		if storage.keys[key] {
+			t.Errorf("%s was not deleted", key)
+		}
+	}
+	for _, key := range []string{"sources/u/j.mp4", "hls/u/j-other/master.m3u8", "thumbnails/u/j.jpg", *job.HLSStorageKey} {
+		if !storage.keys[key] {
+			t.Errorf("%s was deleted; stored keys: %v", key, storage.list())
+		}
+	}
+}
+
+func TestProcessWatermarkRemovalWithoutFFmpegDropsHLS(t *testing.T) {
+	uc, jobs, storage, _ := newWatermarkTest(entity.ErrMediaToolUnavailable)
+	job := jobs.job
+	queueWatermarkRemoval(t, uc, job)
+
+	if _, err := uc.ProcessWatermarkRemoval(context.Background()); err != nil {
+		t.Fatalf("ProcessWatermarkRemoval: %v", err)
+	}
+
+	if job.HLSStorageKey != nil || job.HLSPlaylistURL != nil {
+		t.Errorf("watermarked HLS renditions kept: %v", *job.HLSStorageKey)
+	}
+	if *job.VideoStorageKey != "sources/u/j.mp4" {
+		t.Errorf("video key = %q, want the clean original", *job.VideoStorageKey)
+	}
+	if storage.keys["hls/u/j/master.m3u8"] {
+		t.Error("old renditions were not deleted")
+	}
+}
+
+func TestProcessWatermarkRemovalTranscodeFailureKeepsJob(t *testing.T) {
+	uc, jobs, storage, _ := newWatermarkTest(errors.New("ffmpeg exited with status 1"))
+	job := jobs.job
+	queueWatermarkRemoval(t, uc, job)
+
+	if _, err := uc.ProcessWatermarkRemoval(context.Background()); err == nil {
+		t.Fatal("ProcessWatermarkRemoval succeeded without new renditions")
+	}
+
+	if !job.Watermarked || *job.VideoStorageKey != "videos/u/j.mp4" || *job.HLSStorageKey != "hls/u/j/master.m3u8" {
+		t.Errorf("job changed after a failed transcode: video %q, HLS %v", *job.VideoStorageKey, job.HLSStorageKey)
+	}
+	if job.WatermarkRemoval == nil || *job.WatermarkRemoval != entity.WatermarkRemovalFailed {
+		t.Errorf("watermark removal = %v, want failed", job.WatermarkRemoval)
+	}
+	if len(storage.keys) != 7 {
+		t.Errorf("stored keys = %v, want nothing deleted", storage.list())
+	}
+
+	// A failed removal can be requested again
+	queueWatermarkRemoval(t, uc, job)
+}
+
+func TestProcessWatermarkRemovalWithoutHLS(t *testing.T) {
+	uc, jobs, _, publisher := newWatermarkTest(nil)
+	job := jobs.job
+	job.ClearHLS()
+	queueWatermarkRemoval(t, uc, job)
+
+	if _, err := uc.ProcessWatermarkRemoval(context.Background()); err != nil {
+		t.Fatalf("ProcessWatermarkRemoval: %v", err)
+	}
+
+	if publisher.published != "" {
+		t.Errorf("transcoded %q for a job that had no renditions", publisher.published)
+	}
+	if job.HLSStorageKey != nil {
+		t.Errorf("HLS key = %q, want none", *job.HLSStorageKey)
+	}
+}
+
+func TestProcessWatermarkRemovalSupersededKeepsMedia(t *testing.T) {
+	uc, jobs, storage, publisher := newWatermarkTest(nil)
+	job := jobs.job
+	queueWatermarkRemoval(t, uc, job)
+
+	// Another run that claimed the removal again gives up on it while this one transcodes
+	publisher.during = func() { _ = jobs.FailWatermarkRemoval(context.Background(), job.ID) }
+
+	if _, err := uc.ProcessWatermarkRemoval(context.Background()); err != nil {
+		t.Fatalf("ProcessWatermarkRemoval: %v", err)
+	}
+
+	if !job.Watermarked || *job.VideoStorageKey != "videos/u/j.mp4" || *job.HLSStorageKey != "hls/u/j/master.m3u8" {
+		t.Errorf("superseded run switched the job: video %q, HLS %v", *job.VideoStorageKey, job.HLSStorageKey)
+	}
+	if len(storage.keys) != 7 {
+		t.Errorf("stored keys = %v, want the job's media kept and the new renditions dropped", storage.list())
+	}
+}
+
+func TestRegenerateVideoDropsMockScenario(t *testing.T) {
+	user := &entity.User{ID: uuid.New(), Tier: entity.UserTierPremium, Credits: 100}
+	template := &entity.Template{ID: uuid.New(), IsActive: true, CreditCost: 10}
+	seed := int64(421337)
+	parent := entity.NewVideoJob(user.ID, template.ID, "A paper boat drifting down a rainy street", entity.VideoParams{Duration: 5}, 10)
+	parent.MockScenario = "fail_progress"
+	parent.SetSeed(&seed)
+
+	jobs := &fakeJobRepo{job: parent}
+	queue := &fakeQueue{}
+	uc := NewVideoUseCase(jobs, &fakeTemplateRepo{template: template}, &fakeUserRepo{user: user}, nil, fakeSelector{}, queue, nil, nil, fakeURLSigner{}, nil)
+
+	if _, err := uc.RegenerateVideo(context.Background(), user.ID, parent.ID, RegenerateVideoRequest{}); err != nil {
+		t.Fatalf("RegenerateVideo: %v", err)
+	}
+	child := jobs.created
+	if child.MockScenario != "" {
+		t.Errorf("regeneration inherited mock scenario %q", child.MockScenario)
+	}
+	if child.Seed == nil || *child.Seed != seed || *child.ParentJobID != parent.ID {
+		t.Errorf("regeneration lost the parent's seed or link: seed %v, parent %v", child.Seed, child.ParentJobID)
+	}
+
+	// A scenario is still honoured when the regeneration asks for one
+	if _, err := uc.RegenerateVideo(context.Background(), user.ID, parent.ID, RegenerateVideoRequest{MockScenario: "stall"}); err != nil {
+		t.Fatalf("RegenerateVideo: %v", err)
+	}
+	if jobs.created.MockScenario != "stall" {
+		t.Errorf("mock scenario = %q, want stall", jobs.created.MockScenario)
+	}
+}
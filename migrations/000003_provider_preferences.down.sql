@@ -0,0 +1,4 @@
+-- Drop provider selection columns
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS provider_reason,
+    DROP COLUMN IF EXISTS requested_provider;
@@ -0,0 +1,4 @@
+-- Record the requested provider override and why the provider was chosen
+ALTER TABLE video_jobs
+    ADD COLUMN requested_provider VARCHAR(50),
+    ADD COLUMN provider_reason TEXT;
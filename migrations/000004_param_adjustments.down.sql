@@ -0,0 +1,3 @@
+-- Drop parameter adjustments column
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS param_adjustments;
@@ -0,0 +1,3 @@
+-- Record parameter adjustments made so a provider could honour the request
+ALTER TABLE video_jobs
+    ADD COLUMN param_adjustments JSONB;
@@ -0,0 +1,4 @@
+-- Drop video result columns
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS video_expires_at,
+    DROP COLUMN IF EXISTS output_resolution;
@@ -0,0 +1,4 @@
+-- Record the actual output resolution and when provider-hosted URLs expire
+ALTER TABLE video_jobs
+    ADD COLUMN output_resolution VARCHAR(10),
+    ADD COLUMN video_expires_at TIMESTAMPTZ;
@@ -0,0 +1,3 @@
+-- Drop provider API key column
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS provider_key_id;
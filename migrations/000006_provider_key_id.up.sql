@@ -0,0 +1,3 @@
+-- Record which provider API key submitted each job so status calls reuse it
+ALTER TABLE video_jobs
+    ADD COLUMN provider_key_id VARCHAR(32);
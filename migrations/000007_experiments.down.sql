@@ -0,0 +1,7 @@
+-- Drop experiment tracking columns
+DROP INDEX IF EXISTS idx_video_jobs_experiment;
+
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS experiment,
+    DROP COLUMN IF EXISTS experiment_arm,
+    DROP COLUMN IF EXISTS user_rating;
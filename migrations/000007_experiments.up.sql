@@ -0,0 +1,7 @@
+-- Record the traffic-split experiment arm that routed each job and the user's rating of the result
+ALTER TABLE video_jobs
+    ADD COLUMN experiment VARCHAR(64),
+    ADD COLUMN experiment_arm VARCHAR(64),
+    ADD COLUMN user_rating SMALLINT CHECK (user_rating BETWEEN 1 AND 5);
+
+CREATE INDEX idx_video_jobs_experiment ON video_jobs(experiment) WHERE experiment IS NOT NULL;
@@ -0,0 +1,6 @@
+-- Drop seed and parent job columns
+DROP INDEX IF EXISTS idx_video_jobs_parent_job_id;
+
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS seed,
+    DROP COLUMN IF EXISTS parent_job_id;
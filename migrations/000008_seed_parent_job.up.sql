@@ -0,0 +1,6 @@
+-- Record the generation seed and the job a regeneration was cloned from
+ALTER TABLE video_jobs
+    ADD COLUMN seed BIGINT,
+    ADD COLUMN parent_job_id UUID REFERENCES video_jobs(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_video_jobs_parent_job_id ON video_jobs(parent_job_id) WHERE parent_job_id IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Drop user assets
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS start_image_id;
+
+DROP TABLE IF EXISTS user_assets;
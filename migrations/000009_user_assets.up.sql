@@ -0,0 +1,18 @@
+-- Private user uploads (e.g. start images for image-to-video)
+CREATE TABLE user_assets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(16) NOT NULL,
+    storage_key TEXT NOT NULL,
+    content_type VARCHAR(64) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    width INTEGER NOT NULL DEFAULT 0,
+    height INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_assets_user_id ON user_assets(user_id);
+
+-- The user image a job animates instead of the template thumbnail
+ALTER TABLE video_jobs
+    ADD COLUMN start_image_id UUID REFERENCES user_assets(id) ON DELETE SET NULL;
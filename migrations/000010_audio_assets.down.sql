@@ -0,0 +1,6 @@
+-- Drop soundtrack columns
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS audio_id;
+
+ALTER TABLE user_assets
+    DROP COLUMN IF EXISTS duration_seconds;
@@ -0,0 +1,6 @@
+-- Soundtrack uploads: record audio length and the soundtrack a job is synchronised to
+ALTER TABLE user_assets
+    ADD COLUMN duration_seconds DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+ALTER TABLE video_jobs
+    ADD COLUMN audio_id UUID REFERENCES user_assets(id) ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+-- Drop the media storage key
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS video_storage_key;
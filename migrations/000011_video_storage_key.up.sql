@@ -0,0 +1,3 @@
+-- Durable copies of generated videos in our own media storage
+ALTER TABLE video_jobs
+    ADD COLUMN video_storage_key TEXT;
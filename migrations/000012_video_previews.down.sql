@@ -0,0 +1,3 @@
+-- Drop video previews
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS preview_url;
@@ -0,0 +1,3 @@
+-- Animated previews extracted from completed videos
+ALTER TABLE video_jobs
+    ADD COLUMN preview_url TEXT;
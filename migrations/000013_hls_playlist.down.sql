@@ -0,0 +1,3 @@
+-- Drop the HLS playlist URL
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS hls_playlist_url;
@@ -0,0 +1,3 @@
+-- Adaptive streaming (HLS) master playlist for completed videos
+ALTER TABLE video_jobs
+    ADD COLUMN hls_playlist_url TEXT;
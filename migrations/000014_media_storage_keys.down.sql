@@ -0,0 +1,5 @@
+-- Drop derived media storage keys
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS hls_storage_key,
+    DROP COLUMN IF EXISTS preview_storage_key,
+    DROP COLUMN IF EXISTS thumbnail_storage_key;
@@ -0,0 +1,5 @@
+-- Storage keys of derived media, so their URLs can be signed on every read
+ALTER TABLE video_jobs
+    ADD COLUMN thumbnail_storage_key TEXT,
+    ADD COLUMN preview_storage_key TEXT,
+    ADD COLUMN hls_storage_key TEXT;
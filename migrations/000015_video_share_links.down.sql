@@ -0,0 +1,3 @@
+-- Drop video share tokens
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS share_token;
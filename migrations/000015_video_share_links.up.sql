@@ -0,0 +1,3 @@
+-- Share tokens grant access to a video's stream without signing in
+ALTER TABLE video_jobs
+    ADD COLUMN share_token TEXT;
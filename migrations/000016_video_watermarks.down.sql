@@ -0,0 +1,4 @@
+-- Drop watermark tracking
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS source_storage_key,
+    DROP COLUMN IF EXISTS watermarked;
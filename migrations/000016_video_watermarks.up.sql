@@ -0,0 +1,4 @@
+-- Watermarked videos keep their clean original so the watermark can be removed after an upgrade
+ALTER TABLE video_jobs
+    ADD COLUMN watermarked BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN source_storage_key TEXT;
@@ -0,0 +1,11 @@
+-- Drop measured video metadata
+DROP INDEX IF EXISTS idx_video_jobs_metadata_mismatches;
+
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS video_width,
+    DROP COLUMN IF EXISTS video_height,
+    DROP COLUMN IF EXISTS video_fps,
+    DROP COLUMN IF EXISTS video_codec,
+    DROP COLUMN IF EXISTS video_size_bytes,
+    DROP COLUMN IF EXISTS measured_duration,
+    DROP COLUMN IF EXISTS metadata_mismatches;
@@ -0,0 +1,11 @@
+-- Metadata measured by probing each stored video, and where it falls short of the requested parameters
+ALTER TABLE video_jobs
+    ADD COLUMN video_width INTEGER,
+    ADD COLUMN video_height INTEGER,
+    ADD COLUMN video_fps DOUBLE PRECISION,
+    ADD COLUMN video_codec TEXT,
+    ADD COLUMN video_size_bytes BIGINT,
+    ADD COLUMN measured_duration DOUBLE PRECISION,
+    ADD COLUMN metadata_mismatches JSONB;
+
+CREATE INDEX idx_video_jobs_metadata_mismatches ON video_jobs(provider, created_at DESC) WHERE metadata_mismatches IS NOT NULL;
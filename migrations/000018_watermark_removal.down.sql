@@ -0,0 +1,6 @@
+-- Drop watermark removal tracking
+DROP INDEX IF EXISTS idx_video_jobs_watermark_removal;
+
+ALTER TABLE video_jobs
+    DROP COLUMN IF EXISTS watermark_removal_at,
+    DROP COLUMN IF EXISTS watermark_removal;
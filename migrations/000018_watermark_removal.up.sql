@@ -0,0 +1,6 @@
+-- Watermark removals run in the background; the status guards against running one twice
+ALTER TABLE video_jobs
+    ADD COLUMN watermark_removal TEXT,
+    ADD COLUMN watermark_removal_at TIMESTAMPTZ;
+
+CREATE INDEX idx_video_jobs_watermark_removal ON video_jobs(watermark_removal) WHERE watermark_removal IS NOT NULL;
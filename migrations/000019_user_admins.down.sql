@@ -0,0 +1,3 @@
+-- Drop admin flag
+ALTER TABLE users
+    DROP COLUMN IF EXISTS is_admin;
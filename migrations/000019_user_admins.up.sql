@@ -0,0 +1,4 @@
+-- Admin access is granted here rather than derived from a login's email, e.g.
+-- UPDATE users SET is_admin = TRUE WHERE email = 'ops@arabella.uz';
+ALTER TABLE users
+    ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;